@@ -1,47 +1,397 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
 	"github.com/go-redis/redis/v8"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 
+	"order_management/internal/auth"
+	"order_management/internal/config"
+	"order_management/internal/events"
+	"order_management/internal/graphqlapi"
+	"order_management/internal/grpcapi"
 	"order_management/internal/handlers"
+	"order_management/internal/idempotency"
+	"order_management/internal/logging"
+	"order_management/internal/metrics"
+	"order_management/internal/middlewares"
+	"order_management/internal/openapi"
+	"order_management/internal/ports"
 	"order_management/internal/repositories"
 	"order_management/internal/services"
+	"order_management/internal/tracing"
 	"order_management/internal/validators"
 	"order_management/pkg/database"
 )
 
 func main() {
+	// El subcomando migrate aplica, revierte o muestra las migraciones del esquema y termina
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fatal("Error al ejecutar las migraciones", err)
+		}
+		return
+	}
+
+	// La configuración se toma del archivo, del entorno y de los flags y se valida antes de conectarse
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fatal("Error en la configuración", err)
+	}
+
+	// Log estructurado en JSON; los mensajes registrados con el contexto de una solicitud incluyen su ID
+	slog.SetDefault(logging.New(os.Stdout, cfg.Log))
+	slog.Info("Configuración efectiva", "config", strings.Split(strings.TrimSpace(cfg.String()), "\n"))
+
+	// Trazas de OpenTelemetry hacia un collector OTLP o la salida estándar, según TRACING_EXPORTER
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, os.Stdout)
+	if err != nil {
+		fatal("Error al configurar las trazas", err)
+	}
+
+	// SIGINT o SIGTERM inician el apagado ordenado
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize database
-	db := database.InitDB()
+	db, err := database.InitDB(cfg.Database)
+	if err != nil {
+		fatal("Error al inicializar la base de datos", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("Error al obtener el pool de conexiones", err)
+	}
+	// Verificar que el esquema esté al día antes de atender solicitudes
+	if err := checkMigrations(ctx, db, cfg.Database.RequireMigrations); err != nil {
+		fatal("El esquema de la base de datos no está al día", err)
+	}
 	// Initialize Redis
 	redisClient := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
+		Addr:     cfg.Redis.Addr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
 	})
+	// Los comandos de Redis de una solicitud se registran como spans de su traza
+	redisClient.AddHook(tracing.RedisHook{})
 
-	// Initialize repositories
-	productRepo := repositories.NewProductRepository(db)
-	orderRepo := repositories.NewOrderRepository(db)
+	// Los eventos se publican en Redis y cada instancia los reenvía a sus propios suscriptores
+	eventBroker := events.NewBroker(redisClient)
+	go eventBroker.Run(ctx)
+
+	// Métricas de Prometheus, incluidos los pools de conexiones de MySQL y Redis
+	appMetrics := metrics.New()
+	appMetrics.RegisterDB(sqlDB)
+	appMetrics.RegisterRedis(redisClient)
+
+	// Las claves de idempotencia se guardan en el store configurado con IDEMPOTENCY_STORE e IDEMPOTENCY_TTL
+	store, err := idempotency.NewStore(cfg.Idempotency, redisClient, db)
+	if err != nil {
+		fatal("Error al crear el store de idempotencia", err)
+	}
+	idempotencyStore := idempotency.NewInstrumentedStore(store, appMetrics)
+
+	// Claves de los clientes; la configuración ya se validó al cargarla
+	clients, err := auth.ParseClients(cfg.Auth.ClientKeys)
+	if err != nil {
+		fatal("Error al leer las claves de los clientes", err)
+	}
+
+	// Initialize repositories; cada llamada se registra como un span
+	productRepo := repositories.NewTracedProductRepository(repositories.NewProductRepository(db))
+	orderRepo := repositories.NewTracedOrderRepository(repositories.NewOrderRepository(db))
+	categoryRepo := repositories.NewTracedCategoryRepository(repositories.NewCategoryRepository(db))
 
 	// Initialize services
-	productService := services.NewProductService(productRepo, db)
-	orderService := services.NewOrderService(orderRepo, productRepo, db)
+	productService := services.NewProductService(productRepo, db, eventBroker)
+	orderService := services.NewOrderService(orderRepo, productRepo, db, eventBroker, appMetrics)
+	categoryService := services.NewCategoryService(categoryRepo)
 
 	// Initialize Echo and middleware
 	e := echo.New()
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
+	// El banner no es JSON; el inicio del servidor se registra en el log estructurado
+	e.HideBanner = true
+	e.HidePort = true
+	// Asignar un ID de correlación a cada solicitud antes de registrar cualquier mensaje
+	e.Use(middlewares.RequestIDMiddleware())
+	// Iniciar la traza de la solicitud, o continuar la del cliente si envía traceparent
+	e.Use(middlewares.TracingMiddleware())
+	// Medir la duración de cada solicitud por ruta y código de respuesta
+	e.Use(middlewares.MetricsMiddleware(appMetrics))
+	// Las sondas del balanceador y del orquestador y las consultas de Prometheus no se registran en el
+	// log de accesos
+	e.Use(middlewares.RequestLoggerMiddleware(func(c echo.Context) bool {
+		return c.Path() == handlers.HealthPath || c.Path() == handlers.ReadyPath || c.Path() == handlers.MetricsPath
+	}))
+	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			slog.ErrorContext(c.Request().Context(), "Pánico al procesar la solicitud", "error", err, "stack", string(stack))
+			return err
+		},
+	}))
+	// Negociar el idioma de los mensajes con Accept-Language
+	e.Use(middlewares.LanguageMiddleware())
+	// Identificar al cliente por su clave para separar sus claves de idempotencia de las de otros clientes
+	e.Use(middlewares.ClientAuthMiddleware(clients))
 
 	// Configurar el validador globalmente
 	e.Validator = validators.NewValidator()
 
-	// Define el grupo con el prefijo "/api"
-	apiGroup := e.Group("/api")
+	// Traducir los errores de dominio a respuestas HTTP en un único lugar
+	e.HTTPErrorHandler = handlers.HTTPErrorHandler
+
+	// Verificaciones de salud de MySQL y Redis para el balanceador y el orquestador
+	healthHandler := handlers.NewHealthHandler(e, map[string]ports.HealthChecker{
+		"mysql": ports.HealthCheckFunc(sqlDB.PingContext),
+		"redis": ports.HealthCheckFunc(func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}),
+	}, cfg.Health.Timeout)
+
+	// Métricas para Prometheus
+	handlers.NewMetricsHandler(e, appMetrics.Handler())
+
+	// Documento OpenAPI: se sirve con Swagger UI y valida las solicitudes de la API
+	spec := openapi.NewSpec()
+	handlers.NewDocsHandler(e, spec)
+
+	specValidator, err := middlewares.OpenAPIValidationMiddleware(spec)
+	if err != nil {
+		fatal("Error al cargar la especificación OpenAPI", err)
+	}
+
+	// Register handlers: /api/v1 (con /api como alias) y /api/v2 comparten los servicios
+	handlers.RegisterAPIRoutes(e, handlers.Services{
+		Product:  productService,
+		Category: categoryService,
+		Order:    orderService,
+	}, idempotencyStore, specValidator)
+
+	// Endpoints de administración para inspeccionar o eliminar claves de idempotencia; solo se
+	// registran si ADMIN_TOKEN está definido
+	if cfg.Admin.Token != "" {
+		handlers.NewAdminHandler(e, idempotencyStore, cfg.Admin.Token)
+	}
+
+	// Eventos de órdenes y stock en tiempo real por Server-Sent Events y WebSocket
+	handlers.NewEventHandler(e, eventBroker)
+
+	// Endpoint GraphQL para consultar órdenes y productos en una sola solicitud
+	e.POST(graphqlapi.Path, echo.WrapHandler(graphqlapi.NewHandler(orderService, productService, categoryService)),
+		middleware.BodyLimit(graphqlapi.BodyLimit), middlewares.DeadlineMiddleware(handlers.GraphQLTimeout),
+		graphqlapi.IdempotencyMiddleware(idempotencyStore, handlers.GraphQLIdempotencyTTL))
+
+	// Exponer los mismos servicios por gRPC desde el mismo binario
+	grpcServer := grpcapi.NewServer(orderService, productService, idempotencyStore, clients)
+	listener, err := net.Listen("tcp", cfg.GRPC.Addr())
+	if err != nil {
+		fatal("Error al abrir el puerto gRPC", err)
+	}
+	go func() {
+		slog.Info("Servidor gRPC escuchando", "addr", cfg.GRPC.Addr())
+		if err := grpcServer.Serve(listener); err != nil {
+			fatal("Error en el servidor gRPC", err)
+		}
+	}()
+
+	go func() {
+		slog.Info("Servidor HTTP escuchando", "addr", cfg.HTTP.Addr())
+		if err := e.Start(cfg.HTTP.Addr()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("Error en el servidor HTTP", err)
+		}
+	}()
+
+	<-ctx.Done()
+	// Una segunda señal termina el proceso sin esperar
+	stop()
+	slog.Info("Apagando: se esperan las solicitudes en curso", "drain_delay", cfg.Shutdown.DrainDelay.String(), "timeout", cfg.Shutdown.Timeout.String())
+
+	// /readyz responde 503 para que el balanceador deje de enviar tráfico, y las conexiones de eventos,
+	// que nunca terminan por sí solas, se cierran antes de esperar a las demás solicitudes
+	healthHandler.Drain()
+	eventBroker.Close()
+
+	// Seguir atendiendo mientras el balanceador detecta que la instancia ya no está lista; cerrar el
+	// puerto antes haría fallar las solicitudes que todavía le envía
+	time.Sleep(cfg.Shutdown.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+	defer cancel()
+
+	// Dejar de aceptar conexiones y esperar a que terminen las solicitudes en curso y sus transacciones
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error al apagar el servidor HTTP", "error", err)
+	}
+	stopGRPCServer(shutdownCtx, grpcServer)
+
+	// Close espera a que terminen las consultas que ya empezaron
+	if err := sqlDB.Close(); err != nil {
+		slog.Error("Error al cerrar la conexión con MySQL", "error", err)
+	}
+	if err := redisClient.Close(); err != nil {
+		slog.Error("Error al cerrar la conexión con Redis", "error", err)
+	}
+	// Exportar las trazas pendientes antes de salir
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Error al exportar las trazas pendientes", "error", err)
+	}
+	slog.Info("Aplicación detenida")
+}
+
+// fatal registra el error y termina el proceso
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// migrateUsage describe el subcomando migrate
+const migrateUsage = "uso: order_management migrate up | down [pasos] | status [flags de configuración]"
+
+// runMigrate ejecuta el subcomando migrate: up aplica las migraciones pendientes, down revierte las
+// últimas (una por defecto) y status muestra el estado de cada una. Después de la acción se aceptan
+// los mismos flags de configuración que el servidor.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	action, args := args[0], args[1:]
+	if action != "up" && action != "down" && action != "status" {
+		return errors.New(migrateUsage)
+	}
+
+	steps := 1
+	if action == "down" && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("cantidad de migraciones a revertir inválida: %q", args[0])
+		}
+		steps, args = n, args[1:]
+	}
+
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logging.New(os.Stdout, cfg.Log))
+
+	db, err := database.InitDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		done, err := migrator.Up(ctx)
+		for _, migration := range done {
+			slog.Info("Migración aplicada", "version", migration.Version, "name", migration.Name)
+		}
+		if err == nil && len(done) == 0 {
+			slog.Info("El esquema está al día, no hay migraciones pendientes")
+		}
+		return err
+	case "down":
+		done, err := migrator.Down(ctx, steps)
+		for _, migration := range done {
+			slog.Info("Migración revertida", "version", migration.Version, "name", migration.Name)
+		}
+		if err == nil && len(done) == 0 {
+			slog.Info("No hay migraciones aplicadas para revertir")
+		}
+		return err
+	default:
+		return printMigrationStatus(ctx, migrator)
+	}
+}
+
+// printMigrationStatus muestra una línea por migración con la fecha en que se aplicó
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSIÓN\tNOMBRE\tAPLICADA")
+	for _, status := range statuses {
+		applied := "pendiente"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format(time.RFC3339)
+		}
+		if status.Dirty {
+			applied = "a medias (dirty)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+	return w.Flush()
+}
+
+// checkMigrations verifica que no haya migraciones pendientes. Si las hay, devuelve un error cuando
+// require es true o solo registra una advertencia. Una migración que quedó a medias siempre es un
+// error. No modifica la base: si la tabla schema_migrations no existe, todas están pendientes.
+func checkMigrations(ctx context.Context, db *gorm.DB, require bool) error {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	versions := make([]uint, len(pending))
+	for i, migration := range pending {
+		versions[i] = migration.Version
+	}
+	if require {
+		return fmt.Errorf("hay %d migraciones pendientes %v; ejecute \"migrate up\"", len(pending), versions)
+	}
+	slog.Warn("Hay migraciones del esquema pendientes; ejecute \"migrate up\"", "pending", versions)
+	return nil
+}
 
-	// Register handlers
-	handlers.NewProductHandler(apiGroup, productService)
-	handlers.NewOrderHandler(apiGroup, orderService, redisClient)
+// stopGRPCServer espera a que terminen las llamadas en curso y las corta si vence ctx
+func stopGRPCServer(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
 
-	e.Logger.Fatal(e.Start(":8080"))
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Warn("Las llamadas gRPC en curso no terminaron a tiempo, se cortan")
+		grpcServer.Stop()
+	}
 }
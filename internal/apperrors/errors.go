@@ -0,0 +1,162 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+
+	"order_management/internal/i18n"
+)
+
+// Kind clasifica el error de dominio y determina el código HTTP con que se responde
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindInvalid
+)
+
+// Code es el identificador estable y legible por máquinas que se envía al cliente
+type Code string
+
+const (
+	CodeInternal               Code = "INTERNAL_ERROR"
+	CodeInvalidRequest         Code = "INVALID_REQUEST"
+	CodeInvalidCSV             Code = "INVALID_CSV"
+	CodeValidationFailed       Code = "VALIDATION_FAILED"
+	CodeOrderNotFound          Code = "ORDER_NOT_FOUND"
+	CodeInvalidStatusChange    Code = "INVALID_ORDER_STATUS_TRANSITION"
+	CodeProductNotFound        Code = "PRODUCT_NOT_FOUND"
+	CodeVariantNotFound        Code = "VARIANT_NOT_FOUND"
+	CodeVariantMismatch        Code = "VARIANT_PRODUCT_MISMATCH"
+	CodeCategoryNotFound       Code = "CATEGORY_NOT_FOUND"
+	CodeInsufficientStock      Code = "INSUFFICIENT_STOCK"
+	CodeTransactionConflict    Code = "TRANSACTION_CONFLICT"
+	CodeStockUpdateFailed      Code = "STOCK_UPDATE_FAILED"
+	CodeOrderCreationFailed    Code = "ORDER_CREATION_FAILED"
+	CodeCategoryCreateFailed   Code = "CATEGORY_CREATION_FAILED"
+	CodeBatchAborted           Code = "BATCH_ABORTED"
+	CodeIdempotencyInProgress  Code = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeDuplicateIdempotency   Code = "DUPLICATE_IDEMPOTENCY_KEY"
+	CodeIdempotencyMismatch    Code = "IDEMPOTENCY_KEY_MISMATCH"
+	CodeIdempotencyKeyNotFound Code = "IDEMPOTENCY_KEY_NOT_FOUND"
+	CodeIdempotencyKeyTooLong  Code = "IDEMPOTENCY_KEY_TOO_LONG"
+	CodeRequestTimeout         Code = "REQUEST_TIMEOUT"
+	CodeRequestCanceled        Code = "REQUEST_CANCELED"
+)
+
+// Error representa un error de dominio con un código estable, un mensaje para el cliente,
+// detalles opcionales y la causa original, que nunca se expone en la respuesta.
+// El mensaje se escribe en español; Localize lo traduce al idioma del cliente.
+type Error struct {
+	Kind    Kind
+	Code    Code
+	Message string
+	Details map[string]interface{}
+	Err     error
+
+	// format y args conservan el mensaje sin formatear para poder traducirlo
+	format string
+	args   []interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NotFound crea un error para un recurso inexistente
+func NotFound(code Code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// Conflict crea un error para una operación incompatible con el estado actual
+func Conflict(code Code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Unprocessable crea un error para una solicitud bien formada pero semánticamente inválida
+func Unprocessable(code Code, message string) *Error {
+	return &Error{Kind: KindUnprocessable, Code: code, Message: message}
+}
+
+// Invalid crea un error para una solicitud mal formada
+func Invalid(code Code, message string) *Error {
+	return &Error{Kind: KindInvalid, Code: code, Message: message}
+}
+
+// Invalidf crea un error para una solicitud mal formada con un mensaje con formato. El formato es
+// la clave con la que se traduce el mensaje.
+func Invalidf(code Code, format string, args ...interface{}) *Error {
+	return &Error{
+		Kind:    KindInvalid,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		format:  format,
+		args:    args,
+	}
+}
+
+// Internal crea un error inesperado conservando la causa original
+func Internal(code Code, message string, cause error) *Error {
+	return &Error{Kind: KindInternal, Code: code, Message: message, Err: cause}
+}
+
+// InsufficientStock crea el error de stock insuficiente con el producto, la variante y las cantidades involucradas
+func InsufficientStock(productID uint, variantID *uint, requested, available int) *Error {
+	details := map[string]interface{}{
+		"product_id": productID,
+		"requested":  requested,
+		"available":  available,
+	}
+	if variantID != nil {
+		details["variant_id"] = *variantID
+	}
+
+	format := "stock insuficiente para el producto %d (disponible: %d)"
+	return &Error{
+		Kind:    KindConflict,
+		Code:    CodeInsufficientStock,
+		Message: fmt.Sprintf(format, productID, available),
+		Details: details,
+		format:  format,
+		args:    []interface{}{productID, available},
+	}
+}
+
+// Localize devuelve el mensaje del error traducido al idioma indicado
+func (e *Error) Localize(lang string) string {
+	if e.format != "" {
+		return i18n.Sprintf(lang, e.format, e.args...)
+	}
+	return i18n.Translate(lang, e.Message)
+}
+
+// WithDetail agrega un detalle al error y lo devuelve para encadenar llamadas
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// As extrae el *Error de la cadena de errores, si existe
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// IsCode indica si la cadena de errores contiene un error de dominio con el código indicado
+func IsCode(err error, code Code) bool {
+	appErr, ok := As(err)
+	return ok && appErr.Code == code
+}
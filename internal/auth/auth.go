@@ -0,0 +1,106 @@
+// Package auth identifica a los clientes de la API por la clave que envían en la cabecera
+// Authorization. Cada cliente tiene un identificador estable y una o más claves, de modo que rotar
+// una clave no cambia el identificador con el que se asocian sus datos, como las claves de
+// idempotencia.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxClientIDLength es la longitud máxima del identificador de un cliente. El identificador forma parte
+// de las claves de idempotencia guardadas, que deben caber en la columna del store SQL.
+const MaxClientIDLength = 64
+
+// AnonymousClientID es el ámbito de idempotencia de las solicitudes sin credenciales. Ningún cliente
+// configurado puede usarlo como identificador.
+const AnonymousClientID = "anonymous"
+
+// hashedScope reconoce el SHA-256 en hexadecimal con que se identifica el ámbito de las solicitudes
+// con una cabecera Authorization que no pertenece a ningún cliente
+var hashedScope = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Config define las claves de los clientes
+type Config struct {
+	// ClientKeys lista las claves con el formato cliente:clave separadas por comas. Un cliente puede
+	// tener varias claves para rotarlas sin cambiar su identificador.
+	ClientKeys string `yaml:"client_keys"`
+}
+
+// clientKey asocia una clave con el cliente al que pertenece
+type clientKey struct {
+	clientID string
+	key      []byte
+}
+
+// Clients identifica a los clientes por su clave
+type Clients struct {
+	keys []clientKey
+}
+
+// ParseClients lee las claves con el formato de Config.ClientKeys. Una lista vacía no identifica a
+// ningún cliente. Se rechazan los identificadores que coinciden con los ámbitos de idempotencia de las
+// solicitudes sin un cliente configurado.
+func ParseClients(spec string) (*Clients, error) {
+	clients := &Clients{}
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		clientID, key, ok := strings.Cut(entry, ":")
+		clientID, key = strings.TrimSpace(clientID), strings.TrimSpace(key)
+		if !ok || clientID == "" || key == "" {
+			return nil, fmt.Errorf("las claves de los clientes deben tener el formato cliente:clave")
+		}
+		if len(clientID) > MaxClientIDLength {
+			return nil, fmt.Errorf("el identificador del cliente %s supera los %d caracteres", clientID, MaxClientIDLength)
+		}
+		if clientID == AnonymousClientID || hashedScope.MatchString(clientID) {
+			// Compartiría las claves de idempotencia con otras solicitudes
+			return nil, fmt.Errorf("el identificador del cliente %s está reservado", clientID)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("la clave del cliente %s está repetida", clientID)
+		}
+		seen[key] = true
+		clients.keys = append(clients.keys, clientKey{clientID: clientID, key: []byte(key)})
+	}
+	return clients, nil
+}
+
+// Identify devuelve el cliente de la cabecera Authorization "Bearer <clave>" y false si la clave no
+// pertenece a ningún cliente. Compara con todas las claves en tiempo constante.
+func (c *Clients) Identify(authorization string) (string, bool) {
+	key, ok := strings.CutPrefix(authorization, "Bearer ")
+	if c == nil || !ok || key == "" {
+		return "", false
+	}
+
+	var clientID string
+	for _, candidate := range c.keys {
+		if subtle.ConstantTimeCompare([]byte(key), candidate.key) == 1 {
+			clientID = candidate.clientID
+		}
+	}
+	return clientID, clientID != ""
+}
+
+type clientIDKey struct{}
+
+// WithClientID devuelve una copia de ctx con el identificador del cliente autenticado
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, clientID)
+}
+
+// ClientIDFromContext devuelve el identificador del cliente autenticado, o vacío si la solicitud no
+// se identificó
+func ClientIDFromContext(ctx context.Context) string {
+	clientID, _ := ctx.Value(clientIDKey{}).(string)
+	return clientID
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClients_IdentifiesEveryKeyOfAClient(t *testing.T) {
+	clients, err := ParseClients(" tienda:clave-nueva, tienda:clave-anterior,backoffice:otra ")
+	assert.NoError(t, err)
+
+	// Rotar la clave no cambia el identificador del cliente
+	for authorization, expected := range map[string]string{
+		"Bearer clave-nueva":    "tienda",
+		"Bearer clave-anterior": "tienda",
+		"Bearer otra":           "backoffice",
+	} {
+		clientID, ok := clients.Identify(authorization)
+		assert.True(t, ok, authorization)
+		assert.Equal(t, expected, clientID, authorization)
+	}
+
+	for _, authorization := range []string{"", "Bearer ", "Bearer desconocida", "clave-nueva", "Basic clave-nueva"} {
+		_, ok := clients.Identify(authorization)
+		assert.False(t, ok, authorization)
+	}
+}
+
+func TestParseClients_EmptyIdentifiesNobody(t *testing.T) {
+	clients, err := ParseClients("")
+	assert.NoError(t, err)
+
+	_, ok := clients.Identify("Bearer cualquiera")
+	assert.False(t, ok)
+}
+
+func TestParseClients_RejectsInvalidEntries(t *testing.T) {
+	for _, spec := range []string{"tienda", "tienda:", ":clave", "tienda:clave,backoffice:clave", strings.Repeat("t", MaxClientIDLength+1) + ":clave",
+		AnonymousClientID + ":clave", strings.Repeat("a1", 32) + ":clave"} {
+		_, err := ParseClients(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestClientIDFromContext(t *testing.T) {
+	assert.Empty(t, ClientIDFromContext(context.Background()))
+	assert.Equal(t, "tienda", ClientIDFromContext(WithClientID(context.Background(), "tienda")))
+}
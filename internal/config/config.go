@@ -0,0 +1,338 @@
+// Package config carga la configuración de la aplicación. Cada valor se toma, de menor a mayor
+// prioridad, de los valores por defecto, del archivo YAML indicado con -config o CONFIG_FILE, de su
+// variable de entorno y de su flag. La configuración se valida al iniciar para fallar antes de
+// abrir conexiones con valores inválidos.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"order_management/internal/auth"
+	"order_management/internal/idempotency"
+	"order_management/internal/logging"
+	"order_management/internal/tracing"
+	"order_management/pkg/database"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted reemplaza los secretos al mostrar la configuración
+const redacted = "******"
+
+// Config es la configuración completa de la aplicación
+type Config struct {
+	HTTP        ServerConfig       `yaml:"http"`
+	GRPC        ServerConfig       `yaml:"grpc"`
+	Database    database.Config    `yaml:"database"`
+	Redis       RedisConfig        `yaml:"redis"`
+	Idempotency idempotency.Config `yaml:"idempotency"`
+	Auth        auth.Config        `yaml:"auth"`
+	Admin       AdminConfig        `yaml:"admin"`
+	Health      HealthConfig       `yaml:"health"`
+	Shutdown    ShutdownConfig     `yaml:"shutdown"`
+	Log         logging.Config     `yaml:"log"`
+	Tracing     tracing.Config     `yaml:"tracing"`
+}
+
+// ServerConfig define el puerto en el que escucha un servidor
+type ServerConfig struct {
+	Port int `yaml:"port"`
+}
+
+// Addr devuelve la dirección de escucha del servidor
+func (c ServerConfig) Addr() string {
+	return ":" + strconv.Itoa(c.Port)
+}
+
+// RedisConfig define la conexión a Redis
+type RedisConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+// Addr devuelve la dirección host:puerto de Redis
+func (c RedisConfig) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// AdminConfig define el acceso a los endpoints de administración
+type AdminConfig struct {
+	// Token habilita los endpoints de administración; vacío los deshabilita
+	Token string `yaml:"token"`
+}
+
+// HealthConfig define las verificaciones de /healthz y /readyz
+type HealthConfig struct {
+	// Timeout es el tiempo máximo de espera de cada dependencia
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ShutdownConfig define el apagado de la aplicación
+type ShutdownConfig struct {
+	// Timeout es el tiempo máximo para terminar las solicitudes en curso antes de cerrar las conexiones
+	Timeout time.Duration `yaml:"timeout"`
+	// DrainDelay es el tiempo que el servidor sigue aceptando solicitudes después de que /readyz pasa a
+	// responder 503, para que el balanceador deje de enviarle tráfico antes de cerrar el puerto
+	DrainDelay time.Duration `yaml:"drain_delay"`
+}
+
+// Default devuelve la configuración para desarrollo local
+func Default() Config {
+	return Config{
+		HTTP: ServerConfig{Port: 8080},
+		GRPC: ServerConfig{Port: 9090},
+		Database: database.Config{
+			Host:               "localhost",
+			Port:               3306,
+			User:               "order",
+			Password:           "password",
+			Name:               "order_management",
+			LogLevel:           "warn",
+			SlowQueryThreshold: 200 * time.Millisecond,
+			MaxOpenConns:       25,
+			MaxIdleConns:       25,
+			ConnMaxLifetime:    5 * time.Minute,
+		},
+		Redis:       RedisConfig{Host: "localhost", Port: 6379},
+		Idempotency: idempotency.Config{Store: idempotency.StoreRedis, TTL: idempotency.DefaultTTL},
+		Health:      HealthConfig{Timeout: 2 * time.Second},
+		Shutdown:    ShutdownConfig{Timeout: 30 * time.Second},
+		Log:         logging.Config{Level: "info"},
+		Tracing:     tracing.Config{Exporter: tracing.ExporterNone, Endpoint: "localhost:4317", SampleRatio: 1},
+	}
+}
+
+// Load carga la configuración desde el archivo, el entorno y los argumentos de la línea de comandos,
+// y la valida. Con -h devuelve flag.ErrHelp después de mostrar la ayuda.
+func Load(args []string) (Config, error) {
+	// Los flags se leen primero sobre una copia para conocer el archivo, pero se aplican al final
+	// para que tengan prioridad sobre el archivo y el entorno
+	flags := Default()
+	fs := flag.NewFlagSet("order_management", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "archivo de configuración YAML (CONFIG_FILE)")
+	bind(fs, &flags)
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	config := Default()
+	if *configFile != "" {
+		if err := loadFile(*configFile, &config); err != nil {
+			return Config{}, err
+		}
+	}
+
+	target := flag.NewFlagSet("config", flag.ContinueOnError)
+	settings := bind(target, &config)
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := target.Set(s.flag, value); err != nil {
+				return Config{}, fmt.Errorf("%s inválido: %q", s.env, value)
+			}
+		}
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "config" || err != nil {
+			return
+		}
+		err = target.Set(f.Name, f.Value.String())
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	return config, config.Validate()
+}
+
+// loadFile aplica el archivo YAML sobre la configuración. Las claves desconocidas se rechazan para
+// detectar errores de tipeo.
+func loadFile(path string, config *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de configuración: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("error al leer el archivo de configuración %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate verifica que todos los valores sean utilizables y devuelve todos los errores juntos
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	for _, port := range []struct {
+		env   string
+		value int
+	}{
+		{"HTTP_PORT", c.HTTP.Port},
+		{"GRPC_PORT", c.GRPC.Port},
+		{"DB_PORT", c.Database.Port},
+		{"REDIS_PORT", c.Redis.Port},
+	} {
+		check(port.value > 0 && port.value <= 65535, "%s debe estar entre 1 y 65535: %d", port.env, port.value)
+	}
+	check(c.HTTP.Port != c.GRPC.Port, "HTTP_PORT y GRPC_PORT no pueden ser iguales: %d", c.HTTP.Port)
+
+	check(c.Database.Host != "", "DB_HOST es obligatorio")
+	check(c.Database.User != "", "DB_USER es obligatorio")
+	check(c.Database.Name != "", "DB_NAME es obligatorio")
+	_, ok := database.LogLevels[c.Database.LogLevel]
+	check(ok, "DB_LOG_LEVEL debe ser silent, error, warn o info: %q", c.Database.LogLevel)
+	check(c.Database.SlowQueryThreshold >= 0, "DB_SLOW_QUERY_THRESHOLD no puede ser negativo: %s", c.Database.SlowQueryThreshold)
+	check(c.Database.MaxOpenConns > 0, "DB_MAX_OPEN_CONNS debe ser mayor que 0: %d", c.Database.MaxOpenConns)
+	check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS debe estar entre 0 y DB_MAX_OPEN_CONNS: %d", c.Database.MaxIdleConns)
+	check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME no puede ser negativo: %s", c.Database.ConnMaxLifetime)
+
+	check(c.Redis.Host != "", "REDIS_HOST es obligatorio")
+	check(c.Redis.DB >= 0, "REDIS_DB no puede ser negativo: %d", c.Redis.DB)
+
+	switch c.Idempotency.Store {
+	case idempotency.StoreRedis, idempotency.StoreMemory, idempotency.StoreSQL:
+	default:
+		errs = append(errs, fmt.Errorf("IDEMPOTENCY_STORE debe ser redis, memory o sql: %q", c.Idempotency.Store))
+	}
+	check(c.Idempotency.TTL > 0, "IDEMPOTENCY_TTL debe ser mayor que 0: %s", c.Idempotency.TTL)
+
+	if _, err := auth.ParseClients(c.Auth.ClientKeys); err != nil {
+		errs = append(errs, fmt.Errorf("CLIENT_API_KEYS inválido: %w", err))
+	}
+
+	check(c.Health.Timeout > 0, "HEALTH_CHECK_TIMEOUT debe ser mayor que 0: %s", c.Health.Timeout)
+	check(c.Shutdown.Timeout > 0, "SHUTDOWN_TIMEOUT debe ser mayor que 0: %s", c.Shutdown.Timeout)
+	check(c.Shutdown.DrainDelay >= 0, "SHUTDOWN_DRAIN_DELAY no puede ser negativo: %s", c.Shutdown.DrainDelay)
+
+	_, ok = logging.Levels[c.Log.Level]
+	check(ok, "LOG_LEVEL debe ser debug, info, warn o error: %q", c.Log.Level)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER debe ser none, stdout u otlp: %q", c.Tracing.Exporter))
+	}
+	check(c.Tracing.Exporter != tracing.ExporterOTLP || c.Tracing.Endpoint != "", "TRACING_ENDPOINT es obligatorio con el exportador otlp")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO debe estar entre 0 y 1: %g", c.Tracing.SampleRatio)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("configuración inválida: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// String muestra la configuración efectiva, una variable por línea y con los secretos ocultos
+func (c Config) String() string {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	var b strings.Builder
+	for _, s := range bind(fs, &c) {
+		value := fs.Lookup(s.flag).Value.String()
+		if s.secret && value != "" {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s=%s\n", s.env, value)
+	}
+	return b.String()
+}
+
+// setting asocia un flag con su variable de entorno
+type setting struct {
+	flag   string
+	env    string
+	secret bool
+}
+
+// binder registra los flags de la configuración sobre los campos de una instancia
+type binder struct {
+	fs       *flag.FlagSet
+	settings []setting
+}
+
+// bind registra en fs un flag por cada valor de config, con el valor actual como valor por defecto,
+// y devuelve los flags en el orden en que se declaran
+func bind(fs *flag.FlagSet, config *Config) []setting {
+	b := &binder{fs: fs}
+
+	b.int(&config.HTTP.Port, "http-port", "HTTP_PORT", "puerto de la API REST")
+	b.int(&config.GRPC.Port, "grpc-port", "GRPC_PORT", "puerto de la API gRPC")
+
+	b.string(&config.Database.Host, "db-host", "DB_HOST", "host de MySQL", false)
+	b.int(&config.Database.Port, "db-port", "DB_PORT", "puerto de MySQL")
+	b.string(&config.Database.User, "db-user", "DB_USER", "usuario de MySQL", false)
+	b.string(&config.Database.Password, "db-password", "DB_PASSWORD", "contraseña de MySQL", true)
+	b.string(&config.Database.Name, "db-name", "DB_NAME", "base de datos de MySQL", false)
+	b.string(&config.Database.LogLevel, "db-log-level", "DB_LOG_LEVEL", "nivel de log de GORM: silent, error, warn o info", false)
+	b.duration(&config.Database.SlowQueryThreshold, "db-slow-query-threshold", "DB_SLOW_QUERY_THRESHOLD", "duración a partir de la cual una consulta se registra como lenta; 0 lo desactiva")
+	b.int(&config.Database.MaxOpenConns, "db-max-open-conns", "DB_MAX_OPEN_CONNS", "máximo de conexiones abiertas con MySQL")
+	b.int(&config.Database.MaxIdleConns, "db-max-idle-conns", "DB_MAX_IDLE_CONNS", "máximo de conexiones inactivas con MySQL")
+	b.duration(&config.Database.ConnMaxLifetime, "db-conn-max-lifetime", "DB_CONN_MAX_LIFETIME", "tiempo máximo de vida de una conexión con MySQL")
+	b.bool(&config.Database.RequireMigrations, "db-require-migrations", "DB_REQUIRE_MIGRATIONS", "no iniciar el servidor si hay migraciones del esquema pendientes")
+
+	b.string(&config.Redis.Host, "redis-host", "REDIS_HOST", "host de Redis", false)
+	b.int(&config.Redis.Port, "redis-port", "REDIS_PORT", "puerto de Redis")
+	b.string(&config.Redis.Password, "redis-password", "REDIS_PASSWORD", "contraseña de Redis", true)
+	b.int(&config.Redis.DB, "redis-db", "REDIS_DB", "base de datos de Redis")
+
+	b.string(&config.Idempotency.Store, "idempotency-store", "IDEMPOTENCY_STORE", "store de las claves de idempotencia: redis, memory o sql", false)
+	b.duration(&config.Idempotency.TTL, "idempotency-ttl", "IDEMPOTENCY_TTL", "tiempo durante el que se repiten las respuestas idempotentes")
+
+	b.string(&config.Auth.ClientKeys, "client-api-keys", "CLIENT_API_KEYS", "claves de los clientes con el formato cliente:clave separadas por comas", true)
+
+	b.string(&config.Admin.Token, "admin-token", "ADMIN_TOKEN", "token de los endpoints de administración; vacío los deshabilita", true)
+
+	b.duration(&config.Health.Timeout, "health-check-timeout", "HEALTH_CHECK_TIMEOUT", "tiempo máximo de espera de cada dependencia en /healthz y /readyz")
+	b.duration(&config.Shutdown.Timeout, "shutdown-timeout", "SHUTDOWN_TIMEOUT", "tiempo máximo para terminar las solicitudes en curso al apagar")
+	b.duration(&config.Shutdown.DrainDelay, "shutdown-drain-delay", "SHUTDOWN_DRAIN_DELAY", "tiempo que se siguen aceptando solicitudes después de marcar /readyz como no listo")
+
+	b.string(&config.Log.Level, "log-level", "LOG_LEVEL", "nivel mínimo de log: debug, info, warn o error", false)
+
+	b.string(&config.Tracing.Exporter, "tracing-exporter", "TRACING_EXPORTER", "exportador de las trazas: none, stdout u otlp", false)
+	b.string(&config.Tracing.Endpoint, "tracing-endpoint", "TRACING_ENDPOINT", "dirección host:puerto del collector OTLP por gRPC", false)
+	b.float(&config.Tracing.SampleRatio, "tracing-sample-ratio", "TRACING_SAMPLE_RATIO", "fracción de las trazas nuevas que se registran, entre 0 y 1")
+
+	return b.settings
+}
+
+func (b *binder) string(p *string, name, env, usage string, secret bool) {
+	b.fs.StringVar(p, name, *p, usage+" ("+env+")")
+	b.settings = append(b.settings, setting{flag: name, env: env, secret: secret})
+}
+
+func (b *binder) int(p *int, name, env, usage string) {
+	b.fs.IntVar(p, name, *p, usage+" ("+env+")")
+	b.settings = append(b.settings, setting{flag: name, env: env})
+}
+
+func (b *binder) bool(p *bool, name, env, usage string) {
+	b.fs.BoolVar(p, name, *p, usage+" ("+env+")")
+	b.settings = append(b.settings, setting{flag: name, env: env})
+}
+
+func (b *binder) duration(p *time.Duration, name, env, usage string) {
+	b.fs.DurationVar(p, name, *p, usage+" ("+env+")")
+	b.settings = append(b.settings, setting{flag: name, env: env})
+}
+
+func (b *binder) float(p *float64, name, env, usage string) {
+	b.fs.Float64Var(p, name, *p, usage+" ("+env+")")
+	b.settings = append(b.settings, setting{flag: name, env: env})
+}
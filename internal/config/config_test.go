@@ -0,0 +1,142 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unsetEnv elimina las variables de la configuración durante el test para no depender del entorno
+func unsetEnv(t *testing.T) {
+	config := Default()
+	envs := []string{"CONFIG_FILE"}
+	for _, s := range bind(flag.NewFlagSet("test", flag.ContinueOnError), &config) {
+		envs = append(envs, s.env)
+	}
+
+	for _, env := range envs {
+		if value, ok := os.LookupEnv(env); ok {
+			os.Unsetenv(env)
+			t.Cleanup(func() { os.Setenv(env, value) })
+		}
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	unsetEnv(t)
+	config, err := Load(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), config)
+	assert.Equal(t, ":8080", config.HTTP.Addr())
+	assert.Equal(t, "localhost:6379", config.Redis.Addr())
+}
+
+// TestLoad_Precedence verifica que el entorno tenga prioridad sobre el archivo y los flags sobre ambos
+func TestLoad_Precedence(t *testing.T) {
+	unsetEnv(t)
+	path := writeConfigFile(t, `
+http:
+  port: 8000
+redis:
+  host: redis
+  port: 6380
+database:
+  log_level: error
+idempotency:
+  ttl: 1h
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("REDIS_PORT", "6381")
+	t.Setenv("IDEMPOTENCY_STORE", "sql")
+	t.Setenv("DB_REQUIRE_MIGRATIONS", "true")
+
+	config, err := Load([]string{"-http-port", "8001", "-db-max-open-conns", "50"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 8001, config.HTTP.Port)
+	assert.Equal(t, "redis:6381", config.Redis.Addr())
+	assert.Equal(t, "error", config.Database.LogLevel)
+	assert.Equal(t, 50, config.Database.MaxOpenConns)
+	assert.Equal(t, "sql", config.Idempotency.Store)
+	assert.True(t, config.Database.RequireMigrations)
+	assert.Equal(t, time.Hour, config.Idempotency.TTL)
+	// Los valores que no se indican en ningún lado conservan el valor por defecto
+	assert.Equal(t, Default().Database.Host, config.Database.Host)
+}
+
+func TestLoad_RejectsInvalidValues(t *testing.T) {
+	unsetEnv(t)
+	t.Setenv("IDEMPOTENCY_TTL", "mañana")
+	_, err := Load(nil)
+	assert.ErrorContains(t, err, "IDEMPOTENCY_TTL")
+
+	_, err = Load([]string{"-config", writeConfigFile(t, "redis:\n  hots: redis\n")})
+	assert.ErrorContains(t, err, "hots")
+}
+
+// TestValidate verifica que se informen todos los valores inválidos juntos
+func TestValidate(t *testing.T) {
+	config := Default()
+	config.HTTP.Port = 70000
+	config.Database.LogLevel = "debug"
+	config.Database.MaxIdleConns = 100
+	config.Idempotency.Store = "etcd"
+	config.Log.Level = "trace"
+	config.Tracing.Exporter = "jaeger"
+	config.Tracing.SampleRatio = 1.5
+	config.Auth.ClientKeys = "tienda"
+	config.Shutdown.DrainDelay = -time.Second
+
+	err := config.Validate()
+
+	assert.ErrorContains(t, err, "HTTP_PORT")
+	assert.ErrorContains(t, err, "DB_LOG_LEVEL")
+	assert.ErrorContains(t, err, "DB_MAX_IDLE_CONNS")
+	assert.ErrorContains(t, err, "IDEMPOTENCY_STORE")
+	assert.ErrorContains(t, err, "LOG_LEVEL")
+	assert.ErrorContains(t, err, "TRACING_EXPORTER")
+	assert.ErrorContains(t, err, "TRACING_SAMPLE_RATIO")
+	assert.ErrorContains(t, err, "CLIENT_API_KEYS")
+	assert.ErrorContains(t, err, "SHUTDOWN_DRAIN_DELAY")
+	assert.NoError(t, Default().Validate())
+}
+
+func TestString_RedactsSecrets(t *testing.T) {
+	config := Default()
+	config.Admin.Token = "admin-secret"
+	config.Auth.ClientKeys = "tienda:client-secret"
+
+	output := config.String()
+
+	assert.Contains(t, output, "DB_HOST=localhost\n")
+	assert.Contains(t, output, "IDEMPOTENCY_TTL=10m0s\n")
+	assert.Contains(t, output, "DB_PASSWORD="+redacted+"\n")
+	assert.Contains(t, output, "ADMIN_TOKEN="+redacted+"\n")
+	// Un secreto vacío se muestra vacío para que se note que falta
+	assert.Contains(t, output, "REDIS_PASSWORD=\n")
+	assert.False(t, strings.Contains(output, "admin-secret"))
+	assert.False(t, strings.Contains(output, "client-secret"))
+	assert.False(t, strings.Contains(output, "=password"))
+}
+
+// TestLoad_ExampleFile verifica que el archivo de ejemplo sea válido y documente los valores por defecto
+func TestLoad_ExampleFile(t *testing.T) {
+	unsetEnv(t)
+
+	config, err := Load([]string{"-config", "../../config.example.yaml"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), config)
+}
@@ -0,0 +1,15 @@
+package dtos
+
+// CategoryRequestDTO representa el payload recibido para crear una categoría
+type CategoryRequestDTO struct {
+	Name     string `json:"name" validate:"required"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// CategoryResponseDTO representa una categoría con sus subcategorías anidadas
+type CategoryResponseDTO struct {
+	ID       uint                  `json:"id"`
+	Name     string                `json:"name"`
+	ParentID *uint                 `json:"parent_id"`
+	Children []CategoryResponseDTO `json:"children"`
+}
@@ -0,0 +1,16 @@
+package dtos
+
+// ErrorResponseDTO representa el cuerpo de todas las respuestas de error de la API
+type ErrorResponseDTO struct {
+	Error   string                 `json:"error"`
+	Code    string                 `json:"code"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	Fields  []FieldErrorDTO        `json:"fields,omitempty"`
+}
+
+// FieldErrorDTO representa un campo que no cumple una regla de validación
+type FieldErrorDTO struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,26 @@
+package dtos
+
+import "time"
+
+// EventQueryDTO representa los filtros de una suscripción a eventos en tiempo real
+type EventQueryDTO struct {
+	OrderID      uint   `query:"order_id"`
+	ProductID    uint   `query:"product_id"`
+	CustomerName string `query:"customer_name"`
+}
+
+// EventDTO representa un evento enviado a los clientes suscritos. Según el tipo se informa
+// la orden, con el mismo formato que la versión 2 de la API, o el nuevo stock.
+type EventDTO struct {
+	Type       string               `json:"type"`
+	OccurredAt time.Time            `json:"occurred_at"`
+	Order      *OrderV2ResponseDTO  `json:"order,omitempty"`
+	Stock      *StockChangeEventDTO `json:"stock,omitempty"`
+}
+
+// StockChangeEventDTO representa el nuevo stock de un producto o de una de sus variantes
+type StockChangeEventDTO struct {
+	ProductID uint  `json:"product_id"`
+	VariantID *uint `json:"variant_id,omitempty"`
+	Stock     int   `json:"stock"`
+}
@@ -0,0 +1,14 @@
+package dtos
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+	HealthStatusDraining    = "draining"
+)
+
+// HealthResponseDTO representa el estado de la aplicación y el de cada dependencia verificada
+type HealthResponseDTO struct {
+	Status string `json:"status"`
+	// Checks asocia cada dependencia con "ok" o con el error que devolvió
+	Checks map[string]string `json:"checks"`
+}
@@ -0,0 +1,16 @@
+package dtos
+
+import "time"
+
+// IdempotencyKeyResponseDTO representa el estado guardado de una clave de idempotencia
+type IdempotencyKeyResponseDTO struct {
+	Scope       string            `json:"scope"`
+	Key         string            `json:"key"`
+	Status      string            `json:"status"`
+	Fingerprint string            `json:"fingerprint"`
+	StatusCode  int               `json:"status_code,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	// Response es el cuerpo que se repite en los reintentos
+	Response  string     `json:"response,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,41 @@
+package dtos
+
+const (
+	BatchModeAllOrNothing = "all_or_nothing"
+	BatchModeIndependent  = "independent"
+
+	BatchStatusCreated  = "created"
+	BatchStatusReplayed = "replayed"
+	BatchStatusFailed   = "failed"
+)
+
+// BatchOrderRequestDTO representa el payload recibido para crear un lote de órdenes
+type BatchOrderRequestDTO struct {
+	Mode   string               `json:"mode" validate:"required,oneof=all_or_nothing independent"`
+	Orders []BatchOrderEntryDTO `json:"orders" validate:"required,min=1,max=500,dive"`
+}
+
+// BatchOrderEntryDTO representa una orden del lote con su clave de idempotencia opcional,
+// que se comparte con la cabecera Idempotency-Key de POST /orders
+type BatchOrderEntryDTO struct {
+	IdempotencyKey string `json:"idempotency_key,omitempty" validate:"omitempty,max=128"`
+	OrderRequestDTO
+}
+
+// BatchOrderResponseDTO representa el resultado de un lote de órdenes
+type BatchOrderResponseDTO struct {
+	Mode      string                `json:"mode"`
+	Total     int                   `json:"total"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []BatchOrderResultDTO `json:"results"`
+}
+
+// BatchOrderResultDTO representa el resultado de una orden del lote, en la misma posición que en la solicitud
+type BatchOrderResultDTO struct {
+	Index          int               `json:"index"`
+	IdempotencyKey string            `json:"idempotency_key,omitempty"`
+	Status         string            `json:"status"`
+	OrderID        uint              `json:"order_id,omitempty"`
+	Error          *ErrorResponseDTO `json:"error,omitempty"`
+}
@@ -6,8 +6,15 @@ type OrderRequestDTO struct {
 	Items        []OrderItemRequestDTO `json:"items" validate:"required,dive"`
 }
 
-// OrderItemRequestDTO representa los items relacionados a la orden
+// OrderItemRequestDTO representa los items relacionados a la orden. El máximo de quantity es
+// models.MaxOrderItemQuantity.
 type OrderItemRequestDTO struct {
-	ProductID uint `json:"product_id" validate:"required"`
-	Quantity  int  `json:"quantity" validate:"required,gt=0"`
+	ProductID uint  `json:"product_id" validate:"required"`
+	VariantID *uint `json:"variant_id,omitempty"`
+	Quantity  int   `json:"quantity" validate:"required,gt=0,lte=10000"`
+}
+
+// UpdateOrderStatusRequestDTO representa el payload recibido para cambiar el estado de una orden
+type UpdateOrderStatusRequestDTO struct {
+	Status string `json:"status" validate:"required,oneof=confirmed shipped delivered"`
 }
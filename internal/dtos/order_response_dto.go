@@ -5,6 +5,7 @@ type OrderResponseDTO struct {
 	ID           uint                   `json:"id"`
 	CustomerName string                 `json:"customer_name"`
 	TotalAmount  float64                `json:"total_amount"`
+	Status       string                 `json:"status"`
 	Items        []OrderItemResponseDTO `json:"items"`
 }
 
@@ -13,6 +14,8 @@ type OrderItemResponseDTO struct {
 	ID          uint    `json:"id"`
 	ProductID   uint    `json:"product_id"`
 	ProductName string  `json:"product_name"`
+	VariantID   *uint   `json:"variant_id,omitempty"`
+	SKU         string  `json:"sku,omitempty"`
 	Quantity    int     `json:"quantity"`
 	Subtotal    float64 `json:"subtotal"`
 }
@@ -0,0 +1,41 @@
+package dtos
+
+import "time"
+
+// OrderV2ResponseDTO representa la orden en la versión 2 de la API. Agrupa los datos del cliente,
+// del producto y de la variante en objetos anidados e informa el precio unitario de cada item.
+type OrderV2ResponseDTO struct {
+	ID        uint                     `json:"id"`
+	Customer  OrderCustomerV2DTO       `json:"customer"`
+	Items     []OrderItemV2ResponseDTO `json:"items"`
+	Total     float64                  `json:"total"`
+	Status    string                   `json:"status"`
+	CreatedAt time.Time                `json:"created_at"`
+}
+
+// OrderCustomerV2DTO representa al cliente que realizó la orden
+type OrderCustomerV2DTO struct {
+	Name string `json:"name"`
+}
+
+// OrderItemV2ResponseDTO representa un item de la orden en la versión 2 de la API
+type OrderItemV2ResponseDTO struct {
+	ID        uint               `json:"id"`
+	Product   OrderProductV2DTO  `json:"product"`
+	Variant   *OrderVariantV2DTO `json:"variant,omitempty"`
+	Quantity  int                `json:"quantity"`
+	UnitPrice float64            `json:"unit_price"`
+	Subtotal  float64            `json:"subtotal"`
+}
+
+// OrderProductV2DTO identifica el producto de un item
+type OrderProductV2DTO struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// OrderVariantV2DTO identifica la variante de un item
+type OrderVariantV2DTO struct {
+	ID  uint   `json:"id"`
+	SKU string `json:"sku"`
+}
@@ -2,13 +2,24 @@ package dtos
 
 // ProductResponseDTO representa la respuesta que se envía al cliente
 type ProductResponseDTO struct {
-	ID    uint    `json:"id"`
-	Name  string  `json:"name"`
-	Price float64 `json:"price"`
-	Stock int     `json:"stock"`
+	ID         uint                        `json:"id"`
+	Name       string                      `json:"name"`
+	Price      float64                     `json:"price"`
+	Stock      int                         `json:"stock"`
+	CategoryID *uint                       `json:"category_id"`
+	Variants   []ProductVariantResponseDTO `json:"variants"`
+}
+
+// ProductVariantResponseDTO representa una variante anidada dentro de un producto
+type ProductVariantResponseDTO struct {
+	ID         uint              `json:"id"`
+	SKU        string            `json:"sku"`
+	Attributes map[string]string `json:"attributes"`
+	Price      float64           `json:"price"`
+	Stock      int               `json:"stock"`
 }
 
 // UpdateStocRequestkDTO representa el payload recibido en la actualización del stock de productos
 type UpdateStocRequestkDTO struct {
-	Stock int `json:"stock"`
+	Stock int `json:"stock" validate:"gte=0"`
 }
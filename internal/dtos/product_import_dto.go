@@ -0,0 +1,27 @@
+package dtos
+
+// ProductImportQueryDTO representa los parámetros de la importación masiva de productos
+type ProductImportQueryDTO struct {
+	DryRun bool `query:"dry_run"`
+}
+
+// ProductImportReportDTO representa el reporte de validación de una importación masiva
+type ProductImportReportDTO struct {
+	DryRun    bool                        `json:"dry_run"`
+	Applied   bool                        `json:"applied"`
+	TotalRows int                         `json:"total_rows"`
+	Created   int                         `json:"created"`
+	Updated   int                         `json:"updated"`
+	Failed    int                         `json:"failed"`
+	Rows      []ProductImportRowResultDTO `json:"rows"`
+}
+
+// ProductImportRowResultDTO representa el resultado de una fila del CSV importado
+type ProductImportRowResultDTO struct {
+	Line      int      `json:"line"`
+	Action    string   `json:"action"`
+	ProductID uint     `json:"product_id,omitempty"`
+	VariantID uint     `json:"variant_id,omitempty"`
+	SKU       string   `json:"sku,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
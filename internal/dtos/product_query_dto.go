@@ -0,0 +1,22 @@
+package dtos
+
+// ProductListQueryDTO representa los parámetros de búsqueda, filtrado, ordenamiento y paginación del catálogo
+type ProductListQueryDTO struct {
+	Query      string   `query:"q"`
+	CategoryID *uint    `query:"category_id"`
+	MinPrice   *float64 `query:"min_price" validate:"omitempty,gte=0"`
+	MaxPrice   *float64 `query:"max_price" validate:"omitempty,gte=0"`
+	InStock    bool     `query:"in_stock"`
+	Sort       string   `query:"sort" validate:"omitempty,oneof=id -id name -name price -price stock -stock created_at -created_at"`
+	Page       int      `query:"page" validate:"omitempty,gte=1"`
+	PageSize   int      `query:"page_size" validate:"omitempty,gte=1,lte=100"`
+}
+
+// ProductPageResponseDTO representa una página de productos junto con el total de coincidencias
+type ProductPageResponseDTO struct {
+	Items      []ProductResponseDTO `json:"items"`
+	Total      int64                `json:"total"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+	TotalPages int                  `json:"total_pages"`
+}
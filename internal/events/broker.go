@@ -0,0 +1,210 @@
+// Package events distribuye en tiempo real los eventos de órdenes y stock. Los servicios publican
+// en un canal de Redis y cada instancia de la aplicación reenvía lo que recibe de ese canal a sus
+// propios suscriptores, de modo que todos los clientes ven los mismos eventos sin importar la
+// instancia que atendió el cambio ni la que mantiene su conexión.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"order_management/internal/ports"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	Channel = "events"
+	// SubscriberBuffer es la cantidad de eventos que puede acumular un suscriptor lento antes de perder eventos
+	SubscriberBuffer = 64
+	// RetryBaseBackoff es la espera antes de volver a suscribirse al canal tras un error; se duplica
+	// en cada intento fallido hasta MaxRetryBackoff
+	RetryBaseBackoff = 500 * time.Millisecond
+	MaxRetryBackoff  = 30 * time.Second
+)
+
+var errSubscriptionClosed = errors.New("la suscripción al canal de Redis se cerró")
+
+type subscriber struct {
+	filter    ports.EventFilter
+	events    chan ports.Event
+	closeOnce sync.Once
+}
+
+// close cierra el canal de eventos una sola vez. Debe llamarse con el mutex del broker tomado para
+// no cerrarlo mientras dispatch envía un evento.
+func (s *subscriber) close() {
+	s.closeOnce.Do(func() { close(s.events) })
+}
+
+// Broker publica eventos en Redis y los reparte entre los suscriptores locales
+type Broker struct {
+	redisClient *redis.Client
+
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+// NewBroker crea un broker sobre el cliente de Redis. Run debe estar en ejecución para que los
+// suscriptores reciban eventos.
+func NewBroker(redisClient *redis.Client) *Broker {
+	return &Broker{
+		redisClient: redisClient,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish envía el evento a todas las instancias a través de Redis
+func (b *Broker) Publish(ctx context.Context, event ports.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.redisClient.Publish(ctx, Channel, payload).Err()
+}
+
+// Run recibe los eventos del canal de Redis y los reparte entre los suscriptores locales hasta
+// que se cancela ctx. Si no puede suscribirse, por ejemplo porque Redis no está disponible al
+// iniciar, o pierde la suscripción, lo registra y vuelve a intentarlo con backoff exponencial; los
+// eventos publicados mientras tanto no llegan a los suscriptores de esta instancia.
+func (b *Broker) Run(ctx context.Context) {
+	backoff := RetryBaseBackoff
+	for {
+		subscribed, err := b.receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		// Una suscripción que llegó a confirmarse reinicia la espera
+		if subscribed {
+			backoff = RetryBaseBackoff
+		}
+
+		slog.WarnContext(ctx, "Error en la suscripción a los eventos de Redis, se reintenta", "wait", backoff.String(), "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, MaxRetryBackoff)
+	}
+}
+
+// receive se suscribe al canal de Redis y reparte los eventos hasta que se cancela ctx o se cierra
+// la suscripción. subscribed indica si la suscripción llegó a confirmarse.
+func (b *Broker) receive(ctx context.Context) (subscribed bool, err error) {
+	pubsub := b.redisClient.Subscribe(ctx, Channel)
+	defer pubsub.Close()
+
+	// Esperar la confirmación de la suscripción para no perder los primeros eventos
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return false, err
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case message, ok := <-messages:
+			if !ok {
+				return true, errSubscriptionClosed
+			}
+
+			var event ports.Event
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				slog.WarnContext(ctx, "Evento inválido recibido de Redis", "error", err)
+				continue
+			}
+			b.dispatch(event)
+		}
+	}
+}
+
+// Subscribe registra un suscriptor local. cancel lo elimina y cierra el canal de eventos. Si el
+// broker ya se cerró, el canal se devuelve cerrado.
+func (b *Broker) Subscribe(filter ports.EventFilter) (<-chan ports.Event, func()) {
+	sub := &subscriber{filter: filter, events: make(chan ports.Event, SubscriberBuffer)}
+
+	b.mu.Lock()
+	if b.closed {
+		sub.close()
+	} else {
+		b.subscribers[sub] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, sub)
+		sub.close()
+	}
+
+	return sub.events, cancel
+}
+
+// Close cierra el canal de todos los suscriptores para que las conexiones de eventos terminen
+// durante el apagado, y rechaza las suscripciones nuevas
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		sub.close()
+	}
+}
+
+// dispatch entrega el evento a los suscriptores cuyo filtro lo acepta sin bloquearse por los lentos
+func (b *Broker) dispatch(event ports.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if !matches(sub.filter, event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			slog.Warn("Suscriptor lento: se descarta el evento", "type", event.Type)
+		}
+	}
+}
+
+// matches indica si el evento cumple todos los criterios informados en el filtro
+func matches(filter ports.EventFilter, event ports.Event) bool {
+	switch {
+	case event.Order != nil:
+		if filter.OrderID != 0 && event.Order.ID != filter.OrderID {
+			return false
+		}
+		if filter.CustomerName != "" && event.Order.CustomerName != filter.CustomerName {
+			return false
+		}
+		if filter.ProductID != 0 {
+			for _, item := range event.Order.OrderItems {
+				if item.ProductID == filter.ProductID {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	case event.Stock != nil:
+		// Los cambios de stock no pertenecen a una orden ni a un cliente
+		if filter.OrderID != 0 || filter.CustomerName != "" {
+			return false
+		}
+		return filter.ProductID == 0 || event.Stock.ProductID == filter.ProductID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,165 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"order_management/internal/models"
+	"order_management/internal/ports"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestBrokers crea dos brokers sobre el mismo Redis, como dos instancias de la aplicación,
+// y espera a que ambos estén suscritos al canal
+func newTestBrokers(t *testing.T) (*Broker, *Broker) {
+	redisServer := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	brokers := make([]*Broker, 2)
+	for i := range brokers {
+		redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+		brokers[i] = NewBroker(redisClient)
+		go brokers[i].Run(ctx)
+		t.Cleanup(func() { redisClient.Close() })
+	}
+	t.Cleanup(cancel)
+
+	assert.Eventually(t, func() bool {
+		return redisServer.PubSubNumSub(Channel)[Channel] == len(brokers)
+	}, time.Second, 10*time.Millisecond)
+
+	return brokers[0], brokers[1]
+}
+
+func receive(t *testing.T, events <-chan ports.Event) ports.Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no se recibió el evento")
+		return ports.Event{}
+	}
+}
+
+// TestBroker_FansOutAcrossInstances verifica que un evento publicado en una instancia llegue a los suscriptores de otra
+func TestBroker_FansOutAcrossInstances(t *testing.T) {
+	publisher, receiver := newTestBrokers(t)
+
+	events, cancel := receiver.Subscribe(ports.EventFilter{})
+	defer cancel()
+
+	order := &models.Order{ID: 7, CustomerName: "Customer 1", TotalAmount: 200}
+	err := publisher.Publish(context.Background(), ports.Event{Type: ports.EventOrderCreated, Order: order})
+	assert.NoError(t, err)
+
+	event := receive(t, events)
+	assert.Equal(t, ports.EventOrderCreated, event.Type)
+	assert.Equal(t, uint(7), event.Order.ID)
+	assert.Equal(t, "Customer 1", event.Order.CustomerName)
+}
+
+// TestBroker_AppliesSubscriberFilter verifica que cada suscriptor reciba solo los eventos que cumplen su filtro
+func TestBroker_AppliesSubscriberFilter(t *testing.T) {
+	publisher, receiver := newTestBrokers(t)
+
+	byProduct, cancelProduct := receiver.Subscribe(ports.EventFilter{ProductID: 2})
+	defer cancelProduct()
+	byCustomer, cancelCustomer := receiver.Subscribe(ports.EventFilter{CustomerName: "Customer 2"})
+	defer cancelCustomer()
+
+	orders := []*models.Order{
+		{ID: 1, CustomerName: "Customer 1", OrderItems: []models.OrderItem{{ProductID: 1}}},
+		{ID: 2, CustomerName: "Customer 2", OrderItems: []models.OrderItem{{ProductID: 2}}},
+	}
+	for _, order := range orders {
+		assert.NoError(t, publisher.Publish(context.Background(), ports.Event{Type: ports.EventOrderCreated, Order: order}))
+	}
+	assert.NoError(t, publisher.Publish(context.Background(), ports.Event{Type: ports.EventStockChanged, Stock: &ports.StockChange{ProductID: 2, Stock: 5}}))
+
+	// El filtro por producto recibe la orden que lo incluye y su cambio de stock
+	assert.Equal(t, uint(2), receive(t, byProduct).Order.ID)
+	assert.Equal(t, 5, receive(t, byProduct).Stock.Stock)
+
+	// El filtro por cliente solo recibe su orden: los cambios de stock no pertenecen a un cliente
+	assert.Equal(t, uint(2), receive(t, byCustomer).Order.ID)
+	select {
+	case event := <-byCustomer:
+		t.Fatalf("evento inesperado: %s", event.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestBroker_CancelClosesSubscription verifica que al cancelar la suscripción se cierre el canal
+func TestBroker_CancelClosesSubscription(t *testing.T) {
+	broker := NewBroker(nil)
+
+	events, cancel := broker.Subscribe(ports.EventFilter{})
+	cancel()
+	cancel()
+
+	_, ok := <-events
+	assert.False(t, ok)
+	assert.Empty(t, broker.subscribers)
+}
+
+// TestBroker_CloseEndsSubscriptions verifica que al cerrar el broker terminen las suscripciones
+// abiertas y las nuevas, sin afectar a la cancelación posterior
+func TestBroker_CloseEndsSubscriptions(t *testing.T) {
+	broker := NewBroker(nil)
+
+	events, cancel := broker.Subscribe(ports.EventFilter{})
+	broker.Close()
+	cancel()
+
+	_, ok := <-events
+	assert.False(t, ok)
+	assert.Empty(t, broker.subscribers)
+
+	late, cancelLate := broker.Subscribe(ports.EventFilter{})
+	defer cancelLate()
+	_, ok = <-late
+	assert.False(t, ok)
+}
+
+// TestBroker_RunRetriesSubscription verifica que Run siga intentando suscribirse mientras Redis no
+// está disponible y que, una vez suscrito, entregue los eventos
+func TestBroker_RunRetriesSubscription(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer redisClient.Close()
+	redisServer.Close()
+
+	broker := NewBroker(redisClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		broker.Run(ctx)
+	}()
+
+	// Dejar fallar el primer intento antes de que Redis vuelva a estar disponible
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, redisServer.Restart())
+
+	assert.Eventually(t, func() bool {
+		return redisServer.PubSubNumSub(Channel)[Channel] == 1
+	}, 3*RetryBaseBackoff, 10*time.Millisecond)
+
+	events, cancelSubscription := broker.Subscribe(ports.EventFilter{})
+	defer cancelSubscription()
+	assert.NoError(t, broker.Publish(context.Background(), ports.Event{Type: ports.EventStockChanged, Stock: &ports.StockChange{ProductID: 1, Stock: 3}}))
+	assert.Equal(t, 3, receive(t, events).Stock.Stock)
+
+	// Run termina al cancelar ctx aunque esté esperando para reintentar
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run no terminó al cancelar el contexto")
+	}
+}
@@ -0,0 +1,4 @@
+// Package graphqlapi expone las órdenes y el catálogo por GraphQL sobre los servicios existentes.
+// Las relaciones (productos de los items, categorías) se resuelven con loaders por solicitud que
+// agrupan las búsquedas por ID en una sola consulta, y la profundidad de las consultas está limitada.
+package graphqlapi
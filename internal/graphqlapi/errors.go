@@ -0,0 +1,59 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/dtos"
+	"order_management/internal/i18n"
+	"order_management/internal/validators"
+)
+
+// resolverError expone el código, los detalles y los errores por campo de un error de dominio en las
+// extensions del error GraphQL, con el mensaje ya traducido al idioma del cliente
+type resolverError struct {
+	message string
+	code    apperrors.Code
+	details map[string]interface{}
+	fields  []dtos.FieldErrorDTO
+}
+
+func (e resolverError) Error() string {
+	return e.message
+}
+
+func (e resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": string(e.code)}
+	if len(e.details) > 0 {
+		extensions["details"] = e.details
+	}
+	if len(e.fields) > 0 {
+		extensions["fields"] = e.fields
+	}
+	return extensions
+}
+
+// toGraphQLError traduce un error de los servicios o de la validación al idioma de la solicitud, igual
+// que HTTPErrorHandler en la API REST; la causa de los errores inesperados no se expone
+func toGraphQLError(ctx context.Context, err error) error {
+	lang := i18n.LanguageFromContext(ctx)
+
+	// Errores de validación de los DTOs: un error traducido por cada campo
+	var validationErr *validators.ValidationError
+	if errors.As(err, &validationErr) {
+		return resolverError{
+			message: i18n.Translate(lang, "la solicitud contiene campos inválidos"),
+			code:    apperrors.CodeValidationFailed,
+			fields:  validationErr.Fields(lang),
+		}
+	}
+
+	if appErr, ok := apperrors.As(err); ok {
+		if appErr.Kind == apperrors.KindInternal {
+			return resolverError{message: appErr.Localize(lang), code: appErr.Code}
+		}
+		return resolverError{message: appErr.Localize(lang), code: appErr.Code, details: appErr.Details}
+	}
+	return resolverError{message: i18n.Translate(lang, "error interno del servidor"), code: apperrors.CodeInternal}
+}
@@ -0,0 +1,43 @@
+package graphqlapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"order_management/internal/ports"
+	"order_management/internal/validators"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+const (
+	// Path es la ruta donde se publica el endpoint GraphQL
+	Path = "/graphql"
+	// MaxQueryDepth limita el anidamiento de las consultas para evitar consultas abusivas
+	MaxQueryDepth = 8
+	// BodyLimit es el tamaño máximo de un documento GraphQL con sus variables. Se aplica antes de
+	// IdempotencyMiddleware, que lee el cuerpo completo para calcular la huella de la solicitud.
+	BodyLimit = "1M"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// NewHandler crea el handler HTTP del endpoint GraphQL
+func NewHandler(orderService ports.OrderService, productService ports.ProductService, categoryService ports.CategoryService) http.Handler {
+	schema := graphql.MustParseSchema(schemaSDL, &rootResolver{
+		orderService:   orderService,
+		productService: productService,
+		validator:      validators.NewValidator(),
+	}, graphql.MaxDepth(MaxQueryDepth))
+
+	next := &relay.Handler{Schema: schema}
+
+	// Cada solicitud tiene sus propios loaders para no compartir la caché entre clientes
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := withLoaders(r.Context(), newLoaders(r.Context(), productService, categoryService))
+		ctx = withIdempotencyLockClaim(ctx)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,387 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/i18n"
+	"order_management/internal/idempotency"
+	"order_management/internal/middlewares"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// graphQLResponse representa el cuerpo de una respuesta GraphQL
+type graphQLResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// execute envía la consulta al handler y decodifica la respuesta
+func execute(t *testing.T, handler http.Handler, query string) graphQLResponse {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response graphQLResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return response
+}
+
+func TestGraphQLOrders_BatchesProductAndCategoryLookups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	mockProductService := mocks.NewMockProductService(ctrl)
+	mockCategoryService := mocks.NewMockCategoryService(ctrl)
+	handler := NewHandler(mockOrderService, mockProductService, mockCategoryService)
+
+	variantID := uint(10)
+	clothing, electronics, books := uint(5), uint(6), uint(7)
+	products := []models.Product{
+		{ID: 1, Name: "Laptop", CategoryID: &electronics},
+		{ID: 2, Name: "Camisa", Price: 20, CategoryID: &clothing, Variants: []models.ProductVariant{{ID: 10, SKU: "CAM-M"}}},
+		{ID: 3, Name: "Mouse"},
+		{ID: 4, Name: "Novela", CategoryID: &books},
+	}
+	// Muchas órdenes cuyos productos pertenecen a categorías distintas
+	orders := []models.Order{
+		{ID: 2, CustomerName: "Ana", OrderItems: []models.OrderItem{{ID: 3, ProductID: 1}, {ID: 4, ProductID: 2, VariantID: &variantID}}},
+		{ID: 1, CustomerName: "Luis", OrderItems: []models.OrderItem{{ID: 1, ProductID: 1}, {ID: 2, ProductID: 3}}},
+	}
+	for i := uint(0); i < 30; i++ {
+		orders = append(orders, models.Order{
+			ID:           100 + i,
+			CustomerName: "Cliente",
+			OrderItems:   []models.OrderItem{{ID: 100 + i, ProductID: products[i%uint(len(products))].ID}},
+		})
+	}
+
+	mockOrderService.EXPECT().SearchOrders(gomock.Any(), ports.OrderFilter{Page: 1, PageSize: 20}).Return(orders, int64(len(orders)), nil).Times(1)
+	// Los productos de todos los items se buscan en una sola llamada
+	mockProductService.EXPECT().GetProductsByIDs(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, ids []uint) ([]models.Product, error) {
+		assert.ElementsMatch(t, []uint{1, 2, 3, 4}, ids)
+		return products, nil
+	}).Times(1)
+	// Y las categorías de todos esos productos también
+	mockCategoryService.EXPECT().GetCategoriesByIDs(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, ids []uint) ([]models.Category, error) {
+		assert.ElementsMatch(t, []uint{5, 6, 7}, ids)
+		return []models.Category{{ID: 5, Name: "Ropa"}, {ID: 6, Name: "Electrónica"}, {ID: 7, Name: "Libros"}}, nil
+	}).Times(1)
+
+	response := execute(t, handler, `{
+		orders {
+			total
+			items {
+				id
+				customer { name }
+				items { product { name category { name } } variant { sku price } }
+			}
+		}
+	}`)
+
+	assert.Empty(t, response.Errors)
+	page := response.Data["orders"].(map[string]interface{})
+	assert.Equal(t, float64(len(orders)), page["total"])
+
+	first := page["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Ana", first["customer"].(map[string]interface{})["name"])
+	items := first["items"].([]interface{})
+	assert.Equal(t, "Laptop", items[0].(map[string]interface{})["product"].(map[string]interface{})["name"])
+	assert.Equal(t, "CAM-M", items[1].(map[string]interface{})["variant"].(map[string]interface{})["sku"])
+	assert.Equal(t, float64(20), items[1].(map[string]interface{})["variant"].(map[string]interface{})["price"])
+	assert.Equal(t, "Ropa", items[1].(map[string]interface{})["product"].(map[string]interface{})["category"].(map[string]interface{})["name"])
+
+	// Cada producto recibe su propia categoría aunque todas se hayan buscado juntas
+	categoryNames := map[string]interface{}{"Laptop": "Electrónica", "Camisa": "Ropa", "Novela": "Libros"}
+	for _, order := range page["items"].([]interface{}) {
+		for _, item := range order.(map[string]interface{})["items"].([]interface{}) {
+			product := item.(map[string]interface{})["product"].(map[string]interface{})
+			if expected, ok := categoryNames[product["name"].(string)]; ok {
+				assert.Equal(t, expected, product["category"].(map[string]interface{})["name"], product["name"])
+			} else {
+				assert.Nil(t, product["category"], product["name"])
+			}
+		}
+	}
+}
+
+func TestGraphQLOrder_NotFoundIsNull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	handler := NewHandler(mockOrderService, mocks.NewMockProductService(ctrl), mocks.NewMockCategoryService(ctrl))
+
+	mockOrderService.EXPECT().GetOrderById(gomock.Any(), uint(99)).
+		Return(nil, apperrors.NotFound(apperrors.CodeOrderNotFound, "Order not found")).Times(1)
+
+	response := execute(t, handler, `{ order(id: "99") { id } }`)
+
+	assert.Empty(t, response.Errors)
+	assert.Nil(t, response.Data["order"])
+}
+
+func TestGraphQLCreateOrder_ReportsDomainErrorCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	handler := NewHandler(mockOrderService, mocks.NewMockProductService(ctrl), mocks.NewMockCategoryService(ctrl))
+
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).Return(apperrors.InsufficientStock(1, nil, 5, 2)).Times(1)
+
+	response := execute(t, handler, `mutation {
+		createOrder(input: {customerName: "Ana", items: [{productId: "1", quantity: 5}]}) { id }
+	}`)
+
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, string(apperrors.CodeInsufficientStock), response.Errors[0].Extensions["code"])
+	}
+}
+
+func TestGraphQLCreateOrder_PassesIdempotencyLockToService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	handler := NewHandler(mockOrderService, mocks.NewMockProductService(ctrl), mocks.NewMockCategoryService(ctrl))
+	lock := mocks.NewMockIdempotencyLock(ctrl)
+
+	// El servicio recibe la reserva que el middleware de idempotencia deja en el contexto
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), lock).DoAndReturn(func(_ context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+		order.ID = 7
+		return nil
+	}).Times(1)
+
+	body, _ := json.Marshal(map[string]string{"query": `mutation {
+		createOrder(input: {customerName: "Ana", items: [{productId: "1", quantity: 1}]}) { id }
+	}`})
+	req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body)))
+	req = req.WithContext(middlewares.WithIdempotencyLock(req.Context(), lock))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	var response graphQLResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, "7", response.Data["createOrder"].(map[string]interface{})["id"])
+}
+
+func TestGraphQLCreateOrder_ValidatesInput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// El servicio no se invoca si la solicitud no pasa la validación
+	handler := NewHandler(mocks.NewMockOrderService(ctrl), mocks.NewMockProductService(ctrl), mocks.NewMockCategoryService(ctrl))
+
+	response := execute(t, handler, `mutation {
+		createOrder(input: {customerName: "Ana", items: [{productId: "1", quantity: 0}]}) { id }
+	}`)
+
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, string(apperrors.CodeValidationFailed), response.Errors[0].Extensions["code"])
+		assert.Equal(t, "la solicitud contiene campos inválidos", response.Errors[0].Message)
+		fields, _ := response.Errors[0].Extensions["fields"].([]interface{})
+		if assert.Len(t, fields, 1) {
+			assert.Equal(t, "items[0].quantity", fields[0].(map[string]interface{})["field"])
+			assert.Equal(t, "required", fields[0].(map[string]interface{})["rule"])
+		}
+	}
+}
+
+func TestGraphQLErrors_AreLocalized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := NewHandler(mocks.NewMockOrderService(ctrl), mocks.NewMockProductService(ctrl), mocks.NewMockCategoryService(ctrl))
+
+	// LanguageMiddleware deja en el contexto el idioma negociado con Accept-Language
+	send := func(query string) graphQLResponse {
+		body, _ := json.Marshal(map[string]string{"query": query})
+		req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(i18n.WithLanguage(req.Context(), "en"))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var response graphQLResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+
+	invalid := send(`mutation {
+		createOrder(input: {customerName: "Ana", items: [{productId: "1", quantity: 0}]}) { id }
+	}`)
+	invalidID := send(`{ order(id: "abc") { id } }`)
+	invalidPage := send(`{ orders(page: 0, pageSize: 10) { total } }`)
+
+	if assert.Len(t, invalid.Errors, 1) {
+		assert.Equal(t, "the request contains invalid fields", invalid.Errors[0].Message)
+		fields, _ := invalid.Errors[0].Extensions["fields"].([]interface{})
+		if assert.Len(t, fields, 1) {
+			assert.Equal(t, "quantity is a required field", fields[0].(map[string]interface{})["message"])
+		}
+	}
+	if assert.Len(t, invalidID.Errors, 1) {
+		assert.Equal(t, "Invalid ID", invalidID.Errors[0].Message)
+		assert.Equal(t, string(apperrors.CodeInvalidRequest), invalidID.Errors[0].Extensions["code"])
+	}
+	if assert.Len(t, invalidPage.Errors, 1) {
+		assert.Equal(t, "page must be greater than 0 and pageSize between 1 and 100", invalidPage.Errors[0].Message)
+	}
+}
+
+func TestGraphQLUpdateStock_ReturnsUpdatedProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProductService(ctrl)
+	handler := NewHandler(mocks.NewMockOrderService(ctrl), mockProductService, mocks.NewMockCategoryService(ctrl))
+
+	mockProductService.EXPECT().UpdateStock(gomock.Any(), uint(1), 15).Return(nil).Times(1)
+	mockProductService.EXPECT().GetProductsByIDs(gomock.Any(), []uint{1}).Return([]models.Product{{ID: 1, Name: "Laptop", Stock: 15}}, nil).Times(1)
+
+	response := execute(t, handler, `mutation { updateStock(productId: "1", stock: 15) { id stock } }`)
+
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, float64(15), response.Data["updateStock"].(map[string]interface{})["stock"])
+}
+
+func TestGraphQL_RejectsQueriesDeeperThanLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// La consulta se rechaza antes de ejecutar cualquier resolver
+	handler := NewHandler(mocks.NewMockOrderService(ctrl), mocks.NewMockProductService(ctrl), mocks.NewMockCategoryService(ctrl))
+
+	response := execute(t, handler, `{
+		orders { items { items { product { category { parent { parent { parent { parent { name } } } } } } } } }
+	}`)
+
+	if assert.NotEmpty(t, response.Errors) {
+		assert.Contains(t, response.Errors[0].Message, "exceeds max depth")
+	}
+}
+
+// newIdempotentServer publica el handler GraphQL detrás del middleware de idempotencia
+func newIdempotentServer(handler http.Handler) *echo.Echo {
+	e := echo.New()
+	e.POST(Path, echo.WrapHandler(handler), IdempotencyMiddleware(idempotency.NewMemoryStore(idempotency.DefaultTTL), time.Hour))
+	return e
+}
+
+// executeIdempotent envía la consulta con la Idempotency-Key indicada
+func executeIdempotent(e *echo.Echo, query, idempotencyKey string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGraphQLUpdateStock_IdempotencyKeyReplaysResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProductService(ctrl)
+	e := newIdempotentServer(NewHandler(mocks.NewMockOrderService(ctrl), mockProductService, mocks.NewMockCategoryService(ctrl)))
+
+	// El reintento no vuelve a ajustar el stock
+	mockProductService.EXPECT().UpdateStock(gomock.Any(), uint(1), 15).Return(nil).Times(1)
+	mockProductService.EXPECT().UpdateVariantStock(gomock.Any(), uint(1), uint(10), 3).Return(nil).Times(1)
+	mockProductService.EXPECT().GetProductsByIDs(gomock.Any(), []uint{1}).Return([]models.Product{{ID: 1, Name: "Laptop", Stock: 15}}, nil).Times(2)
+
+	for _, query := range []string{
+		`mutation { updateStock(productId: "1", stock: 15) { id stock } }`,
+		`mutation { updateVariantStock(productId: "1", variantId: "10", stock: 3) { id } }`,
+	} {
+		first := executeIdempotent(e, query, query)
+		retry := executeIdempotent(e, query, query)
+
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(middlewares.HeaderIdempotentReplayed))
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+	}
+}
+
+func TestGraphQLCreateOrder_IdempotencyKeyReleasedOnErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	e := newIdempotentServer(NewHandler(mockOrderService, mocks.NewMockProductService(ctrl), mocks.NewMockCategoryService(ctrl)))
+	query := `mutation { createOrder(input: {customerName: "Ana", items: [{productId: "1", quantity: 1}]}) { id } }`
+
+	// El primer intento falla con una respuesta 200 con errores; la clave se libera y el reintento
+	// crea la orden, que se repite en el tercer intento
+	gomock.InOrder(
+		mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).Return(apperrors.InsufficientStock(1, nil, 1, 0)),
+		mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).DoAndReturn(func(_ context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+			order.ID = 7
+			return nil
+		}),
+	)
+
+	failed := executeIdempotent(e, query, "key-1")
+	created := executeIdempotent(e, query, "key-1")
+	replayed := executeIdempotent(e, query, "key-1")
+
+	assert.Contains(t, failed.Body.String(), "errors")
+	assert.Empty(t, created.Header().Get(middlewares.HeaderIdempotentReplayed))
+	assert.Equal(t, "true", replayed.Header().Get(middlewares.HeaderIdempotentReplayed))
+	assert.JSONEq(t, `{"data":{"createOrder":{"id":"7"}}}`, replayed.Body.String())
+}
+
+func TestGraphQLCreateOrder_IdempotencyLockClaimedOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	handler := NewHandler(mockOrderService, mocks.NewMockProductService(ctrl), mocks.NewMockCategoryService(ctrl))
+	lock := mocks.NewMockIdempotencyLock(ctrl)
+
+	// Solo la primera orden del documento se asocia con la clave
+	gomock.InOrder(
+		mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), lock).Return(nil),
+		mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Nil()).Return(nil),
+	)
+
+	body, _ := json.Marshal(map[string]string{"query": `mutation {
+		a: createOrder(input: {customerName: "Ana", items: [{productId: "1", quantity: 1}]}) { id }
+		b: createOrder(input: {customerName: "Luis", items: [{productId: "1", quantity: 1}]}) { id }
+	}`})
+	req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body)))
+	req = req.WithContext(middlewares.WithIdempotencyLock(req.Context(), lock))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	var response graphQLResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Empty(t, response.Errors)
+}
@@ -0,0 +1,68 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"order_management/internal/middlewares"
+	"order_management/internal/ports"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IdempotencyMiddleware aplica la Idempotency-Key a las solicitudes GraphQL. GraphQL responde 200
+// aunque una mutación falle, por lo que la clave se libera si la respuesta informa errores y solo
+// se repiten las respuestas sin errores.
+func IdempotencyMiddleware(store ports.IdempotencyStore, ttl time.Duration) echo.MiddlewareFunc {
+	return middlewares.IdempotencyMiddlewareWithConfig(store, middlewares.IdempotencyConfig{
+		TTL:     ttl,
+		Outcome: idempotencyOutcome,
+	})
+}
+
+// idempotencyOutcome libera la clave de las respuestas con errores, además de los códigos que libera
+// la política por defecto
+func idempotencyOutcome(statusCode int, body []byte) middlewares.ReplayAction {
+	var response struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Errors) > 0 {
+		return middlewares.ReplayRelease
+	}
+	return middlewares.DefaultIdempotencyPolicy.Action(statusCode)
+}
+
+// idempotencyLockClaim entrega la reserva de la clave a una sola mutación del documento. Una clave
+// identifica una operación, de modo que si el documento crea varias órdenes solo la primera queda
+// asociada con la clave.
+type idempotencyLockClaim struct {
+	mu   sync.Mutex
+	lock ports.IdempotencyLock
+}
+
+type idempotencyLockClaimKey struct{}
+
+// withIdempotencyLockClaim prepara la reserva de la solicitud, si la tiene, para que la tome una mutación
+func withIdempotencyLockClaim(ctx context.Context) context.Context {
+	lock := middlewares.IdempotencyLockFromRequest(ctx)
+	if lock == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyLockClaimKey{}, &idempotencyLockClaim{lock: lock})
+}
+
+// claimIdempotencyLock devuelve la reserva de la clave la primera vez y nil en las siguientes
+func claimIdempotencyLock(ctx context.Context) ports.IdempotencyLock {
+	claim, ok := ctx.Value(idempotencyLockClaimKey{}).(*idempotencyLockClaim)
+	if !ok {
+		return nil
+	}
+	claim.mu.Lock()
+	defer claim.mu.Unlock()
+
+	lock := claim.lock
+	claim.lock = nil
+	return lock
+}
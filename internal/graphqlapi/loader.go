@@ -0,0 +1,130 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+
+	"order_management/internal/models"
+	"order_management/internal/ports"
+)
+
+// batchLoader agrupa las búsquedas por ID de una misma solicitud en una sola consulta. Los
+// resolvers registran con Register los IDs que sus hijos van a necesitar; la primera llamada a
+// Load busca todos los IDs registrados a la vez y las siguientes los toman de la caché.
+type batchLoader[V any] struct {
+	mu      sync.Mutex
+	fetch   func(ids []uint) (map[uint]V, error)
+	pending map[uint]struct{}
+	loaded  map[uint]V
+	fetched map[uint]bool
+}
+
+func newBatchLoader[V any](fetch func(ids []uint) (map[uint]V, error)) *batchLoader[V] {
+	return &batchLoader[V]{
+		fetch:   fetch,
+		pending: make(map[uint]struct{}),
+		loaded:  make(map[uint]V),
+		fetched: make(map[uint]bool),
+	}
+}
+
+// Register anota IDs para buscarlos junto con la próxima llamada a Load
+func (l *batchLoader[V]) Register(ids ...uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.registerLocked(ids...)
+}
+
+// registerLocked es Register para quien ya tiene el mutex, como la función fetch del propio loader
+func (l *batchLoader[V]) registerLocked(ids ...uint) {
+	for _, id := range ids {
+		if !l.fetched[id] {
+			l.pending[id] = struct{}{}
+		}
+	}
+}
+
+// Load devuelve el valor del ID indicado y false si no existe
+func (l *batchLoader[V]) Load(id uint) (V, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.fetched[id] {
+		l.pending[id] = struct{}{}
+
+		ids := make([]uint, 0, len(l.pending))
+		for pendingID := range l.pending {
+			ids = append(ids, pendingID)
+		}
+
+		values, err := l.fetch(ids)
+		if err != nil {
+			var zero V
+			return zero, false, err
+		}
+
+		for _, pendingID := range ids {
+			l.fetched[pendingID] = true
+			delete(l.pending, pendingID)
+		}
+		for loadedID, value := range values {
+			l.loaded[loadedID] = value
+		}
+	}
+
+	value, ok := l.loaded[id]
+	return value, ok, nil
+}
+
+// loaders agrupa los loaders de una solicitud; se crean por solicitud para no compartir la caché
+type loaders struct {
+	products   *batchLoader[models.Product]
+	categories *batchLoader[models.Category]
+}
+
+// newLoaders crea los loaders de una solicitud. Cada lote de productos registra todas sus categorías
+// y cada lote de categorías registra sus padres, así los resolvers de un mismo nivel comparten una
+// sola búsqueda aunque se resuelvan por separado.
+func newLoaders(ctx context.Context, productService ports.ProductService, categoryService ports.CategoryService) *loaders {
+	l := &loaders{}
+	l.categories = newBatchLoader(func(ids []uint) (map[uint]models.Category, error) {
+		categories, err := categoryService.GetCategoriesByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]models.Category, len(categories))
+		for _, category := range categories {
+			byID[category.ID] = category
+			if category.ParentID != nil {
+				l.categories.registerLocked(*category.ParentID)
+			}
+		}
+		return byID, nil
+	})
+	l.products = newBatchLoader(func(ids []uint) (map[uint]models.Product, error) {
+		products, err := productService.GetProductsByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]models.Product, len(products))
+		for _, product := range products {
+			byID[product.ID] = product
+			if product.CategoryID != nil {
+				l.categories.Register(*product.CategoryID)
+			}
+		}
+		return byID, nil
+	})
+	return l
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,443 @@
+package graphqlapi
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/dtos"
+	"order_management/internal/mappers"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/internal/validators"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// rootResolver resuelve las queries y mutations sobre los servicios existentes
+type rootResolver struct {
+	orderService   ports.OrderService
+	productService ports.ProductService
+	validator      *validators.CustomValidator
+}
+
+// parseID convierte un ID de GraphQL en el ID numérico de los modelos
+func parseID(ctx context.Context, id graphql.ID) (uint, error) {
+	value, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil || value == 0 {
+		return 0, toGraphQLError(ctx, apperrors.Invalid(apperrors.CodeInvalidRequest, "ID inválido").WithDetail("id", string(id)))
+	}
+	return uint(value), nil
+}
+
+func formatID(id uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+// totalPages calcula la cantidad de páginas para el total de resultados
+func totalPages(total int64, pageSize int) int32 {
+	return int32((total + int64(pageSize) - 1) / int64(pageSize))
+}
+
+func (r *rootResolver) Order(ctx context.Context, args struct{ ID graphql.ID }) (*orderResolver, error) {
+	id, err := parseID(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := r.orderService.GetOrderById(ctx, id)
+	if apperrors.IsCode(err, apperrors.CodeOrderNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+
+	return newOrderResolver(*order, loadersFrom(ctx)), nil
+}
+
+func (r *rootResolver) Orders(ctx context.Context, args struct {
+	CustomerName *string
+	Page         int32
+	PageSize     int32
+}) (*orderPageResolver, error) {
+	filter := ports.OrderFilter{Page: int(args.Page), PageSize: int(args.PageSize)}
+	if args.CustomerName != nil {
+		filter.CustomerName = *args.CustomerName
+	}
+	if filter.Page < 1 || filter.PageSize < 1 || filter.PageSize > 100 {
+		return nil, toGraphQLError(ctx, apperrors.Invalid(apperrors.CodeInvalidRequest, "page debe ser mayor a 0 y pageSize estar entre 1 y 100"))
+	}
+
+	orders, total, err := r.orderService.SearchOrders(ctx, filter)
+	if err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+
+	l := loadersFrom(ctx)
+	page := &orderPageResolver{
+		items:    make([]*orderResolver, len(orders)),
+		total:    total,
+		page:     filter.Page,
+		pageSize: filter.PageSize,
+	}
+	for i, order := range orders {
+		page.items[i] = newOrderResolver(order, l)
+	}
+	return page, nil
+}
+
+func (r *rootResolver) Product(ctx context.Context, args struct{ ID graphql.ID }) (*productResolver, error) {
+	id, err := parseID(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := loadersFrom(ctx)
+	product, ok, err := l.products.Load(id)
+	if err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return newProductResolver(product, l), nil
+}
+
+func (r *rootResolver) Products(ctx context.Context, args struct {
+	Query      *string
+	CategoryID *graphql.ID
+	MinPrice   *float64
+	MaxPrice   *float64
+	InStock    bool
+	Sort       *string
+	Page       int32
+	PageSize   int32
+}) (*productPageResolver, error) {
+	queryDTO := dtos.ProductListQueryDTO{
+		MinPrice: args.MinPrice,
+		MaxPrice: args.MaxPrice,
+		InStock:  args.InStock,
+		Page:     int(args.Page),
+		PageSize: int(args.PageSize),
+	}
+	if args.Query != nil {
+		queryDTO.Query = *args.Query
+	}
+	if args.Sort != nil {
+		queryDTO.Sort = *args.Sort
+	}
+	if args.CategoryID != nil {
+		categoryID, err := parseID(ctx, *args.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		queryDTO.CategoryID = &categoryID
+	}
+	if err := r.validator.Validate(queryDTO); err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+
+	filter := mappers.ConvertProductListQueryDTOToFilter(queryDTO)
+
+	products, total, err := r.productService.SearchProducts(ctx, filter)
+	if err != nil {
+		return nil, toGraphQLError(ctx, apperrors.Internal(apperrors.CodeInternal, "error al obtener productos", err))
+	}
+
+	l := loadersFrom(ctx)
+	page := &productPageResolver{
+		items:    make([]*productResolver, len(products)),
+		total:    total,
+		page:     filter.Page,
+		pageSize: filter.PageSize,
+	}
+	for i, product := range products {
+		page.items[i] = newProductResolver(product, l)
+	}
+	return page, nil
+}
+
+// createOrderInput refleja el input CreateOrderInput del esquema
+type createOrderInput struct {
+	CustomerName string
+	Items        []struct {
+		ProductID graphql.ID
+		VariantID *graphql.ID
+		Quantity  int32
+	}
+}
+
+func (r *rootResolver) CreateOrder(ctx context.Context, args struct{ Input createOrderInput }) (*orderResolver, error) {
+	orderRequest := dtos.OrderRequestDTO{
+		CustomerName: args.Input.CustomerName,
+		Items:        make([]dtos.OrderItemRequestDTO, len(args.Input.Items)),
+	}
+	for i, item := range args.Input.Items {
+		productID, err := parseID(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		orderRequest.Items[i] = dtos.OrderItemRequestDTO{ProductID: productID, Quantity: int(item.Quantity)}
+		if item.VariantID != nil {
+			variantID, err := parseID(ctx, *item.VariantID)
+			if err != nil {
+				return nil, err
+			}
+			orderRequest.Items[i].VariantID = &variantID
+		}
+	}
+
+	// Validar con las mismas reglas que la API REST
+	if err := r.validator.Validate(orderRequest); err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+
+	// Con Idempotency-Key la clave se asocia con la orden en la misma transacción si el store lo permite
+	order := mappers.ConvertOrderRequestDTOToOrder(orderRequest)
+	if err := r.orderService.CreateOrderIdempotent(ctx, &order, claimIdempotencyLock(ctx)); err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+
+	return newOrderResolver(order, loadersFrom(ctx)), nil
+}
+
+func (r *rootResolver) UpdateStock(ctx context.Context, args struct {
+	ProductID graphql.ID
+	Stock     int32
+}) (*productResolver, error) {
+	productID, err := parseID(ctx, args.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.productService.UpdateStock(ctx, productID, int(args.Stock)); err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+	return r.reloadProduct(ctx, productID)
+}
+
+func (r *rootResolver) UpdateVariantStock(ctx context.Context, args struct {
+	ProductID graphql.ID
+	VariantID graphql.ID
+	Stock     int32
+}) (*productResolver, error) {
+	productID, err := parseID(ctx, args.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	variantID, err := parseID(ctx, args.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.productService.UpdateVariantStock(ctx, productID, variantID, int(args.Stock)); err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+	return r.reloadProduct(ctx, productID)
+}
+
+// reloadProduct lee el producto actualizado sin pasar por la caché de la solicitud
+func (r *rootResolver) reloadProduct(ctx context.Context, id uint) (*productResolver, error) {
+	products, err := r.productService.GetProductsByIDs(ctx, []uint{id})
+	if err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+	if len(products) == 0 {
+		return nil, toGraphQLError(ctx, apperrors.NotFound(apperrors.CodeProductNotFound, "producto no encontrado").WithDetail("product_id", id))
+	}
+	return newProductResolver(products[0], loadersFrom(ctx)), nil
+}
+
+type orderPageResolver struct {
+	items    []*orderResolver
+	total    int64
+	page     int
+	pageSize int
+}
+
+func (r *orderPageResolver) Items() []*orderResolver { return r.items }
+func (r *orderPageResolver) Total() int32            { return int32(r.total) }
+func (r *orderPageResolver) Page() int32             { return int32(r.page) }
+func (r *orderPageResolver) PageSize() int32         { return int32(r.pageSize) }
+func (r *orderPageResolver) TotalPages() int32       { return totalPages(r.total, r.pageSize) }
+
+type orderResolver struct {
+	order   models.Order
+	loaders *loaders
+}
+
+// newOrderResolver registra los productos de los items para buscarlos todos juntos
+func newOrderResolver(order models.Order, l *loaders) *orderResolver {
+	for _, item := range order.OrderItems {
+		l.products.Register(item.ProductID)
+	}
+	return &orderResolver{order: order, loaders: l}
+}
+
+func (r *orderResolver) ID() graphql.ID { return formatID(r.order.ID) }
+func (r *orderResolver) Customer() *customerResolver {
+	return &customerResolver{name: r.order.CustomerName}
+}
+func (r *orderResolver) TotalAmount() float64    { return r.order.TotalAmount }
+func (r *orderResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.order.CreatedAt} }
+
+func (r *orderResolver) Items() []*orderItemResolver {
+	items := make([]*orderItemResolver, len(r.order.OrderItems))
+	for i, item := range r.order.OrderItems {
+		items[i] = &orderItemResolver{item: item, loaders: r.loaders}
+	}
+	return items
+}
+
+type customerResolver struct {
+	name string
+}
+
+func (r *customerResolver) Name() string { return r.name }
+
+type orderItemResolver struct {
+	item    models.OrderItem
+	loaders *loaders
+}
+
+func (r *orderItemResolver) ID() graphql.ID    { return formatID(r.item.ID) }
+func (r *orderItemResolver) Quantity() int32   { return int32(r.item.Quantity) }
+func (r *orderItemResolver) Subtotal() float64 { return r.item.Subtotal }
+
+func (r *orderItemResolver) Product(ctx context.Context) (*productResolver, error) {
+	product, ok, err := r.loaders.products.Load(r.item.ProductID)
+	if err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return newProductResolver(product, r.loaders), nil
+}
+
+// Variant se resuelve a partir de las variantes del producto, que el loader ya trae cargadas
+func (r *orderItemResolver) Variant(ctx context.Context) (*variantResolver, error) {
+	if r.item.VariantID == nil {
+		return nil, nil
+	}
+
+	product, ok, err := r.loaders.products.Load(r.item.ProductID)
+	if err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	for _, variant := range product.Variants {
+		if variant.ID == *r.item.VariantID {
+			return &variantResolver{variant: variant, productPrice: product.Price}, nil
+		}
+	}
+	return nil, nil
+}
+
+type productPageResolver struct {
+	items    []*productResolver
+	total    int64
+	page     int
+	pageSize int
+}
+
+func (r *productPageResolver) Items() []*productResolver { return r.items }
+func (r *productPageResolver) Total() int32              { return int32(r.total) }
+func (r *productPageResolver) Page() int32               { return int32(r.page) }
+func (r *productPageResolver) PageSize() int32           { return int32(r.pageSize) }
+func (r *productPageResolver) TotalPages() int32         { return totalPages(r.total, r.pageSize) }
+
+type productResolver struct {
+	product models.Product
+	loaders *loaders
+}
+
+// newProductResolver registra la categoría del producto para buscarla junto con las demás
+func newProductResolver(product models.Product, l *loaders) *productResolver {
+	if product.CategoryID != nil {
+		l.categories.Register(*product.CategoryID)
+	}
+	return &productResolver{product: product, loaders: l}
+}
+
+func (r *productResolver) ID() graphql.ID { return formatID(r.product.ID) }
+func (r *productResolver) Name() string   { return r.product.Name }
+func (r *productResolver) Price() float64 { return r.product.Price }
+func (r *productResolver) Stock() int32   { return int32(r.product.Stock) }
+
+func (r *productResolver) Category(ctx context.Context) (*categoryResolver, error) {
+	if r.product.CategoryID == nil {
+		return nil, nil
+	}
+	return loadCategory(ctx, r.loaders, *r.product.CategoryID)
+}
+
+func (r *productResolver) Variants() []*variantResolver {
+	variants := make([]*variantResolver, len(r.product.Variants))
+	for i, variant := range r.product.Variants {
+		variants[i] = &variantResolver{variant: variant, productPrice: r.product.Price}
+	}
+	return variants
+}
+
+type variantResolver struct {
+	variant      models.ProductVariant
+	productPrice float64
+}
+
+func (r *variantResolver) ID() graphql.ID { return formatID(r.variant.ID) }
+func (r *variantResolver) SKU() string    { return r.variant.SKU }
+func (r *variantResolver) Price() float64 { return r.variant.EffectivePrice(r.productPrice) }
+func (r *variantResolver) Stock() int32   { return int32(r.variant.Stock) }
+
+// Attributes devuelve los atributos ordenados por nombre para que la respuesta sea estable
+func (r *variantResolver) Attributes() []*attributeResolver {
+	attributes := make([]*attributeResolver, 0, len(r.variant.Attributes))
+	for name, value := range r.variant.Attributes {
+		attributes = append(attributes, &attributeResolver{name: name, value: value})
+	}
+	sort.Slice(attributes, func(i, j int) bool { return attributes[i].name < attributes[j].name })
+	return attributes
+}
+
+type attributeResolver struct {
+	name  string
+	value string
+}
+
+func (r *attributeResolver) Name() string  { return r.name }
+func (r *attributeResolver) Value() string { return r.value }
+
+type categoryResolver struct {
+	category models.Category
+	loaders  *loaders
+}
+
+// loadCategory resuelve una categoría a través del loader y registra su padre
+func loadCategory(ctx context.Context, l *loaders, id uint) (*categoryResolver, error) {
+	category, ok, err := l.categories.Load(id)
+	if err != nil {
+		return nil, toGraphQLError(ctx, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	if category.ParentID != nil {
+		l.categories.Register(*category.ParentID)
+	}
+	return &categoryResolver{category: category, loaders: l}, nil
+}
+
+func (r *categoryResolver) ID() graphql.ID { return formatID(r.category.ID) }
+func (r *categoryResolver) Name() string   { return r.category.Name }
+
+func (r *categoryResolver) Parent(ctx context.Context) (*categoryResolver, error) {
+	if r.category.ParentID == nil {
+		return nil, nil
+	}
+	return loadCategory(ctx, r.loaders, *r.category.ParentID)
+}
@@ -0,0 +1,32 @@
+package grpcapi
+
+import (
+	"context"
+
+	"order_management/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// AuthorizationMetadata es la clave de metadata equivalente a la cabecera Authorization
+const AuthorizationMetadata = "authorization"
+
+// ClientAuthUnaryInterceptor identifica al cliente por la clave de la metadata authorization, igual
+// que ClientAuthMiddleware en la API REST, y deja su identificador en el contexto de la llamada
+func ClientAuthUnaryInterceptor(clients *auth.Clients) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if clientID, ok := clients.Identify(authorizationFromContext(ctx)); ok {
+			ctx = auth.WithClientID(ctx, clientID)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authorizationFromContext obtiene la metadata authorization entrante
+func authorizationFromContext(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, AuthorizationMetadata); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
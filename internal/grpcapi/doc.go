@@ -0,0 +1,9 @@
+// Package grpcapi expone los servicios de órdenes y productos por gRPC. Comparte los servicios,
+// los DTOs y las validaciones con la API REST; solo cambia el transporte.
+//
+// El código de internal/grpcapi/pb se genera a partir de proto/ordermanagement/v1 con:
+//
+//	protoc -I proto --go_out=. --go_opt=module=order_management \
+//		--go-grpc_out=. --go-grpc_opt=module=order_management \
+//		proto/ordermanagement/v1/orders.proto proto/ordermanagement/v1/products.proto
+package grpcapi
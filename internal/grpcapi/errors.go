@@ -0,0 +1,103 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"order_management/internal/apperrors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain identifica el origen de los errores en el detalle ErrorInfo
+const ErrorDomain = "order_management"
+
+// codeByKind asocia cada tipo de error de dominio con su código gRPC
+var codeByKind = map[apperrors.Kind]codes.Code{
+	apperrors.KindNotFound:      codes.NotFound,
+	apperrors.KindConflict:      codes.FailedPrecondition,
+	apperrors.KindUnprocessable: codes.FailedPrecondition,
+	apperrors.KindInvalid:       codes.InvalidArgument,
+	apperrors.KindInternal:      codes.Internal,
+}
+
+// codeByErrorCode sobrescribe el código gRPC de los errores que el cliente puede reintentar
+var codeByErrorCode = map[apperrors.Code]codes.Code{
+	apperrors.CodeTransactionConflict: codes.Aborted,
+}
+
+// toStatus traduce un error devuelto por los servicios a un status gRPC. El código de dominio y
+// sus detalles viajan en un ErrorInfo; la causa original nunca se expone al cliente.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	// La llamada venció o el cliente la canceló mientras se procesaba; los servicios pueden haber
+	// envuelto el error del contexto en un error de dominio interno
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, "la solicitud excedió el tiempo máximo de procesamiento")
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, "la solicitud se canceló")
+	}
+
+	appErr, ok := apperrors.As(err)
+	if !ok {
+		return status.Error(codes.Internal, "error interno del servidor")
+	}
+
+	code, ok := codeByErrorCode[appErr.Code]
+	if !ok {
+		code, ok = codeByKind[appErr.Kind]
+	}
+	if !ok {
+		code = codes.Internal
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   string(appErr.Code),
+		Domain:   ErrorDomain,
+		Metadata: make(map[string]string, len(appErr.Details)),
+	}
+	for key, value := range appErr.Details {
+		info.Metadata[key] = fmt.Sprint(value)
+	}
+
+	st, detailErr := status.New(code, appErr.Message).WithDetails(info)
+	if detailErr != nil {
+		return status.Error(code, appErr.Message)
+	}
+	return st.Err()
+}
+
+// ErrorUnaryInterceptor centraliza la traducción de errores de dominio de las llamadas unarias
+func ErrorUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		logIfInternal(ctx, info.FullMethod, err)
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+// ErrorStreamInterceptor centraliza la traducción de errores de dominio de las llamadas con streaming
+func ErrorStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := handler(srv, ss); err != nil {
+		logIfInternal(ss.Context(), info.FullMethod, err)
+		return toStatus(err)
+	}
+	return nil
+}
+
+// logIfInternal registra la causa de los errores inesperados, que no se envía al cliente
+func logIfInternal(ctx context.Context, method string, err error) {
+	if status.Code(toStatus(err)) == codes.Internal {
+		slog.ErrorContext(ctx, "Error interno en la llamada gRPC", "method", method, "error", err)
+	}
+}
@@ -0,0 +1,148 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/auth"
+	"order_management/internal/middlewares"
+	"order_management/internal/ports"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// IdempotencyKeyMetadata es la clave de metadata equivalente a la cabecera Idempotency-Key
+	IdempotencyKeyMetadata = "idempotency-key"
+	// idempotencyScopePrefix separa las claves de gRPC de las de la API REST, que guardan otro formato
+	idempotencyScopePrefix = "grpc:"
+)
+
+// IdempotentMethod define cómo se repite la respuesta de un método idempotente
+type IdempotentMethod struct {
+	// NewResponse crea el mensaje de respuesta del método para reconstruir la respuesta guardada
+	NewResponse func() proto.Message
+	// TTL es el tiempo durante el que se repite la respuesta; 0 usa el TTL del store
+	TTL time.Duration
+}
+
+// IdempotencyUnaryInterceptor aplica a gRPC el mismo esquema que IdempotencyMiddleware: la primera
+// llamada con una clave la reserva en el store y su respuesta se guarda; las siguientes con la misma
+// clave reciben la respuesta guardada. Las claves se guardan por cliente y junto con la huella del
+// método y del mensaje de la solicitud, por lo que reutilizar una clave con otra solicitud se
+// rechaza. Solo afecta a los métodos de methods.
+func IdempotencyUnaryInterceptor(store ports.IdempotencyStore, methods map[string]IdempotentMethod) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method, ok := methods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		idempotencyKey := idempotencyKeyFromContext(ctx)
+		// Si no tiene idempotency-key, continuar sin usar el store
+		if idempotencyKey == "" {
+			return handler(ctx, req)
+		}
+
+		// El error se traduce a InvalidArgument en ErrorUnaryInterceptor
+		if err := middlewares.ValidateIdempotencyKey(idempotencyKey); err != nil {
+			return nil, err
+		}
+
+		fingerprint, err := requestFingerprint(info.FullMethod, req)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "error al verificar la clave de idempotencia")
+		}
+		key := middlewares.ScopedIdempotencyKey(clientScope(ctx), info.FullMethod+":"+idempotencyKey)
+
+		// Reservar la clave de forma atómica: solo una llamada puede tomarla
+		lock, storedData, err := middlewares.AcquireIdempotencyKey(ctx, store, key, fingerprint, ports.IdempotencyOptions{Lease: middlewares.DefaultLockLease, TTL: method.TTL})
+		if err != nil {
+			// La reutilización de la clave con otra solicitud se traduce en ErrorUnaryInterceptor
+			if _, ok := apperrors.As(err); ok {
+				return nil, err
+			}
+			return nil, status.Error(codes.Internal, "error al verificar la clave de idempotencia")
+		}
+		if storedData != nil {
+			// Si la solicitud está en progreso, el cliente debe reintentar más tarde
+			if storedData.Status == ports.IdempotencyInProgress {
+				return nil, status.Error(codes.Aborted, "la petición está siendo procesada")
+			}
+
+			// Si la solicitud ya fue completada, devolver la respuesta almacenada
+			resp := method.NewResponse()
+			if err := protojson.Unmarshal(storedData.Response, resp); err != nil {
+				return nil, status.Error(codes.Internal, "error al recuperar la respuesta almacenada")
+			}
+			return resp, nil
+		}
+
+		// La reserva se renueva, completa y libera aunque el cliente cancele la llamada o venza su deadline
+		lockCtx := context.WithoutCancel(ctx)
+
+		// Renovar la reserva mientras el handler se ejecuta y cancelarlo si un reintento toma la clave
+		handlerCtx, cancelHandler := context.WithCancelCause(ctx)
+		defer cancelHandler(nil)
+		stopKeepAlive := lock.KeepAlive(lockCtx, func() { cancelHandler(ports.ErrIdempotencyLockLost) })
+		// El handler recibe la reserva para asociar la clave con lo que crea en su propia transacción
+		resp, err := handler(middlewares.WithIdempotencyLock(handlerCtx, lock), req)
+		stopKeepAlive()
+		if err != nil && errors.Is(context.Cause(handlerCtx), ports.ErrIdempotencyLockLost) {
+			// La clave pertenece ahora al reintento que la tomó
+			return nil, status.Error(codes.Aborted, "la petición está siendo procesada")
+		}
+		if err != nil {
+			// Liberar la clave para que el cliente pueda reintentar
+			lock.Release(lockCtx)
+			return nil, err
+		}
+
+		// Guardar la respuesta en el store
+		message, _ := resp.(proto.Message)
+		responseJSON, err := protojson.Marshal(message)
+		if err == nil {
+			lock.Complete(lockCtx, ports.IdempotencyRecord{Fingerprint: fingerprint, Response: responseJSON})
+		} else {
+			lock.Release(lockCtx)
+		}
+
+		return resp, nil
+	}
+}
+
+// idempotencyKeyFromContext obtiene la clave de idempotencia de la metadata entrante
+func idempotencyKeyFromContext(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, IdempotencyKeyMetadata); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// clientScope devuelve el ámbito de las claves del cliente de la llamada, con el mismo criterio que
+// ClientScope en la API REST y separado de sus ámbitos
+func clientScope(ctx context.Context) string {
+	return idempotencyScopePrefix + middlewares.ScopeFor(auth.ClientIDFromContext(ctx), authorizationFromContext(ctx))
+}
+
+// requestFingerprint calcula la huella de la llamada a partir del método y del mensaje serializado de
+// forma determinista
+func requestFingerprint(fullMethod string, req interface{}) (string, error) {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return "", fmt.Errorf("la solicitud de %s no es un mensaje protobuf", fullMethod)
+	}
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	return middlewares.RequestFingerprint("GRPC", fullMethod, body), nil
+}
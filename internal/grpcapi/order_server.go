@@ -0,0 +1,56 @@
+package grpcapi
+
+import (
+	"context"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/grpcapi/pb"
+	"order_management/internal/mappers"
+	"order_management/internal/middlewares"
+	"order_management/internal/ports"
+	"order_management/internal/validators"
+)
+
+// OrderServer implementa pb.OrderServiceServer sobre ports.OrderService
+type OrderServer struct {
+	pb.UnimplementedOrderServiceServer
+	orderService ports.OrderService
+	validator    *validators.CustomValidator
+}
+
+// NewOrderServer crea el servidor gRPC de órdenes
+func NewOrderServer(orderService ports.OrderService) *OrderServer {
+	return &OrderServer{orderService: orderService, validator: validators.NewValidator()}
+}
+
+// CreateOrder valida la solicitud con las mismas reglas que la API REST y crea la orden
+func (s *OrderServer) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
+	orderRequest := mappers.ConvertCreateOrderRequestToOrderRequestDTO(req)
+	if err := s.validator.Validate(orderRequest); err != nil {
+		return nil, apperrors.Invalid(apperrors.CodeInvalidRequest, err.Error())
+	}
+
+	order := mappers.ConvertOrderRequestDTOToOrder(orderRequest)
+
+	// Con idempotency-key la clave se asocia con la orden en la misma transacción si el store lo permite.
+	// Los errores de dominio se traducen a su código gRPC en ErrorUnaryInterceptor
+	if err := s.orderService.CreateOrderIdempotent(ctx, &order, middlewares.IdempotencyLockFromRequest(ctx)); err != nil {
+		return nil, err
+	}
+
+	return mappers.ConvertOrderToPB(order), nil
+}
+
+// GetOrder obtiene una orden por su ID
+func (s *OrderServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+	if req.GetId() == 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInvalidRequest, "ID inválido")
+	}
+
+	order, err := s.orderService.GetOrderById(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+
+	return mappers.ConvertOrderToPB(*order), nil
+}
@@ -0,0 +1,96 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/grpcapi/pb"
+	"order_management/internal/mappers"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/internal/validators"
+)
+
+// ProductServer implementa pb.ProductServiceServer sobre ports.ProductService
+type ProductServer struct {
+	pb.UnimplementedProductServiceServer
+	productService ports.ProductService
+	validator      *validators.CustomValidator
+}
+
+// NewProductServer crea el servidor gRPC de productos
+func NewProductServer(productService ports.ProductService) *ProductServer {
+	return &ProductServer{productService: productService, validator: validators.NewValidator()}
+}
+
+// SearchProducts busca, filtra y pagina el catálogo con los mismos parámetros que la API REST
+func (s *ProductServer) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
+	queryDTO := mappers.ConvertSearchProductsRequestToQueryDTO(req)
+	if err := s.validator.Validate(queryDTO); err != nil {
+		return nil, apperrors.Invalid(apperrors.CodeInvalidRequest, err.Error())
+	}
+
+	filter := mappers.ConvertProductListQueryDTOToFilter(queryDTO)
+
+	products, total, err := s.productService.SearchProducts(ctx, filter)
+	if err != nil {
+		return nil, apperrors.Internal(apperrors.CodeInternal, "error al obtener productos", err)
+	}
+
+	return mappers.ConvertProductPageToPB(products, total, filter), nil
+}
+
+// UpdateStock actualiza el stock de un producto
+func (s *ProductServer) UpdateStock(ctx context.Context, req *pb.UpdateStockRequest) (*pb.UpdateStockResponse, error) {
+	if req.GetProductId() == 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInvalidRequest, "ID inválido")
+	}
+
+	if err := s.productService.UpdateStock(ctx, uint(req.GetProductId()), int(req.GetStock())); err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateStockResponse{}, nil
+}
+
+// UpdateVariantStock actualiza el stock de una variante de producto
+func (s *ProductServer) UpdateVariantStock(ctx context.Context, req *pb.UpdateVariantStockRequest) (*pb.UpdateStockResponse, error) {
+	if req.GetProductId() == 0 || req.GetVariantId() == 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInvalidRequest, "ID inválido")
+	}
+
+	if err := s.productService.UpdateVariantStock(ctx, uint(req.GetProductId()), uint(req.GetVariantId()), int(req.GetStock())); err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateStockResponse{}, nil
+}
+
+// ImportProducts importa productos y variantes desde un CSV. Las filas rechazadas se informan en
+// la respuesta, igual que en la API REST, y en ese caso no se aplica ningún cambio.
+func (s *ProductServer) ImportProducts(ctx context.Context, req *pb.ImportProductsRequest) (*pb.ImportProductsResponse, error) {
+	rows, err := mappers.ParseProductImportCSV(bytes.NewReader(req.GetCsv()))
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.productService.ImportProducts(ctx, rows, req.GetDryRun())
+	if err != nil {
+		return nil, err
+	}
+
+	return mappers.ConvertProductImportReportToPB(*report, req.GetDryRun()), nil
+}
+
+// ExportProducts envía el catálogo completo, producto por producto, a medida que se leen los lotes
+func (s *ProductServer) ExportProducts(req *pb.ExportProductsRequest, stream pb.ProductService_ExportProductsServer) error {
+	return s.productService.ExportProducts(stream.Context(), func(products []models.Product) error {
+		for _, product := range products {
+			if err := stream.Send(mappers.ConvertProductToPB(product)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
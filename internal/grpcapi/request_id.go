@@ -0,0 +1,48 @@
+package grpcapi
+
+import (
+	"context"
+
+	"order_management/internal/logging"
+	"order_management/internal/middlewares"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadata es la clave de metadata equivalente a la cabecera X-Request-ID
+const RequestIDMetadata = "x-request-id"
+
+// requestIDContext reutiliza el ID de correlación de la metadata entrante, o genera uno, lo devuelve
+// al cliente en la cabecera de la respuesta y lo guarda en el contexto de la llamada
+func requestIDContext(ctx context.Context) context.Context {
+	var id string
+	if values := metadata.ValueFromIncomingContext(ctx, RequestIDMetadata); len(values) > 0 {
+		id = values[0]
+	}
+	id = middlewares.RequestIDOrNew(id)
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id))
+	return logging.WithRequestID(ctx, id)
+}
+
+// RequestIDUnaryInterceptor asigna a cada llamada unaria un ID de correlación, igual que
+// RequestIDMiddleware en la API REST
+func RequestIDUnaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(requestIDContext(ctx), req)
+}
+
+// RequestIDStreamInterceptor asigna a cada llamada con streaming un ID de correlación
+func RequestIDStreamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: ss, ctx: requestIDContext(ss.Context())})
+}
+
+// contextStream reemplaza el contexto de un ServerStream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
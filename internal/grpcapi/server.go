@@ -0,0 +1,52 @@
+package grpcapi
+
+import (
+	"order_management/internal/auth"
+	"order_management/internal/grpcapi/pb"
+	"order_management/internal/handlers"
+	"order_management/internal/ports"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// idempotentMethods lista los métodos que aceptan la metadata idempotency-key: todos los que modifican
+// datos, con el mismo TTL que su ruta equivalente de la API REST
+var idempotentMethods = map[string]IdempotentMethod{
+	pb.OrderService_CreateOrder_FullMethodName: {
+		NewResponse: func() proto.Message { return &pb.Order{} },
+		TTL:         handlers.OrderIdempotencyTTL,
+	},
+	pb.ProductService_UpdateStock_FullMethodName: {
+		NewResponse: func() proto.Message { return &pb.UpdateStockResponse{} },
+		TTL:         handlers.StockIdempotencyTTL,
+	},
+	pb.ProductService_UpdateVariantStock_FullMethodName: {
+		NewResponse: func() proto.Message { return &pb.UpdateStockResponse{} },
+		TTL:         handlers.StockIdempotencyTTL,
+	},
+	pb.ProductService_ImportProducts_FullMethodName: {
+		NewResponse: func() proto.Message { return &pb.ImportProductsResponse{} },
+		TTL:         handlers.ImportIdempotencyTTL,
+	},
+}
+
+// NewServer crea el servidor gRPC con los servicios de órdenes y productos registrados. Los clientes
+// se identifican con clients para separar sus claves de idempotencia.
+func NewServer(orderService ports.OrderService, productService ports.ProductService, idempotencyStore ports.IdempotencyStore, clients *auth.Clients) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			RequestIDUnaryInterceptor,
+			TracingUnaryInterceptor,
+			ErrorUnaryInterceptor,
+			ClientAuthUnaryInterceptor(clients),
+			IdempotencyUnaryInterceptor(idempotencyStore, idempotentMethods),
+		),
+		grpc.ChainStreamInterceptor(RequestIDStreamInterceptor, TracingStreamInterceptor, ErrorStreamInterceptor),
+	)
+
+	pb.RegisterOrderServiceServer(server, NewOrderServer(orderService))
+	pb.RegisterProductServiceServer(server, NewProductServer(productService))
+
+	return server
+}
@@ -0,0 +1,476 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/auth"
+	"order_management/internal/grpcapi/pb"
+	"order_management/internal/idempotency"
+	"order_management/internal/logging"
+	"order_management/internal/middlewares"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/internal/tracing"
+	"order_management/test/mocks"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testClientKeys son las claves de los clientes del servidor de prueba; tienda tiene dos para simular
+// una rotación
+const testClientKeys = "tienda:clave-nueva,tienda:clave-anterior"
+
+// newTestClient levanta el servidor gRPC sobre un listener en memoria y devuelve una conexión a él
+func newTestClient(t *testing.T, orderService ports.OrderService, productService ports.ProductService) *grpc.ClientConn {
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	clients, err := auth.ParseClients(testClientKeys)
+	assert.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(orderService, productService, idempotency.NewRedisStore(redisClient, idempotency.DefaultTTL), clients)
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+		redisClient.Close()
+	})
+	return conn
+}
+
+func TestGRPCCreateOrder_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+		order.ID = 7
+		order.TotalAmount = 1000
+		order.OrderItems[0].Subtotal = 1000
+		order.OrderItems[0].Product = models.Product{ID: 1, Name: "Laptop"}
+		return nil
+	}).Times(1)
+
+	variantID := uint64(3)
+	resp, err := client.CreateOrder(context.Background(), &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, VariantId: &variantID, Quantity: 2}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), resp.GetId())
+	assert.Equal(t, float64(1000), resp.GetTotalAmount())
+	assert.Equal(t, "Laptop", resp.GetItems()[0].GetProductName())
+	assert.Equal(t, variantID, resp.GetItems()[0].GetVariantId())
+}
+
+func TestGRPCCreateOrder_InvalidRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	// La validación es la misma que en la API REST: falta customer_name y quantity debe ser mayor a 0
+	_, err := client.CreateOrder(context.Background(), &pb.CreateOrderRequest{
+		Items: []*pb.OrderItemRequest{{ProductId: 1, Quantity: 0}},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// TestGRPCCreateOrder_PropagatesRequestID verifica que el servicio reciba el ID de correlación de la
+// metadata y que se devuelva al cliente en la cabecera de la respuesta
+func TestGRPCCreateOrder_PropagatesRequestID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	var requestID string
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+		requestID = logging.RequestIDFromContext(ctx)
+		return nil
+	}).Times(1)
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDMetadata, "req-42")
+	_, err := client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 1}},
+	}, grpc.Header(&header))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "req-42", requestID)
+	assert.Equal(t, []string{"req-42"}, header.Get(RequestIDMetadata))
+}
+
+func TestGRPCCreateOrder_ContinuesClientTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(tracing.Propagator)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	var serviceSpan trace.SpanContext
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+		serviceSpan = trace.SpanContextFromContext(ctx)
+		return apperrors.Internal(apperrors.CodeInternal, "error interno", io.ErrUnexpectedEOF)
+	}).Times(1)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err := client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 1}},
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, pb.OrderService_CreateOrder_FullMethodName, span.Name())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+		assert.Equal(t, span.SpanContext().SpanID(), serviceSpan.SpanID())
+		assert.Equal(t, otelcodes.Error, span.Status().Code)
+	}
+}
+
+func TestGRPCCreateOrder_MapsDomainErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(apperrors.InsufficientStock(1, nil, 5, 2)).Times(1)
+
+	_, err := client.CreateOrder(context.Background(), &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 5}},
+	})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		info := st.Details()[0].(*errdetails.ErrorInfo)
+		assert.Equal(t, string(apperrors.CodeInsufficientStock), info.GetReason())
+		assert.Equal(t, "2", info.GetMetadata()["available"])
+	}
+}
+
+func TestGRPCCreateOrder_IdempotencyKeyReplaysResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	// El servicio se invoca una sola vez aunque la llamada se repita con la misma clave
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+		order.ID = 7
+		return nil
+	}).Times(1)
+
+	req := &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 1}},
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyMetadata, "order-123")
+
+	first, err := client.CreateOrder(ctx, req)
+	assert.NoError(t, err)
+
+	second, err := client.CreateOrder(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, first.GetId(), second.GetId())
+	assert.Equal(t, first.GetCustomerName(), second.GetCustomerName())
+}
+
+func TestGRPCCreateOrder_PassesIdempotencyLockToService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	// Con idempotency-key el servicio recibe la reserva para asociarla con la orden en su transacción
+	var locks []ports.IdempotencyLock
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order *models.Order, lock ports.IdempotencyLock) error {
+		locks = append(locks, lock)
+		order.ID = uint(len(locks))
+		return nil
+	}).Times(2)
+
+	req := &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 1}},
+	}
+	_, err := client.CreateOrder(metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyMetadata, "order-123"), req)
+	assert.NoError(t, err)
+	_, err = client.CreateOrder(context.Background(), req)
+	assert.NoError(t, err)
+
+	if assert.Len(t, locks, 2) {
+		assert.NotNil(t, locks[0])
+		assert.Nil(t, locks[1])
+	}
+}
+
+func TestGRPCCreateOrder_IdempotencyKeyRejectsDifferentRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+		order.ID = 7
+		return nil
+	}).Times(1)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyMetadata, "order-123")
+	_, err := client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 1}},
+	})
+	assert.NoError(t, err)
+
+	// La misma clave con otra solicitud no repite la orden anterior
+	_, err = client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 2}},
+	})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		assert.Equal(t, string(apperrors.CodeIdempotencyMismatch), st.Details()[0].(*errdetails.ErrorInfo).GetReason())
+	}
+}
+
+func TestGRPCCreateOrder_RejectsLongIdempotencyKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// El servicio no se llama si la clave es demasiado larga
+	client := pb.NewOrderServiceClient(newTestClient(t, mocks.NewMockOrderService(ctrl), nil))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyMetadata, strings.Repeat("k", middlewares.MaxIdempotencyKeyLength+1))
+	_, err := client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 1}},
+	})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		assert.Equal(t, string(apperrors.CodeIdempotencyKeyTooLong), st.Details()[0].(*errdetails.ErrorInfo).GetReason())
+	}
+}
+
+func TestGRPCCreateOrder_IdempotencyKeyScopedByClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	// Una orden para el cliente tienda y otra para la llamada anónima con la misma clave
+	var created uint32
+	mockOrderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+		created++
+		order.ID = uint(created)
+		return nil
+	}).Times(2)
+
+	req := &pb.CreateOrderRequest{
+		CustomerName: "Ana",
+		Items:        []*pb.OrderItemRequest{{ProductId: 1, Quantity: 1}},
+	}
+	withKey := func(authorization string) context.Context {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyMetadata, "order-123")
+		if authorization != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, AuthorizationMetadata, authorization)
+		}
+		return ctx
+	}
+
+	first, err := client.CreateOrder(withKey("Bearer clave-anterior"), req)
+	assert.NoError(t, err)
+
+	// Rotar la clave del cliente no cambia el ámbito de sus claves de idempotencia
+	replayed, err := client.CreateOrder(withKey("Bearer clave-nueva"), req)
+	assert.NoError(t, err)
+	assert.Equal(t, first.GetId(), replayed.GetId())
+
+	anonymous, err := client.CreateOrder(withKey(""), req)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.GetId(), anonymous.GetId())
+}
+
+func TestGRPCGetOrder_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderService(ctrl)
+	client := pb.NewOrderServiceClient(newTestClient(t, mockOrderService, nil))
+
+	mockOrderService.EXPECT().GetOrderById(gomock.Any(), uint(99)).
+		Return(nil, apperrors.NotFound(apperrors.CodeOrderNotFound, "Order not found")).Times(1)
+
+	_, err := client.GetOrder(context.Background(), &pb.GetOrderRequest{Id: 99})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "Order not found", status.Convert(err).Message())
+}
+
+func TestGRPCSearchProducts_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProductService(ctrl)
+	client := pb.NewProductServiceClient(newTestClient(t, nil, mockProductService))
+
+	expectedFilter := ports.ProductFilter{Query: "lap", SortBy: "price", SortDesc: true, Page: 1, PageSize: 20}
+	products := []models.Product{{ID: 1, Name: "Laptop", Price: 500, Stock: 10}}
+	mockProductService.EXPECT().SearchProducts(gomock.Any(), expectedFilter).Return(products, int64(1), nil).Times(1)
+
+	resp, err := client.SearchProducts(context.Background(), &pb.SearchProductsRequest{Query: "lap", Sort: "-price"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), resp.GetTotal())
+	assert.Equal(t, int32(1), resp.GetTotalPages())
+	assert.Equal(t, "Laptop", resp.GetItems()[0].GetName())
+}
+
+func TestGRPCUpdateVariantStock_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProductService(ctrl)
+	client := pb.NewProductServiceClient(newTestClient(t, nil, mockProductService))
+
+	mockProductService.EXPECT().UpdateVariantStock(gomock.Any(), uint(1), uint(2), 5).
+		Return(apperrors.NotFound(apperrors.CodeVariantNotFound, "variante no encontrada")).Times(1)
+
+	_, err := client.UpdateVariantStock(context.Background(), &pb.UpdateVariantStockRequest{ProductId: 1, VariantId: 2, Stock: 5})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCProductMutations_IdempotencyKeyReplaysResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProductService(ctrl)
+	client := pb.NewProductServiceClient(newTestClient(t, nil, mockProductService))
+
+	// Cada operación se aplica una sola vez aunque la llamada se repita con la misma clave
+	mockProductService.EXPECT().UpdateStock(gomock.Any(), uint(1), 5).Return(nil).Times(1)
+	mockProductService.EXPECT().UpdateVariantStock(gomock.Any(), uint(1), uint(2), 5).Return(nil).Times(1)
+	mockProductService.EXPECT().ImportProducts(gomock.Any(), gomock.Any(), false).Return(&ports.ProductImportReport{
+		Results: []ports.ProductImportResult{{Line: 2, Action: ports.ImportActionCreate, ProductID: 1}},
+		Applied: true,
+	}, nil).Times(1)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyMetadata, "mutation-1")
+	csv := []byte("name,price,stock\nLaptop,100,5\n")
+	for i := 0; i < 2; i++ {
+		_, err := client.UpdateStock(ctx, &pb.UpdateStockRequest{ProductId: 1, Stock: 5})
+		assert.NoError(t, err)
+		_, err = client.UpdateVariantStock(ctx, &pb.UpdateVariantStockRequest{ProductId: 1, VariantId: 2, Stock: 5})
+		assert.NoError(t, err)
+		resp, err := client.ImportProducts(ctx, &pb.ImportProductsRequest{Csv: csv})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), resp.GetCreated())
+	}
+}
+
+// TestIdempotentMethods_CoverEveryMutation verifica que todo método que modifica datos acepte idempotency-key
+func TestIdempotentMethods_CoverEveryMutation(t *testing.T) {
+	readOnly := map[string]bool{
+		pb.OrderService_GetOrder_FullMethodName:         true,
+		pb.ProductService_SearchProducts_FullMethodName: true,
+	}
+	for _, desc := range []grpc.ServiceDesc{pb.OrderService_ServiceDesc, pb.ProductService_ServiceDesc} {
+		for _, method := range desc.Methods {
+			fullMethod := "/" + desc.ServiceName + "/" + method.MethodName
+			_, ok := idempotentMethods[fullMethod]
+			assert.Equal(t, !readOnly[fullMethod], ok, fullMethod)
+		}
+	}
+}
+
+func TestGRPCExportProducts_StreamsAllBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductService := mocks.NewMockProductService(ctrl)
+	client := pb.NewProductServiceClient(newTestClient(t, nil, mockProductService))
+
+	mockProductService.EXPECT().ExportProducts(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func([]models.Product) error) error {
+		if err := fn([]models.Product{{ID: 1, Name: "Laptop"}, {ID: 2, Name: "Mouse"}}); err != nil {
+			return err
+		}
+		return fn([]models.Product{{ID: 3, Name: "Teclado"}})
+	}).Times(1)
+
+	stream, err := client.ExportProducts(context.Background(), &pb.ExportProductsRequest{})
+	assert.NoError(t, err)
+
+	var names []string
+	for {
+		product, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			break
+		}
+		names = append(names, product.GetName())
+	}
+	assert.Equal(t, []string{"Laptop", "Mouse", "Teclado"}, names)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/mappers"
+	"order_management/internal/middlewares"
+	"order_management/internal/ports"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	AdminPrefix = "/admin"
+)
+
+// AdminHandler expone operaciones de soporte que no forman parte de la API pública
+type AdminHandler struct {
+	idempotencyStore ports.IdempotencyStore
+}
+
+// NewAdminHandler registra los endpoints de administración en Echo, protegidos con
+// "Authorization: Bearer <token>". Las claves se identifican por el ámbito del cliente y la
+// Idempotency-Key que envió; las de gRPC usan el ámbito "grpc:<ámbito del cliente>" y la clave
+// "/<método>:<clave>".
+func NewAdminHandler(e *echo.Echo, idempotencyStore ports.IdempotencyStore, token string) {
+	handler := &AdminHandler{idempotencyStore: idempotencyStore}
+
+	admin := e.Group(AdminPrefix, middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+		return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
+	}))
+	admin.GET("/idempotency-keys/:scope/*", handler.GetIdempotencyKey, deadline(ReadTimeout))
+	admin.DELETE("/idempotency-keys/:scope/*", handler.DeleteIdempotencyKey, deadline(WriteTimeout))
+}
+
+// GetIdempotencyKey devuelve el estado de una clave de idempotencia y la respuesta que se repite
+func (h *AdminHandler) GetIdempotencyKey(c echo.Context) error {
+	scope, key := c.Param("scope"), c.Param("*")
+
+	record, err := h.idempotencyStore.Get(c.Request().Context(), middlewares.ScopedIdempotencyKey(scope, key))
+	if err != nil {
+		return apperrors.Internal(apperrors.CodeInternal, "error al verificar la clave de idempotencia", err)
+	}
+	if record == nil {
+		return apperrors.NotFound(apperrors.CodeIdempotencyKeyNotFound, "clave de idempotencia no encontrada")
+	}
+
+	return c.JSON(http.StatusOK, mappers.ConvertIdempotencyRecordToResponseDTO(scope, key, *record))
+}
+
+// DeleteIdempotencyKey elimina una clave de idempotencia para que el cliente pueda repetir la
+// operación, por ejemplo cuando una solicitud quedó bloqueada o se guardó una respuesta incorrecta
+func (h *AdminHandler) DeleteIdempotencyKey(c echo.Context) error {
+	scope, key := c.Param("scope"), c.Param("*")
+
+	deleted, err := h.idempotencyStore.Delete(c.Request().Context(), middlewares.ScopedIdempotencyKey(scope, key))
+	if err != nil {
+		return apperrors.Internal(apperrors.CodeInternal, "error al eliminar la clave de idempotencia", err)
+	}
+	if !deleted {
+		return apperrors.NotFound(apperrors.CodeIdempotencyKeyNotFound, "clave de idempotencia no encontrada")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
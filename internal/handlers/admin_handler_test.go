@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"order_management/internal/dtos"
+	"order_management/internal/ports"
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAdminToken = "admin-secret"
+
+// newAdminServer registra los endpoints de administración sobre el store indicado
+func newAdminServer(store ports.IdempotencyStore) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	NewAdminHandler(e, store, testAdminToken)
+	return e
+}
+
+func adminRequest(e *echo.Echo, method, target, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminHandler_RejectsMissingOrWrongToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// El store no se consulta si el token no es válido
+	e := newAdminServer(mocks.NewMockIdempotencyStore(ctrl))
+
+	// KeyAuth responde 400 si falta la cabecera y 401 si el token no coincide
+	for _, tc := range []struct {
+		name, authorization string
+		status              int
+	}{
+		{"sin token", "", http.StatusBadRequest},
+		{"esquema erróneo", "Basic " + testAdminToken, http.StatusBadRequest},
+		{"token inválido", "Bearer otro-token", http.StatusUnauthorized},
+	} {
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			rec := adminRequest(e, method, "/admin/idempotency-keys/anonymous/key-1", tc.authorization)
+
+			assert.Equal(t, tc.status, rec.Code, tc.name)
+		}
+	}
+}
+
+func TestAdminHandler_GetParsesScopeAndKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockIdempotencyStore(ctrl)
+	e := newAdminServer(store)
+
+	for _, tc := range []struct {
+		path, storeKey, scope, key string
+	}{
+		{"/admin/idempotency-keys/anonymous/mi-clave", "anonymous:mi-clave", "anonymous", "mi-clave"},
+		{"/admin/idempotency-keys/cliente-a/pedido:1", "cliente-a:pedido:1", "cliente-a", "pedido:1"},
+		// Las claves de gRPC incluyen el método completo, con barras
+		{
+			"/admin/idempotency-keys/grpc:cliente-a//order_management.OrderService/CreateOrder:key-1",
+			"grpc:cliente-a:/order_management.OrderService/CreateOrder:key-1",
+			"grpc:cliente-a",
+			"/order_management.OrderService/CreateOrder:key-1",
+		},
+	} {
+		store.EXPECT().Get(gomock.Any(), tc.storeKey).Return(&ports.IdempotencyRecord{Status: ports.IdempotencyCompleted, StatusCode: http.StatusCreated}, nil).Times(1)
+
+		rec := adminRequest(e, http.MethodGet, tc.path, "Bearer "+testAdminToken)
+
+		assert.Equal(t, http.StatusOK, rec.Code, tc.path)
+		var response dtos.IdempotencyKeyResponseDTO
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, tc.scope, response.Scope)
+		assert.Equal(t, tc.key, response.Key)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+	}
+}
+
+func TestAdminHandler_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockIdempotencyStore(ctrl)
+	e := newAdminServer(store)
+
+	store.EXPECT().Get(gomock.Any(), "anonymous:key-1").Return(nil, nil).Times(1)
+	store.EXPECT().Delete(gomock.Any(), "anonymous:key-1").Return(false, nil).Times(1)
+
+	get := adminRequest(e, http.MethodGet, "/admin/idempotency-keys/anonymous/key-1", "Bearer "+testAdminToken)
+	del := adminRequest(e, http.MethodDelete, "/admin/idempotency-keys/anonymous/key-1", "Bearer "+testAdminToken)
+
+	assert.Equal(t, http.StatusNotFound, get.Code)
+	assert.Equal(t, http.StatusNotFound, del.Code)
+}
+
+func TestAdminHandler_DeleteRemovesKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockIdempotencyStore(ctrl)
+	e := newAdminServer(store)
+
+	store.EXPECT().Delete(gomock.Any(), "cliente-a:/order_management.ProductService/UpdateStock:key-1").Return(true, nil).Times(1)
+
+	rec := adminRequest(e, http.MethodDelete, "/admin/idempotency-keys/cliente-a//order_management.ProductService/UpdateStock:key-1", "Bearer "+testAdminToken)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
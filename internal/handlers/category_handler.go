@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/dtos"
+	"order_management/internal/mappers"
+	"order_management/internal/ports"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CategoryHandler maneja las solicitudes HTTP relacionadas con categorías
+type CategoryHandler struct {
+	categoryService ports.CategoryService
+}
+
+// NewCategoryHandler registra los endpoints de categorías en Echo
+func NewCategoryHandler(apiGroup *echo.Group, categoryService ports.CategoryService, idempotencyStore ports.IdempotencyStore) {
+	handler := &CategoryHandler{categoryService: categoryService}
+
+	apiGroup.GET("/categories", handler.GetCategoryTree, deadline(ReadTimeout))
+	apiGroup.POST("/categories", handler.CreateCategory, deadline(WriteTimeout), idempotent(idempotencyStore, CategoryIdempotencyTTL))
+}
+
+// GetCategoryTree maneja la solicitud para obtener el árbol de categorías
+func (h *CategoryHandler) GetCategoryTree(c echo.Context) error {
+	categories, err := h.categoryService.GetCategoryTree(c.Request().Context())
+	if err != nil {
+		return apperrors.Internal(apperrors.CodeInternal, "error al obtener categorías", err)
+	}
+
+	return c.JSON(http.StatusOK, mappers.ConvertCategoriesToCategoryResponseDTO(categories))
+}
+
+// CreateCategory maneja la creación de una nueva categoría
+func (h *CategoryHandler) CreateCategory(c echo.Context) error {
+	var categoryRequest dtos.CategoryRequestDTO
+	if err := c.Bind(&categoryRequest); err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
+	}
+
+	if err := c.Validate(categoryRequest); err != nil {
+		return err
+	}
+
+	category := mappers.ConvertCategoryRequestDTOToCategory(categoryRequest)
+
+	if err := h.categoryService.CreateCategory(c.Request().Context(), &category); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, mappers.ConvertCategoryToCategoryResponseDTO(category))
+}
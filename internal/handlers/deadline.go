@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"time"
+
+	"order_management/internal/middlewares"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Tiempo máximo de procesamiento de cada ruta de la API. Al vencer se cancelan las consultas y la
+// transacción en curso y se responde 504.
+const (
+	ReadTimeout  = 5 * time.Second
+	WriteTimeout = 10 * time.Second
+	// Un lote crea varias órdenes, cada una con sus propios reintentos por contención
+	BatchTimeout = 30 * time.Second
+	// Un documento GraphQL puede incluir mutaciones; se usa el tiempo máximo de las escrituras
+	GraphQLTimeout = WriteTimeout
+	// Las importaciones y exportaciones recorren el catálogo completo
+	ImportTimeout = 2 * time.Minute
+	ExportTimeout = 5 * time.Minute
+)
+
+// deadline es la opción de tiempo máximo de una ruta. Se declara antes que idempotent para que la
+// reserva de la clave también quede dentro del tiempo máximo.
+func deadline(timeout time.Duration) echo.MiddlewareFunc {
+	return middlewares.DeadlineMiddleware(timeout)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+)
+
+// swaggerUIPage carga Swagger UI apuntando al documento servido en /openapi.json
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="es">
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>`
+
+// DocsHandler expone la especificación OpenAPI y su interfaz interactiva
+type DocsHandler struct {
+	doc *openapi3.T
+}
+
+// NewDocsHandler registra los endpoints de documentación en Echo
+func NewDocsHandler(e *echo.Echo, doc *openapi3.T) {
+	handler := &DocsHandler{doc: doc}
+
+	e.GET("/openapi.json", handler.GetSpec)
+	e.GET("/docs", handler.GetSwaggerUI)
+}
+
+// GetSpec devuelve el documento OpenAPI en JSON
+func (h *DocsHandler) GetSpec(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.doc)
+}
+
+// GetSwaggerUI devuelve la página de Swagger UI
+func (h *DocsHandler) GetSwaggerUI(c echo.Context) error {
+	return c.HTML(http.StatusOK, fmt.Sprintf(swaggerUIPage, h.doc.Info.Title))
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/dtos"
+	"order_management/internal/i18n"
+	"order_management/internal/validators"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StatusClientClosedRequest es el código, por convención de nginx, de las solicitudes que el cliente
+// abandonó antes de recibir la respuesta. Solo queda en el log de accesos y en las métricas.
+const StatusClientClosedRequest = 499
+
+// statusByKind asocia cada tipo de error de dominio con su código HTTP
+var statusByKind = map[apperrors.Kind]int{
+	apperrors.KindNotFound:      http.StatusNotFound,
+	apperrors.KindConflict:      http.StatusConflict,
+	apperrors.KindUnprocessable: http.StatusUnprocessableEntity,
+	apperrors.KindInvalid:       http.StatusBadRequest,
+	apperrors.KindInternal:      http.StatusInternalServerError,
+}
+
+// HTTPErrorHandler traduce los errores devueltos por los handlers a una respuesta JSON uniforme,
+// con el mensaje en el idioma negociado con el cliente. Se registra como e.HTTPErrorHandler para
+// centralizar el mapeo de errores de dominio a códigos HTTP.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, body := errorResponse(err, i18n.LanguageFromContext(c.Request().Context()))
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request().Context(), "Error interno en la solicitud",
+			"method", c.Request().Method, "path", c.Request().URL.Path, "error", err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, body)
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Error al enviar la respuesta de error", "error", err)
+	}
+}
+
+// errorResponse obtiene el código HTTP y el cuerpo de la respuesta para un error en el idioma indicado
+func errorResponse(err error, lang string) (int, dtos.ErrorResponseDTO) {
+	// Errores de validación de los DTOs: un error traducido por cada campo
+	var validationErr *validators.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, dtos.ErrorResponseDTO{
+			Error:  i18n.Translate(lang, "la solicitud contiene campos inválidos"),
+			Code:   string(apperrors.CodeValidationFailed),
+			Fields: validationErr.Fields(lang),
+		}
+	}
+
+	// La solicitud venció o el cliente se desconectó mientras se procesaba; los servicios pueden
+	// haber envuelto el error del contexto en un error de dominio interno
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, dtos.ErrorResponseDTO{
+			Error: i18n.Translate(lang, "la solicitud excedió el tiempo máximo de procesamiento"),
+			Code:  string(apperrors.CodeRequestTimeout),
+		}
+	}
+	if errors.Is(err, context.Canceled) {
+		return StatusClientClosedRequest, dtos.ErrorResponseDTO{
+			Error: i18n.Translate(lang, "la solicitud se canceló"),
+			Code:  string(apperrors.CodeRequestCanceled),
+		}
+	}
+
+	if appErr, ok := apperrors.As(err); ok {
+		status, ok := statusByKind[appErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		return status, dtos.ErrorResponseDTO{
+			Error:   appErr.Localize(lang),
+			Code:    string(appErr.Code),
+			Details: appErr.Details,
+		}
+	}
+
+	// Errores propios de Echo (ruta inexistente, método no permitido, cuerpo demasiado grande, etc.)
+	if he, ok := err.(*echo.HTTPError); ok {
+		return he.Code, dtos.ErrorResponseDTO{
+			Error: fmt.Sprint(he.Message),
+			Code:  strings.ToUpper(strings.ReplaceAll(http.StatusText(he.Code), " ", "_")),
+		}
+	}
+
+	return http.StatusInternalServerError, dtos.ErrorResponseDTO{
+		Error: i18n.Translate(lang, "error interno del servidor"),
+		Code:  string(apperrors.CodeInternal),
+	}
+}
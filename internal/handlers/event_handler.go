@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/dtos"
+	"order_management/internal/mappers"
+	"order_management/internal/ports"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	EventsPath          = "/events"
+	EventsWebSocketPath = "/events/ws"
+	// EventsKeepAlive es el intervalo de los mensajes que mantienen abierta una conexión sin eventos
+	EventsKeepAlive = 15 * time.Second
+	// EventsWriteTimeout es el tiempo máximo para enviar un mensaje por WebSocket
+	EventsWriteTimeout = 10 * time.Second
+)
+
+// EventHandler envía en tiempo real los eventos de órdenes y stock por Server-Sent Events y WebSocket
+type EventHandler struct {
+	subscriber ports.EventSubscriber
+	upgrader   websocket.Upgrader
+}
+
+// NewEventHandler registra los endpoints de eventos en Echo. Los clientes pueden filtrar por
+// order_id, product_id y customer_name.
+func NewEventHandler(e *echo.Echo, subscriber ports.EventSubscriber) {
+	handler := &EventHandler{subscriber: subscriber}
+
+	e.GET(EventsPath, handler.StreamEvents)
+	e.GET(EventsWebSocketPath, handler.StreamEventsWebSocket)
+}
+
+// StreamEvents mantiene abierta la respuesta y envía cada evento como un mensaje Server-Sent Events
+func (h *EventHandler) StreamEvents(c echo.Context) error {
+	var queryDTO dtos.EventQueryDTO
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &queryDTO); err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Parámetros de consulta inválidos")
+	}
+
+	events, cancel := h.subscriber.Subscribe(mappers.ConvertEventQueryDTOToFilter(queryDTO))
+	defer cancel()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	keepAlive := time.NewTicker(EventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			// Los comentarios son ignorados por EventSource pero evitan que los proxies cierren la conexión
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			data, err := json.Marshal(mappers.ConvertEventToDTO(event))
+			if err != nil {
+				slog.ErrorContext(c.Request().Context(), "Error al serializar el evento", "type", event.Type, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// StreamEventsWebSocket actualiza la conexión a WebSocket y envía cada evento como un mensaje JSON
+func (h *EventHandler) StreamEventsWebSocket(c echo.Context) error {
+	var queryDTO dtos.EventQueryDTO
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &queryDTO); err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Parámetros de consulta inválidos")
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// El upgrader ya respondió con el error al cliente
+		return nil
+	}
+	defer conn.Close()
+
+	events, cancel := h.subscriber.Subscribe(mappers.ConvertEventQueryDTOToFilter(queryDTO))
+	defer cancel()
+
+	// El cliente no envía datos: solo se lee para procesar los mensajes de control y detectar el cierre
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(EventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return nil
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(EventsWriteTimeout)); err != nil {
+				return nil
+			}
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(EventsWriteTimeout))
+			if err := conn.WriteJSON(mappers.ConvertEventToDTO(event)); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"order_management/internal/dtos"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// newEventServer levanta el handler de eventos con un suscriptor que entrega los eventos de events.
+// El canal canceled se cierra cuando el handler cancela la suscripción.
+func newEventServer(t *testing.T, filter ports.EventFilter, events chan ports.Event) (*httptest.Server, chan struct{}) {
+	ctrl := gomock.NewController(t)
+	subscriber := mocks.NewMockEventSubscriber(ctrl)
+
+	canceled := make(chan struct{})
+	subscriber.EXPECT().Subscribe(filter).Return((<-chan ports.Event)(events), func() { close(canceled) })
+
+	e := echo.New()
+	NewEventHandler(e, subscriber)
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+
+	return server, canceled
+}
+
+func statusChangedEvent() ports.Event {
+	return ports.Event{
+		Type:       ports.EventOrderStatusChanged,
+		OccurredAt: time.Now(),
+		Order:      &models.Order{ID: 7, CustomerName: "Customer 1", Status: models.OrderStatusShipped},
+	}
+}
+
+func assertCanceled(t *testing.T, canceled chan struct{}) {
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("el handler no canceló la suscripción")
+	}
+}
+
+// TestStreamEvents_SendsServerSentEvents verifica que cada evento se envíe con su tipo y la orden en
+// formato JSON, y que la respuesta termine al cerrarse la suscripción
+func TestStreamEvents_SendsServerSentEvents(t *testing.T) {
+	events := make(chan ports.Event, 1)
+	server, canceled := newEventServer(t, ports.EventFilter{OrderID: 7}, events)
+
+	res, err := http.Get(server.URL + EventsPath + "?order_id=7")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get(echo.HeaderContentType))
+
+	events <- statusChangedEvent()
+	reader := bufio.NewReader(res.Body)
+	eventLine, _ := reader.ReadString('\n')
+	dataLine, _ := reader.ReadString('\n')
+	assert.Equal(t, "event: "+ports.EventOrderStatusChanged+"\n", eventLine)
+
+	var event dtos.EventDTO
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(dataLine, "data: ")), &event))
+	assert.Equal(t, ports.EventOrderStatusChanged, event.Type)
+	assert.Equal(t, uint(7), event.Order.ID)
+	assert.Equal(t, models.OrderStatusShipped, event.Order.Status)
+
+	// Al cerrarse el canal, como durante el apagado, termina la respuesta
+	close(events)
+	rest, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "\n", string(rest))
+	assertCanceled(t, canceled)
+}
+
+// TestStreamEvents_CancelsSubscriptionOnDisconnect verifica que al desconectarse el cliente se
+// cancele la suscripción
+func TestStreamEvents_CancelsSubscriptionOnDisconnect(t *testing.T) {
+	server, canceled := newEventServer(t, ports.EventFilter{}, make(chan ports.Event))
+
+	res, err := http.Get(server.URL + EventsPath)
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	assertCanceled(t, canceled)
+}
+
+// TestStreamEvents_RejectsInvalidQuery verifica que un filtro inválido se rechace sin suscribirse
+func TestStreamEvents_RejectsInvalidQuery(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	NewEventHandler(e, mocks.NewMockEventSubscriber(gomock.NewController(t)))
+
+	for _, path := range []string{EventsPath, EventsWebSocketPath} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?order_id=abc", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, path)
+	}
+}
+
+// TestStreamEventsWebSocket_SendsEvents verifica que cada evento se envíe como un mensaje JSON, que
+// la conexión se cierre al cerrarse la suscripción y que se cancele la suscripción
+func TestStreamEventsWebSocket_SendsEvents(t *testing.T) {
+	events := make(chan ports.Event, 1)
+	server, canceled := newEventServer(t, ports.EventFilter{CustomerName: "Customer 1", ProductID: 3}, events)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + EventsWebSocketPath + "?customer_name=Customer+1&product_id=3"
+	conn, res, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+	events <- statusChangedEvent()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var event dtos.EventDTO
+	assert.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, ports.EventOrderStatusChanged, event.Type)
+	assert.Equal(t, uint(7), event.Order.ID)
+	assert.Equal(t, models.OrderStatusShipped, event.Order.Status)
+
+	close(events)
+	_, _, err = conn.ReadMessage()
+	assert.Error(t, err)
+	assertCanceled(t, canceled)
+}
+
+// TestStreamEventsWebSocket_CancelsSubscriptionOnClose verifica que al cerrar el cliente la conexión
+// se cancele la suscripción
+func TestStreamEventsWebSocket_CancelsSubscriptionOnClose(t *testing.T) {
+	server, canceled := newEventServer(t, ports.EventFilter{}, make(chan ports.Event))
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+EventsWebSocketPath, nil)
+	assert.NoError(t, err)
+	conn.Close()
+
+	assertCanceled(t, canceled)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"order_management/internal/dtos"
+	"order_management/internal/ports"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HealthPath = "/healthz"
+	ReadyPath  = "/readyz"
+)
+
+// HealthHandler expone el estado de la aplicación para los balanceadores y el orquestador.
+// /healthz indica si las dependencias responden; /readyz además deja de aceptar tráfico en cuanto
+// empieza el apagado, para que el balanceador deje de enviar solicitudes mientras se drenan las
+// que están en curso.
+type HealthHandler struct {
+	checks   map[string]ports.HealthChecker
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewHealthHandler registra los endpoints de salud en Echo. Cada verificación se cancela si no
+// responde dentro de timeout.
+func NewHealthHandler(e *echo.Echo, checks map[string]ports.HealthChecker, timeout time.Duration) *HealthHandler {
+	handler := &HealthHandler{checks: checks, timeout: timeout}
+
+	e.GET(HealthPath, handler.Health)
+	e.GET(ReadyPath, handler.Ready)
+	return handler
+}
+
+// Drain marca la aplicación como no lista para recibir tráfico
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Health responde 200 si todas las dependencias responden y 503 si alguna falla
+func (h *HealthHandler) Health(c echo.Context) error {
+	return h.respond(c, h.check(c.Request().Context()))
+}
+
+// Ready responde como Health, salvo durante el apagado, en que responde 503 sin verificar las dependencias
+func (h *HealthHandler) Ready(c echo.Context) error {
+	if h.draining.Load() {
+		return c.JSON(http.StatusServiceUnavailable, dtos.HealthResponseDTO{Status: dtos.HealthStatusDraining, Checks: map[string]string{}})
+	}
+	return h.respond(c, h.check(c.Request().Context()))
+}
+
+func (h *HealthHandler) respond(c echo.Context, response dtos.HealthResponseDTO) error {
+	if response.Status != dtos.HealthStatusOK {
+		return c.JSON(http.StatusServiceUnavailable, response)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// check verifica todas las dependencias en paralelo, cada una con su propio timeout
+func (h *HealthHandler) check(ctx context.Context) dtos.HealthResponseDTO {
+	response := dtos.HealthResponseDTO{Status: dtos.HealthStatusOK, Checks: make(map[string]string, len(h.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, checker := range h.checks {
+		wg.Add(1)
+		go func(name string, checker ports.HealthChecker) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+			err := checker.Check(checkCtx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				// Los endpoints no requieren autenticación: el detalle del error solo va al log
+				slog.WarnContext(ctx, "Una dependencia no responde", "dependency", name, "error", err)
+				response.Status = dtos.HealthStatusUnavailable
+				response.Checks[name] = dtos.HealthStatusUnavailable
+				return
+			}
+			response.Checks[name] = dtos.HealthStatusOK
+		}(name, checker)
+	}
+	wg.Wait()
+
+	return response
+}
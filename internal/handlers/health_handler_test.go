@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"order_management/internal/dtos"
+	"order_management/internal/ports"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHealthHandler_HidesDependencyErrors verifica que los endpoints sin autenticación informen el
+// estado de cada dependencia sin el detalle del error
+func TestHealthHandler_HidesDependencyErrors(t *testing.T) {
+	e := echo.New()
+	NewHealthHandler(e, map[string]ports.HealthChecker{
+		"mysql": ports.HealthCheckFunc(func(ctx context.Context) error { return nil }),
+		"redis": ports.HealthCheckFunc(func(ctx context.Context) error {
+			return errors.New("dial tcp 10.0.0.12:6379: connect: connection refused")
+		}),
+	}, time.Second)
+
+	for _, path := range []string{HealthPath, ReadyPath} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, path)
+		assert.NotContains(t, rec.Body.String(), "10.0.0.12", path)
+		var health dtos.HealthResponseDTO
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+		assert.Equal(t, dtos.HealthStatusUnavailable, health.Status)
+		assert.Equal(t, map[string]string{"mysql": dtos.HealthStatusOK, "redis": dtos.HealthStatusUnavailable}, health.Checks)
+	}
+}
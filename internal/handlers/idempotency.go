@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"time"
+
+	"order_management/internal/middlewares"
+	"order_management/internal/ports"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Tiempo durante el que cada ruta mutante repite su respuesta ante una Idempotency-Key ya usada.
+// 0 usa el TTL del store (IDEMPOTENCY_TTL).
+const (
+	OrderIdempotencyTTL    time.Duration = 0
+	CategoryIdempotencyTTL time.Duration = 0
+	// Los ajustes de stock y las importaciones suelen reintentarlos procesos programados, mucho
+	// después de la solicitud original
+	StockIdempotencyTTL  = time.Hour
+	ImportIdempotencyTTL = 24 * time.Hour
+	// Un documento GraphQL puede crear órdenes y ajustar stock; se usa el TTL de los ajustes de stock
+	GraphQLIdempotencyTTL = StockIdempotencyTTL
+)
+
+// idempotent es la opción de idempotencia de una ruta. Toda ruta que modifica datos debe declararla
+// para que los reintentos del cliente no apliquen la operación dos veces.
+func idempotent(store ports.IdempotencyStore, ttl time.Duration) echo.MiddlewareFunc {
+	return middlewares.IdempotencyMiddlewareWithConfig(store, middlewares.IdempotencyConfig{TTL: ttl})
+}
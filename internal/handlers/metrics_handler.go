@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MetricsPath es la ruta que consulta Prometheus. Queda fuera de /api, por lo que no forma parte de
+// la especificación OpenAPI ni pasa por su validación.
+const MetricsPath = "/metrics"
+
+// NewMetricsHandler registra en Echo el endpoint que publica las métricas en formato Prometheus
+func NewMetricsHandler(e *echo.Echo, handler http.Handler) {
+	e.GET(MetricsPath, echo.WrapHandler(handler))
+}
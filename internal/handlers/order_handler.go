@@ -1,72 +1,356 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"order_management/internal/apperrors"
 	"order_management/internal/dtos"
+	"order_management/internal/i18n"
 	"order_management/internal/mappers"
 	"order_management/internal/middlewares"
+	"order_management/internal/models"
 	"order_management/internal/ports"
 	"strconv"
+	"strings"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/labstack/echo/v4"
 )
 
 type OrderHandler struct {
-	orderService ports.OrderService
+	orderService     ports.OrderService
+	idempotencyStore ports.IdempotencyStore
+	// toResponse convierte la orden al DTO de respuesta de la versión de la API
+	toResponse func(order models.Order) interface{}
 }
 
-func NewOrderHandler(apiGroup *echo.Group, orderService ports.OrderService, redisClient *redis.Client) {
+// NewOrderHandler registra los endpoints de órdenes de la versión 1 de la API
+func NewOrderHandler(apiGroup *echo.Group, orderService ports.OrderService, idempotencyStore ports.IdempotencyStore) {
 	handler := &OrderHandler{
-		orderService: orderService,
+		orderService:     orderService,
+		idempotencyStore: idempotencyStore,
+		toResponse: func(order models.Order) interface{} {
+			return mappers.ConvertOrderToOrderResponseDTO(order)
+		},
 	}
+	handler.registerRoutes(apiGroup)
+}
+
+// NewOrderHandlerV2 registra los endpoints de órdenes de la versión 2 de la API, que comparten
+// el servicio con la versión 1 y solo cambian el formato de la respuesta
+func NewOrderHandlerV2(apiGroup *echo.Group, orderService ports.OrderService, idempotencyStore ports.IdempotencyStore) {
+	handler := &OrderHandler{
+		orderService:     orderService,
+		idempotencyStore: idempotencyStore,
+		toResponse: func(order models.Order) interface{} {
+			return mappers.ConvertOrderToOrderV2ResponseDTO(order)
+		},
+	}
+	handler.registerRoutes(apiGroup)
+}
 
-	// Rutas
-	// Aplicar middleware de idempotencia solo en POST /orders
-	apiGroup.POST("/orders", handler.CreateOrder, middlewares.IdempotencyMiddleware(redisClient))
-	apiGroup.GET("/orders/:id", handler.GetOrderById)
+func (h *OrderHandler) registerRoutes(apiGroup *echo.Group) {
+	apiGroup.POST("/orders", h.CreateOrder, deadline(WriteTimeout), idempotent(h.idempotencyStore, OrderIdempotencyTTL))
+	apiGroup.POST("/orders/batch", h.CreateOrdersBatch, deadline(BatchTimeout), idempotent(h.idempotencyStore, OrderIdempotencyTTL))
+	apiGroup.GET("/orders/:id", h.GetOrderById, deadline(ReadTimeout))
+	apiGroup.PUT("/orders/:id/status", h.UpdateOrderStatus, deadline(WriteTimeout), idempotent(h.idempotencyStore, OrderIdempotencyTTL))
 }
 
 // CreateOrder maneja la creación de un nuevo pedido
 func (h *OrderHandler) CreateOrder(c echo.Context) error {
 	var orderRequest dtos.OrderRequestDTO
 	if err := c.Bind(&orderRequest); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
 	}
 
 	// Validar la estructura después de Bind()
 	if err := c.Validate(orderRequest); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		return err
 	}
 
 	// Convertir DTO a modelo
 	order := mappers.ConvertOrderRequestDTOToOrder(orderRequest)
 
-	// Llamar al servicio para crear la orden
-	err := h.orderService.CreateOrder(&order)
+	// Llamar al servicio para crear la orden. Con Idempotency-Key la clave se asocia con la orden en
+	// la misma transacción si el store lo permite.
+	// Los errores de dominio se traducen a su código HTTP en HTTPErrorHandler
+	if err := h.orderService.CreateOrderIdempotent(c.Request().Context(), &order, middlewares.IdempotencyLockFromContext(c)); err != nil {
+		return err
+	}
+
+	// Devolver la orden creada junto con la URL donde puede consultarse
+	c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("%s/%d", c.Path(), order.ID))
+
+	return c.JSON(http.StatusCreated, h.toResponse(order))
+}
+
+// CreateOrdersBatch maneja la creación de un lote de órdenes. En modo all_or_nothing se crean todas
+// o ninguna; en modo independent cada orden se crea por separado. Cada orden puede traer su propia
+// clave de idempotencia: si ya se usó, se informa la orden creada originalmente sin crearla de nuevo.
+func (h *OrderHandler) CreateOrdersBatch(c echo.Context) error {
+	// Conservar el cuerpo para calcular la huella de cada orden sobre el JSON que envió el cliente
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
+	}
+	c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+	var batchRequest dtos.BatchOrderRequestDTO
+	if err := c.Bind(&batchRequest); err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
+	}
+	entryBodies, err := batchEntryBodies(body)
+	if err != nil || len(entryBodies) != len(batchRequest.Orders) {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
+	}
+
+	// Validar la estructura después de Bind()
+	if err := c.Validate(batchRequest); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	// Las reservas se renuevan, completan y liberan aunque el cliente se desconecte o venza la solicitud
+	lockCtx := context.WithoutCancel(ctx)
+	// Si un reintento toma la clave de alguna orden, se cancela la creación de las órdenes del lote
+	createCtx, cancelCreate := context.WithCancelCause(ctx)
+	defer cancelCreate(nil)
+	lang := i18n.LanguageFromContext(ctx)
+	allOrNothing := batchRequest.Mode == dtos.BatchModeAllOrNothing
+	results := make([]dtos.BatchOrderResultDTO, len(batchRequest.Orders))
+
+	// Las claves de cada orden se comparten con POST /orders: se reservan en el mismo ámbito del
+	// cliente y con la huella que tendría la orden enviada sola a esa ruta
+	scope := middlewares.ClientScope(c)
+	ordersPath := strings.TrimSuffix(c.Request().URL.Path, "/batch")
+	fingerprints := make([]string, len(batchRequest.Orders))
+	locks := make([]ports.IdempotencyLock, len(batchRequest.Orders))
+	stopKeepAlive := make([]func(), len(batchRequest.Orders))
+
+	// Reservar las claves de idempotencia y descartar las órdenes que ya fueron procesadas
+	var orders []*models.Order
+	var orderLocks []ports.IdempotencyLock
+	var positions []int
+	seenKeys := make(map[string]bool)
+	failed := false
+	for i, entry := range batchRequest.Orders {
+		results[i] = dtos.BatchOrderResultDTO{Index: i, IdempotencyKey: entry.IdempotencyKey}
+
+		if entry.IdempotencyKey != "" {
+			if seenKeys[entry.IdempotencyKey] {
+				failed = true
+				results[i] = batchFailure(results[i], apperrors.Invalid(apperrors.CodeDuplicateIdempotency, "la clave de idempotencia se repite en el lote"), lang)
+				continue
+			}
+			seenKeys[entry.IdempotencyKey] = true
+
+			fingerprints[i] = middlewares.RequestFingerprint(http.MethodPost, ordersPath, entryBodies[i])
+
+			lock, replayed, err := h.reserveBatchEntry(ctx, scope, entry.IdempotencyKey, fingerprints[i])
+			if err != nil || replayed != nil {
+				if err != nil {
+					failed = true
+					results[i] = batchFailure(results[i], err, lang)
+				} else {
+					failed = failed || replayed.Status == dtos.BatchStatusFailed
+					results[i] = *replayed
+					results[i].Index = i
+				}
+				continue
+			}
+
+			// Renovar la reserva mientras se crean las órdenes del lote
+			locks[i] = lock
+			stopKeepAlive[i] = lock.KeepAlive(lockCtx, func() { cancelCreate(ports.ErrIdempotencyLockLost) })
+		}
+
+		order := mappers.ConvertOrderRequestDTOToOrder(entry.OrderRequestDTO)
+		orders = append(orders, &order)
+		orderLocks = append(orderLocks, locks[i])
+		positions = append(positions, i)
+	}
+
+	// En modo todo o nada un error previo impide crear el resto de las órdenes
+	errs := make([]error, len(orders))
+	if allOrNothing && failed {
+		for j := range errs {
+			errs[j] = apperrors.Conflict(apperrors.CodeBatchAborted, "la orden no se creó porque otra orden del lote falló")
+		}
+	} else if len(orders) > 0 {
+		// Cada clave se asocia con su orden en la transacción que la crea si el store lo permite
+		errs = h.orderService.CreateOrders(createCtx, orders, orderLocks, allOrNothing)
+	}
+
+	for j, err := range errs {
+		i := positions[j]
+
+		if locks[i] != nil {
+			stopKeepAlive[i]()
+		}
+
+		if err != nil {
+			results[i] = batchFailure(results[i], err, lang)
+			if locks[i] != nil {
+				if err := locks[i].Release(lockCtx); err != nil {
+					slog.WarnContext(lockCtx, "Error al liberar la clave de idempotencia", "key", results[i].IdempotencyKey, "error", err)
+				}
+			}
+			continue
+		}
+
+		results[i].Status = dtos.BatchStatusCreated
+		results[i].OrderID = orders[j].ID
+		if locks[i] != nil {
+			// Guardar la misma respuesta que POST /orders para que ambas rutas compartan la clave
+			response, _ := json.Marshal(h.toResponse(*orders[j]))
+			err := locks[i].Complete(lockCtx, ports.IdempotencyRecord{
+				Fingerprint: fingerprints[i],
+				StatusCode:  http.StatusCreated,
+				Headers: map[string]string{
+					echo.HeaderContentType: echo.MIMEApplicationJSON,
+					echo.HeaderLocation:    fmt.Sprintf("%s/%d", ordersPath, orders[j].ID),
+				},
+				Response: response,
+			})
+			if err != nil {
+				// La orden ya se creó; si la reserva venció, la clave pertenece a un reintento
+				slog.WarnContext(lockCtx, "Error al guardar la respuesta idempotente", "key", results[i].IdempotencyKey, "error", err)
+			}
+		}
+	}
+
+	batchResponse := mappers.ConvertBatchOrderResultsToResponseDTO(batchRequest.Mode, results)
+	switch {
+	case batchResponse.Failed == 0:
+		return c.JSON(http.StatusOK, batchResponse)
+	case allOrNothing:
+		return c.JSON(http.StatusUnprocessableEntity, batchResponse)
+	default:
+		return c.JSON(http.StatusMultiStatus, batchResponse)
+	}
+}
+
+// reserveBatchEntry reserva la clave de idempotencia de una orden del lote y devuelve su lock. Si la clave ya fue
+// usada para la misma orden devuelve el resultado de la orden creada originalmente, o el error
+// original si la política de idempotencia lo guardó.
+func (h *OrderHandler) reserveBatchEntry(ctx context.Context, scope, idempotencyKey, fingerprint string) (ports.IdempotencyLock, *dtos.BatchOrderResultDTO, error) {
+	lock, storedData, err := middlewares.AcquireIdempotencyKey(ctx, h.idempotencyStore, middlewares.ScopedIdempotencyKey(scope, idempotencyKey), fingerprint,
+		ports.IdempotencyOptions{Lease: middlewares.DefaultLockLease, TTL: OrderIdempotencyTTL})
+	if _, ok := apperrors.As(err); ok {
+		return nil, nil, err
+	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		return nil, nil, apperrors.Internal(apperrors.CodeInternal, "error al verificar la clave de idempotencia", err)
+	}
+	if storedData == nil {
+		return lock, nil, nil
+	}
+	if storedData.Status == ports.IdempotencyInProgress {
+		return nil, nil, apperrors.Conflict(apperrors.CodeIdempotencyInProgress, "la petición está siendo procesada")
 	}
 
-	return c.JSON(http.StatusCreated, echo.Map{"message": "Orden creada correctamente"})
+	// Si la política guardó un error, se repite el mismo error en lugar de crear la orden
+	if storedData.StatusCode >= http.StatusBadRequest {
+		var storedError dtos.ErrorResponseDTO
+		if err := json.Unmarshal(storedData.Response, &storedError); err != nil {
+			return nil, nil, apperrors.Internal(apperrors.CodeInternal, "error al recuperar la respuesta almacenada", err)
+		}
+		return nil, &dtos.BatchOrderResultDTO{
+			IdempotencyKey: idempotencyKey,
+			Status:         dtos.BatchStatusFailed,
+			Error:          &storedError,
+		}, nil
+	}
+
+	var storedOrder struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(storedData.Response, &storedOrder); err != nil {
+		return nil, nil, apperrors.Internal(apperrors.CodeInternal, "error al recuperar la respuesta almacenada", err)
+	}
+	return nil, &dtos.BatchOrderResultDTO{
+		IdempotencyKey: idempotencyKey,
+		Status:         dtos.BatchStatusReplayed,
+		OrderID:        storedOrder.ID,
+	}, nil
+}
+
+// batchEntryBodies devuelve el JSON de cada orden del lote tal como lo envió el cliente, sin su
+// idempotency_key, que es el cuerpo que tendría la orden enviada sola a POST /orders. La huella se
+// calcula sobre ese JSON para que una clave pueda reutilizarse entre ambas rutas.
+func batchEntryBodies(body []byte) ([]json.RawMessage, error) {
+	var rawBatch struct {
+		Orders []map[string]json.RawMessage `json:"orders"`
+	}
+	if err := json.Unmarshal(body, &rawBatch); err != nil {
+		return nil, err
+	}
+
+	entries := make([]json.RawMessage, len(rawBatch.Orders))
+	for i, entry := range rawBatch.Orders {
+		delete(entry, "idempotency_key")
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = raw
+	}
+	return entries, nil
+}
+
+// batchFailure marca el resultado como fallido con el mismo cuerpo de error que el resto de la API
+func batchFailure(result dtos.BatchOrderResultDTO, err error, lang string) dtos.BatchOrderResultDTO {
+	_, body := errorResponse(err, lang)
+	result.Status = dtos.BatchStatusFailed
+	result.Error = &body
+	return result
 }
 
 // GetOrderById maneja la obtención de una orden por su ID
 func (h *OrderHandler) GetOrderById(c echo.Context) error {
 	orderIDInt, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "ID inválido"})
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "ID inválido")
 	}
 
 	orderID := uint(orderIDInt) // Conversión segura de int a uint
 
-	order, err := h.orderService.GetOrderById(orderID)
+	order, err := h.orderService.GetOrderById(c.Request().Context(), orderID)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "Order not found"})
+		return err
 	}
 
 	// Convertir model a DTO
-	orderDTO := mappers.ConvertOrderToOrderResponseDTO(*order)
+	orderDTO := h.toResponse(*order)
 
 	return c.JSON(http.StatusOK, orderDTO)
 }
+
+// UpdateOrderStatus maneja el cambio de estado de una orden
+func (h *OrderHandler) UpdateOrderStatus(c echo.Context) error {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "ID inválido")
+	}
+
+	var statusRequest dtos.UpdateOrderStatusRequestDTO
+	if err := c.Bind(&statusRequest); err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
+	}
+
+	// Validar la estructura después de Bind()
+	if err := c.Validate(statusRequest); err != nil {
+		return err
+	}
+
+	order, err := h.orderService.UpdateOrderStatus(c.Request().Context(), uint(orderID), statusRequest.Status)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, h.toResponse(*order))
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"order_management/internal/dtos"
+	"order_management/internal/idempotency"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/internal/validators"
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func postJSON(e *echo.Echo, target, body, idempotencyKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestOrderHandler_BatchSharesKeysWithCreateOrder verifica que una orden creada por POST /orders con una
+// clave se informe como repetida al enviarla en un lote con la misma clave, aunque el JSON de la orden
+// incluya campos con su valor nulo que la estructura descarta
+func TestOrderHandler_BatchSharesKeysWithCreateOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderService := mocks.NewMockOrderService(ctrl)
+	e := echo.New()
+	e.Validator = validators.NewValidator()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	NewOrderHandler(e.Group("/api"), orderService, idempotency.NewMemoryStore(idempotency.DefaultTTL))
+
+	orderService.EXPECT().CreateOrderIdempotent(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).
+		DoAndReturn(func(_ context.Context, order *models.Order, _ ports.IdempotencyLock) error {
+			order.ID = 7
+			return nil
+		}).Times(1)
+
+	created := postJSON(e, "/api/orders", `{"customer_name":"Ana","items":[{"product_id":1,"variant_id":null,"quantity":2}]}`, "pedido-1")
+	assert.Equal(t, http.StatusCreated, created.Code)
+
+	// Las propiedades en otro orden y con otros espacios no cambian la huella
+	rec := postJSON(e, "/api/orders/batch", `{"mode":"independent","orders":[
+		{"items":[{"quantity":2,"variant_id":null,"product_id":1}],"idempotency_key":"pedido-1","customer_name":"Ana"}
+	]}`, "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response dtos.BatchOrderResponseDTO
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	if assert.Len(t, response.Results, 1) {
+		assert.Equal(t, dtos.BatchStatusReplayed, response.Results[0].Status)
+		assert.Equal(t, uint(7), response.Results[0].OrderID)
+		assert.Nil(t, response.Results[0].Error)
+	}
+}
+
+// TestOrderHandler_CreateOrderReplaysBatchEntry verifica que POST /orders repita la orden creada en un
+// lote con la misma clave
+func TestOrderHandler_CreateOrderReplaysBatchEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderService := mocks.NewMockOrderService(ctrl)
+	e := echo.New()
+	e.Validator = validators.NewValidator()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	NewOrderHandler(e.Group("/api"), orderService, idempotency.NewMemoryStore(idempotency.DefaultTTL))
+
+	orderService.EXPECT().CreateOrders(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, orders []*models.Order, _ []ports.IdempotencyLock, _ bool) []error {
+			orders[0].ID = 9
+			return make([]error, len(orders))
+		}).Times(1)
+
+	batch := postJSON(e, "/api/orders/batch", `{"mode":"independent","orders":[
+		{"idempotency_key":"pedido-2","customer_name":"Luis","items":[{"product_id":3,"quantity":1}]}
+	]}`, "")
+	assert.Equal(t, http.StatusOK, batch.Code)
+
+	rec := postJSON(e, "/api/orders", `{"items":[{"product_id":3,"quantity":1}],"customer_name":"Luis"}`, "pedido-2")
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "/api/orders/9", rec.Header().Get(echo.HeaderLocation))
+}
@@ -1,59 +1,192 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 
+	"order_management/internal/apperrors"
 	"order_management/internal/dtos"
+	"order_management/internal/i18n"
 	"order_management/internal/mappers"
+	"order_management/internal/models"
 	"order_management/internal/ports"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// ImportBodyLimit es el tamaño máximo del cuerpo de las solicitudes de la API, el del CSV de la
+	// importación de productos. RegisterAPIRoutes lo aplica a todo el grupo antes de validar la solicitud.
+	ImportBodyLimit = "10M"
+)
+
 // ProductHandler maneja las solicitudes HTTP relacionadas con productos
 type ProductHandler struct {
 	productService ports.ProductService
 }
 
 // NewProductHandler registra los endpoints de productos en Echo
-func NewProductHandler(apiGroup *echo.Group, productService ports.ProductService) {
+func NewProductHandler(apiGroup *echo.Group, productService ports.ProductService, idempotencyStore ports.IdempotencyStore) {
 	handler := &ProductHandler{productService: productService}
 
-	apiGroup.GET("/products", handler.GetAllProducts)
+	apiGroup.GET("/products", handler.ListProducts, deadline(ReadTimeout))
+	apiGroup.GET("/products/export", handler.ExportProducts, deadline(ExportTimeout))
+	apiGroup.POST("/products/import", handler.ImportProducts, deadline(ImportTimeout), idempotent(idempotencyStore, ImportIdempotencyTTL))
 	//apiGroup.GET("/products/:id", handler.GetProductByID)
-	apiGroup.PUT("/products/:id/stock", handler.UpdateStock)
+	apiGroup.PUT("/products/:id/stock", handler.UpdateStock, deadline(WriteTimeout), idempotent(idempotencyStore, StockIdempotencyTTL))
+	apiGroup.PUT("/products/:id/variants/:variantId/stock", handler.UpdateVariantStock, deadline(WriteTimeout), idempotent(idempotencyStore, StockIdempotencyTTL))
 }
 
-// GetAllProducts maneja la solicitud para obtener todos los productos
-func (h *ProductHandler) GetAllProducts(c echo.Context) error {
-	products, err := h.productService.GetAllProducts()
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Error al obtener productos"})
+// ListProducts maneja la solicitud para buscar, filtrar y paginar los productos
+func (h *ProductHandler) ListProducts(c echo.Context) error {
+	var queryDTO dtos.ProductListQueryDTO
+	if err := c.Bind(&queryDTO); err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Parámetros de consulta inválidos")
 	}
 
-	productResponseDTOs := mappers.ConvertProductToProductResponseDTO(products)
+	if err := c.Validate(queryDTO); err != nil {
+		return err
+	}
 
-	return c.JSON(http.StatusOK, productResponseDTOs)
+	filter := mappers.ConvertProductListQueryDTOToFilter(queryDTO)
+
+	products, total, err := h.productService.SearchProducts(c.Request().Context(), filter)
+	if err != nil {
+		return apperrors.Internal(apperrors.CodeInternal, "error al obtener productos", err)
+	}
+
+	return c.JSON(http.StatusOK, mappers.ConvertProductPageToResponseDTO(products, total, filter))
 }
 
 // UpdateStock maneja la solicitud para actualizar el stock de un producto
 func (h *ProductHandler) UpdateStock(c echo.Context) error {
 	idInt, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "ID inválido"})
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "ID inválido")
 	}
 
 	var stockDTO dtos.UpdateStocRequestkDTO
 	if err := c.Bind(&stockDTO); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Datos de entrada inválidos"})
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
+	}
+
+	if err := c.Validate(stockDTO); err != nil {
+		return err
 	}
 
 	id := uint(idInt) // Conversión segura de int a uint
 
-	if err := h.productService.UpdateStock(id, stockDTO.Stock); err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Error al actualizar stock"})
+	if err := h.productService.UpdateStock(c.Request().Context(), id, stockDTO.Stock); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": i18n.Translate(i18n.LanguageFromContext(c.Request().Context()), "Stock actualizado correctamente")})
+}
+
+// UpdateVariantStock maneja la solicitud para actualizar el stock de una variante de producto
+func (h *ProductHandler) UpdateVariantStock(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "ID inválido")
+	}
+
+	variantIDInt, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "ID de variante inválido")
+	}
+
+	var stockDTO dtos.UpdateStocRequestkDTO
+	if err := c.Bind(&stockDTO); err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
+	}
+
+	if err := c.Validate(stockDTO); err != nil {
+		return err
+	}
+
+	if err := h.productService.UpdateVariantStock(c.Request().Context(), uint(idInt), uint(variantIDInt), stockDTO.Stock); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": i18n.Translate(i18n.LanguageFromContext(c.Request().Context()), "Stock actualizado correctamente")})
+}
+
+// ImportProducts maneja la importación masiva de productos y variantes desde un CSV.
+// El archivo puede enviarse como campo "file" de un formulario multipart o directamente como cuerpo text/csv.
+func (h *ProductHandler) ImportProducts(c echo.Context) error {
+	var queryDTO dtos.ProductImportQueryDTO
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &queryDTO); err != nil {
+		return apperrors.Invalid(apperrors.CodeInvalidRequest, "Parámetros de consulta inválidos")
+	}
+
+	var body io.Reader = c.Request().Body
+	if file, err := c.FormFile("file"); err == nil {
+		src, err := file.Open()
+		if err != nil {
+			return apperrors.Invalid(apperrors.CodeInvalidRequest, "No se pudo leer el archivo")
+		}
+		defer src.Close()
+		body = src
+	}
+
+	// Los errores de lectura del CSV ya tienen su código y se traducen en HTTPErrorHandler
+	rows, err := mappers.ParseProductImportCSV(body)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.productService.ImportProducts(c.Request().Context(), rows, queryDTO.DryRun)
+	if err != nil {
+		return err
+	}
+
+	reportDTO := mappers.ConvertProductImportReportToResponseDTO(*report, queryDTO.DryRun)
+
+	// Traducir los errores de cada fila al idioma del cliente
+	lang := i18n.LanguageFromContext(c.Request().Context())
+	for _, row := range reportDTO.Rows {
+		for i, rowErr := range row.Errors {
+			row.Errors[i] = i18n.Translate(lang, rowErr)
+		}
+	}
+	if reportDTO.Failed > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, reportDTO)
+	}
+
+	return c.JSON(http.StatusOK, reportDTO)
+}
+
+// ExportProducts maneja la exportación del catálogo completo en CSV, escribiendo la respuesta por lotes
+func (h *ProductHandler) ExportProducts(c echo.Context) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="products.csv"`)
+	res.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(res)
+	if err := writer.Write(mappers.ProductCSVHeader); err != nil {
+		return err
+	}
+
+	err := h.productService.ExportProducts(c.Request().Context(), func(products []models.Product) error {
+		for _, product := range products {
+			if err := writer.WriteAll(mappers.ConvertProductToCSVRecords(product)); err != nil {
+				return err
+			}
+		}
+		// Enviar el lote al cliente antes de leer el siguiente
+		res.Flush()
+		return nil
+	})
+	if err != nil {
+		// La cabecera ya fue enviada, solo queda registrar el error y cortar la respuesta
+		slog.ErrorContext(c.Request().Context(), "Error al exportar productos", "error", err)
+		return nil
 	}
 
-	return c.JSON(http.StatusOK, echo.Map{"message": "Stock actualizado correctamente"})
+	writer.Flush()
+	return writer.Error()
 }
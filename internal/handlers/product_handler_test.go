@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/dtos"
+	"order_management/internal/validators"
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// newProductServer registra los endpoints de productos sobre los mocks indicados
+func newProductServer(ctrl *gomock.Controller, productService *mocks.MockProductService) *echo.Echo {
+	e := echo.New()
+	e.Validator = validators.NewValidator()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	NewProductHandler(e.Group("/api"), productService, mocks.NewMockIdempotencyStore(ctrl))
+	return e
+}
+
+func putStock(e *echo.Echo, target, body string) (*httptest.ResponseRecorder, dtos.ErrorResponseDTO) {
+	req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var response dtos.ErrorResponseDTO
+	_ = json.Unmarshal(rec.Body.Bytes(), &response)
+	return rec, response
+}
+
+func TestProductHandler_UpdateStockUnknownProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productService := mocks.NewMockProductService(ctrl)
+	e := newProductServer(ctrl, productService)
+
+	productService.EXPECT().UpdateStock(gomock.Any(), uint(999999), 5).
+		Return(apperrors.NotFound(apperrors.CodeProductNotFound, "producto no encontrado").WithDetail("product_id", uint(999999))).
+		Times(1)
+
+	rec, response := putStock(e, "/api/products/999999/stock", `{"stock":5}`)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, string(apperrors.CodeProductNotFound), response.Code)
+}
+
+func TestProductHandler_RejectsNegativeStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// El servicio no se llama si el stock es negativo
+	e := newProductServer(ctrl, mocks.NewMockProductService(ctrl))
+
+	for _, target := range []string{"/api/products/1/stock", "/api/products/1/variants/3/stock"} {
+		rec, response := putStock(e, target, `{"stock":-1}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.Equal(t, string(apperrors.CodeValidationFailed), response.Code, target)
+		if assert.Len(t, response.Fields, 1, target) {
+			assert.Equal(t, "stock", response.Fields[0].Field)
+			assert.Equal(t, "gte", response.Fields[0].Rule)
+		}
+	}
+}
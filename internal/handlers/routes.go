@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"order_management/internal/middlewares"
+	"order_management/internal/ports"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	// APIPrefix se mantiene como alias de la versión 1 para los clientes existentes
+	APIPrefix   = "/api"
+	APIV1Prefix = "/api/v1"
+	APIV2Prefix = "/api/v2"
+)
+
+// Services agrupa los servicios que comparten todas las versiones de la API
+type Services struct {
+	Product  ports.ProductService
+	Category ports.CategoryService
+	Order    ports.OrderService
+}
+
+// RegisterAPIRoutes registra las versiones de la API una junto a otra. La versión 1 (y su alias
+// /api) responde con cabeceras de obsolescencia que apuntan a la versión 2. Los middlewares
+// recibidos se aplican a todos los grupos, después de limitar el tamaño del cuerpo para que la
+// validación contra el documento OpenAPI nunca lea un cuerpo mayor que ImportBodyLimit. Las rutas que
+// modifican datos aceptan Idempotency-Key con las claves guardadas en idempotencyStore.
+func RegisterAPIRoutes(e *echo.Echo, services Services, idempotencyStore ports.IdempotencyStore, m ...echo.MiddlewareFunc) {
+	m = append([]echo.MiddlewareFunc{middleware.BodyLimit(ImportBodyLimit)}, m...)
+
+	for _, prefix := range []string{APIPrefix, APIV1Prefix} {
+		v1 := e.Group(prefix, middlewares.DeprecationMiddleware(prefix, APIV2Prefix))
+		v1.Use(m...)
+
+		NewProductHandler(v1, services.Product, idempotencyStore)
+		NewCategoryHandler(v1, services.Category, idempotencyStore)
+		NewOrderHandler(v1, services.Order, idempotencyStore)
+	}
+
+	v2 := e.Group(APIV2Prefix, m...)
+
+	NewProductHandler(v2, services.Product, idempotencyStore)
+	NewCategoryHandler(v2, services.Category, idempotencyStore)
+	NewOrderHandlerV2(v2, services.Order, idempotencyStore)
+}
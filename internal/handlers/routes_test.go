@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegisterAPIRoutes_LimitsBodyBeforeMiddlewares verifica que un cuerpo mayor que ImportBodyLimit se
+// rechace antes de que los middlewares recibidos, como la validación OpenAPI, lean el cuerpo
+func TestRegisterAPIRoutes_LimitsBodyBeforeMiddlewares(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validated := 0
+	validator := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			validated++
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	RegisterAPIRoutes(e, Services{
+		Product:  mocks.NewMockProductService(ctrl),
+		Category: mocks.NewMockCategoryService(ctrl),
+		Order:    mocks.NewMockOrderService(ctrl),
+	}, mocks.NewMockIdempotencyStore(ctrl), validator)
+
+	send := func(target string, size int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(make([]byte, size)))
+		req.Header.Set(echo.HeaderContentType, "text/csv")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, prefix := range []string{APIPrefix, APIV1Prefix, APIV2Prefix} {
+		tooLarge := send(prefix+"/products/import", 11<<20)
+		accepted := send(prefix+"/products/import", 1<<10)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, tooLarge.Code, prefix)
+		assert.Equal(t, http.StatusNoContent, accepted.Code, prefix)
+	}
+	assert.Equal(t, 3, validated)
+}
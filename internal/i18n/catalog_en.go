@@ -0,0 +1,76 @@
+package i18n
+
+// en contiene las traducciones al inglés
+var en = Catalog{
+	// Errores generales
+	"error interno del servidor":                                          "internal server error",
+	"la solicitud contiene campos inválidos":                              "the request contains invalid fields",
+	"la solicitud no cumple la especificación de la API":                  "the request does not comply with the API specification",
+	"Datos de entrada inválidos":                                          "Invalid input data",
+	"Parámetros de consulta inválidos":                                    "Invalid query parameters",
+	"ID inválido":                                                         "Invalid ID",
+	"ID de variante inválido":                                             "Invalid variant ID",
+	"error al confirmar la transacción":                                   "error committing the transaction",
+	"la operación no pudo completarse por contención, intente nuevamente": "the operation could not be completed due to contention, try again",
+	"la solicitud excedió el tiempo máximo de procesamiento":              "the request exceeded the maximum processing time",
+	"la solicitud se canceló":                                             "the request was canceled",
+
+	// Órdenes
+	"orden no encontrada":                                    "order not found",
+	"error al buscar la orden":                               "error fetching the order",
+	"error al buscar órdenes":                                "error fetching orders",
+	"page debe ser mayor a 0 y pageSize estar entre 1 y 100": "page must be greater than 0 and pageSize between 1 and 100",
+	"error al crear la orden":                                "error creating the order",
+	"la orden no se creó porque otra orden del lote falló":   "the order was not created because another order in the batch failed",
+	"la orden no puede pasar a ese estado":                   "the order cannot move to that status",
+	"la orden cambió de estado, intente nuevamente":          "the order status changed, try again",
+	"error al actualizar el estado de la orden":              "error updating the order status",
+
+	// Idempotencia
+	"la petición está siendo procesada":                             "the request is being processed",
+	"la clave de idempotencia ya se usó con una solicitud distinta": "the idempotency key was already used with a different request",
+	"la clave de idempotencia se repite en el lote":                 "the idempotency key is repeated in the batch",
+	"error al verificar la clave de idempotencia":                   "error checking the idempotency key",
+	"error al recuperar la respuesta almacenada":                    "error retrieving the stored response",
+	"clave de idempotencia no encontrada":                           "idempotency key not found",
+	"la clave de idempotencia no puede superar los %d caracteres":   "the idempotency key cannot exceed %d characters",
+	"error al eliminar la clave de idempotencia":                    "error deleting the idempotency key",
+
+	// Productos y stock
+	"producto no encontrado":                                  "product not found",
+	"variante no encontrada":                                  "variant not found",
+	"la variante no pertenece al producto":                    "the variant does not belong to the product",
+	"stock insuficiente para el producto %d (disponible: %d)": "insufficient stock for product %d (available: %d)",
+	"la cantidad del producto %d debe estar entre 1 y %d":     "the quantity of product %d must be between 1 and %d",
+	"error al actualizar stock":                               "error updating stock",
+	"error al buscar producto":                                "error fetching the product",
+	"error al buscar productos":                               "error fetching products",
+	"error al buscar variante":                                "error fetching the variant",
+	"error al obtener productos":                              "error retrieving products",
+	"Stock actualizado correctamente":                         "Stock updated successfully",
+
+	// Importación de productos
+	"No se pudo leer el archivo":                               "Could not read the file",
+	"el archivo CSV está vacío":                                "the CSV file is empty",
+	"columna desconocida en el CSV: %q":                        "unknown column in the CSV: %q",
+	"error al leer la línea %d del CSV":                        "error reading line %d of the CSV",
+	"attributes debe tener el formato clave=valor;clave=valor": "attributes must have the format key=value;key=value",
+	"product_id debe ser un entero positivo":                   "product_id must be a positive integer",
+	"price debe ser un número mayor o igual a 0":               "price must be a number greater than or equal to 0",
+	"stock debe ser un entero mayor o igual a 0":               "stock must be an integer greater than or equal to 0",
+	"category_id debe ser un entero positivo":                  "category_id must be a positive integer",
+	"sku no puede superar los 64 caracteres":                   "sku cannot exceed 64 characters",
+	"name es obligatorio para crear un producto":               "name is required to create a product",
+	"price es obligatorio para crear un producto":              "price is required to create a product",
+	"product_id es obligatorio para una variante":              "product_id is required for a variant",
+	"el SKU pertenece a otro producto":                         "the SKU belongs to another product",
+	"error al guardar el producto":                             "error saving the product",
+	"error al guardar la variante":                             "error saving the variant",
+
+	// Categorías
+	"categoría padre no encontrada":      "parent category not found",
+	"error al buscar la categoría padre": "error fetching the parent category",
+	"error al buscar categorías":         "error fetching categories",
+	"error al obtener categorías":        "error retrieving categories",
+	"error al crear la categoría":        "error creating the category",
+}
@@ -0,0 +1,76 @@
+package i18n
+
+// pt contiene las traducciones al portugués
+var pt = Catalog{
+	// Errores generales
+	"error interno del servidor":                                          "erro interno do servidor",
+	"la solicitud contiene campos inválidos":                              "a requisição contém campos inválidos",
+	"la solicitud no cumple la especificación de la API":                  "a requisição não cumpre a especificação da API",
+	"Datos de entrada inválidos":                                          "Dados de entrada inválidos",
+	"Parámetros de consulta inválidos":                                    "Parâmetros de consulta inválidos",
+	"ID inválido":                                                         "ID inválido",
+	"ID de variante inválido":                                             "ID de variante inválido",
+	"error al confirmar la transacción":                                   "erro ao confirmar a transação",
+	"la operación no pudo completarse por contención, intente nuevamente": "a operação não pôde ser concluída por contenção, tente novamente",
+	"la solicitud excedió el tiempo máximo de procesamiento":              "a requisição excedeu o tempo máximo de processamento",
+	"la solicitud se canceló":                                             "a requisição foi cancelada",
+
+	// Órdenes
+	"orden no encontrada":                                    "pedido não encontrado",
+	"error al buscar la orden":                               "erro ao buscar o pedido",
+	"error al buscar órdenes":                                "erro ao buscar pedidos",
+	"page debe ser mayor a 0 y pageSize estar entre 1 y 100": "page deve ser maior que 0 e pageSize estar entre 1 e 100",
+	"error al crear la orden":                                "erro ao criar o pedido",
+	"la orden no se creó porque otra orden del lote falló":   "o pedido não foi criado porque outro pedido do lote falhou",
+	"la orden no puede pasar a ese estado":                   "o pedido não pode passar para esse status",
+	"la orden cambió de estado, intente nuevamente":          "o status do pedido mudou, tente novamente",
+	"error al actualizar el estado de la orden":              "erro ao atualizar o status do pedido",
+
+	// Idempotencia
+	"la petición está siendo procesada":                             "a requisição está sendo processada",
+	"la clave de idempotencia ya se usó con una solicitud distinta": "a chave de idempotência já foi usada com uma requisição diferente",
+	"la clave de idempotencia se repite en el lote":                 "a chave de idempotência se repete no lote",
+	"error al verificar la clave de idempotencia":                   "erro ao verificar a chave de idempotência",
+	"error al recuperar la respuesta almacenada":                    "erro ao recuperar a resposta armazenada",
+	"clave de idempotencia no encontrada":                           "chave de idempotência não encontrada",
+	"la clave de idempotencia no puede superar los %d caracteres":   "a chave de idempotência não pode exceder %d caracteres",
+	"error al eliminar la clave de idempotencia":                    "erro ao excluir a chave de idempotência",
+
+	// Productos y stock
+	"producto no encontrado":                                  "produto não encontrado",
+	"variante no encontrada":                                  "variante não encontrada",
+	"la variante no pertenece al producto":                    "a variante não pertence ao produto",
+	"stock insuficiente para el producto %d (disponible: %d)": "estoque insuficiente para o produto %d (disponível: %d)",
+	"la cantidad del producto %d debe estar entre 1 y %d":     "a quantidade do produto %d deve estar entre 1 e %d",
+	"error al actualizar stock":                               "erro ao atualizar o estoque",
+	"error al buscar producto":                                "erro ao buscar o produto",
+	"error al buscar productos":                               "erro ao buscar produtos",
+	"error al buscar variante":                                "erro ao buscar a variante",
+	"error al obtener productos":                              "erro ao obter produtos",
+	"Stock actualizado correctamente":                         "Estoque atualizado com sucesso",
+
+	// Importación de productos
+	"No se pudo leer el archivo":                               "Não foi possível ler o arquivo",
+	"el archivo CSV está vacío":                                "o arquivo CSV está vazio",
+	"columna desconocida en el CSV: %q":                        "coluna desconhecida no CSV: %q",
+	"error al leer la línea %d del CSV":                        "erro ao ler a linha %d do CSV",
+	"attributes debe tener el formato clave=valor;clave=valor": "attributes deve ter o formato chave=valor;chave=valor",
+	"product_id debe ser un entero positivo":                   "product_id deve ser um inteiro positivo",
+	"price debe ser un número mayor o igual a 0":               "price deve ser um número maior ou igual a 0",
+	"stock debe ser un entero mayor o igual a 0":               "stock deve ser um inteiro maior ou igual a 0",
+	"category_id debe ser un entero positivo":                  "category_id deve ser um inteiro positivo",
+	"sku no puede superar los 64 caracteres":                   "sku não pode ultrapassar 64 caracteres",
+	"name es obligatorio para crear un producto":               "name é obrigatório para criar um produto",
+	"price es obligatorio para crear un producto":              "price é obrigatório para criar um produto",
+	"product_id es obligatorio para una variante":              "product_id é obrigatório para uma variante",
+	"el SKU pertenece a otro producto":                         "o SKU pertence a outro produto",
+	"error al guardar el producto":                             "erro ao salvar o produto",
+	"error al guardar la variante":                             "erro ao salvar a variante",
+
+	// Categorías
+	"categoría padre no encontrada":      "categoria pai não encontrada",
+	"error al buscar la categoría padre": "erro ao buscar a categoria pai",
+	"error al buscar categorías":         "erro ao buscar categorias",
+	"error al obtener categorías":        "erro ao obter categorias",
+	"error al crear la categoría":        "erro ao criar a categoria",
+}
@@ -0,0 +1,78 @@
+// Package i18n traduce los mensajes que la API devuelve a los clientes. Los mensajes se escriben en
+// español en el código, que es el idioma por defecto, y ese mismo texto es la clave con la que se
+// buscan en los catálogos de los demás idiomas. Un mensaje sin traducción se devuelve sin cambios.
+package i18n
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	DefaultLanguage = "es"
+)
+
+// Catalog asocia cada mensaje en español con su traducción
+type Catalog map[string]string
+
+// catalogs contiene las traducciones de cada idioma soportado distinto del español
+var catalogs = map[string]Catalog{
+	"en": en,
+	"pt": pt,
+}
+
+// SupportedLanguages son los idiomas que puede negociar Accept-Language, con el idioma por defecto primero
+var SupportedLanguages = []string{DefaultLanguage, "en", "pt"}
+
+var matcher = language.NewMatcher(func() []language.Tag {
+	tags := make([]language.Tag, len(SupportedLanguages))
+	for i, lang := range SupportedLanguages {
+		tags[i] = language.MustParse(lang)
+	}
+	return tags
+}())
+
+type contextKey struct{}
+
+// MatchLanguage elige el idioma soportado que mejor cumple con la cabecera Accept-Language.
+// Si la cabecera está vacía, es inválida o no coincide con ningún idioma se usa el idioma por defecto.
+func MatchLanguage(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLanguage
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLanguage
+	}
+	return SupportedLanguages[index]
+}
+
+// WithLanguage devuelve una copia del contexto con el idioma de la solicitud
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// LanguageFromContext devuelve el idioma de la solicitud o el idioma por defecto si no se negoció
+func LanguageFromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// Translate devuelve el mensaje en el idioma indicado
+func Translate(lang, message string) string {
+	if translated, ok := catalogs[lang][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf traduce un mensaje con formato y lo completa con los argumentos
+func Sprintf(lang, format string, args ...interface{}) string {
+	return fmt.Sprintf(Translate(lang, format), args...)
+}
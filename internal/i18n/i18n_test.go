@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var formatVerb = regexp.MustCompile(`%[a-z]`)
+
+// TestCatalogsTranslateTheSameMessages falla si un mensaje se agrega a un catálogo y no a los demás
+// o si una traducción no conserva los verbos de formato del mensaje original
+func TestCatalogsTranslateTheSameMessages(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for otherLang, other := range catalogs {
+			for message := range catalog {
+				_, ok := other[message]
+				assert.True(t, ok, "falta la traducción al %s de %q (existe en %s)", otherLang, message, lang)
+			}
+		}
+
+		for message, translation := range catalog {
+			assert.Equal(t, formatVerb.FindAllString(message, -1), formatVerb.FindAllString(translation, -1), "%s: %q", lang, message)
+		}
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	assert.Equal(t, DefaultLanguage, MatchLanguage(""))
+	assert.Equal(t, DefaultLanguage, MatchLanguage("not a language;;"))
+	assert.Equal(t, DefaultLanguage, MatchLanguage("ja-JP"))
+	assert.Equal(t, "en", MatchLanguage("en-GB,en;q=0.9,es;q=0.8"))
+	assert.Equal(t, "pt", MatchLanguage("pt-BR"))
+	assert.Equal(t, "es", MatchLanguage("es-MX"))
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "order not found", Translate("en", "orden no encontrada"))
+	assert.Equal(t, "orden no encontrada", Translate("es", "orden no encontrada"))
+	// Los mensajes sin traducción se devuelven sin cambios
+	assert.Equal(t, "mensaje sin traducción", Translate("en", "mensaje sin traducción"))
+
+	assert.Equal(t, "insufficient stock for product 3 (available: 1)", Sprintf("en", "stock insuficiente para el producto %d (disponible: %d)", 3, 1))
+}
+
+func TestLanguageFromContext(t *testing.T) {
+	assert.Equal(t, DefaultLanguage, LanguageFromContext(context.Background()))
+	assert.Equal(t, "pt", LanguageFromContext(WithLanguage(context.Background(), "pt")))
+}
@@ -0,0 +1,45 @@
+package idempotency
+
+import (
+	"context"
+
+	"order_management/internal/ports"
+)
+
+// InstrumentedStore registra en las métricas cómo se resuelve cada reserva de clave. Envuelve al
+// store configurado, por lo que cubre el middleware REST, la creación de órdenes en lote y gRPC.
+type InstrumentedStore struct {
+	ports.IdempotencyStore
+	metrics ports.IdempotencyMetrics
+}
+
+// NewInstrumentedStore envuelve store para registrar sus reservas en metrics
+func NewInstrumentedStore(store ports.IdempotencyStore, metrics ports.IdempotencyMetrics) *InstrumentedStore {
+	return &InstrumentedStore{IdempotencyStore: store, metrics: metrics}
+}
+
+// Acquire reserva la clave en el store envuelto. Una clave nueva cuenta como miss; una existente como
+// hit y, según su estado, como conflicto o como respuesta repetida. El lock se devuelve sin envolver
+// para conservar las interfaces opcionales del store, como TransactionalIdempotencyLock.
+func (s *InstrumentedStore) Acquire(ctx context.Context, key, fingerprint string, options ports.IdempotencyOptions) (ports.IdempotencyLock, *ports.IdempotencyRecord, error) {
+	lock, storedData, err := s.IdempotencyStore.Acquire(ctx, key, fingerprint, options)
+	if err != nil {
+		return lock, storedData, err
+	}
+
+	if storedData == nil {
+		s.metrics.IdempotencyMiss()
+		return lock, storedData, nil
+	}
+
+	s.metrics.IdempotencyHit()
+	switch {
+	case storedData.Fingerprint != fingerprint:
+		s.metrics.IdempotencyConflict(ports.IdempotencyConflictMismatch)
+	case storedData.Status == ports.IdempotencyInProgress:
+		s.metrics.IdempotencyConflict(ports.IdempotencyConflictInProgress)
+	default:
+		s.metrics.IdempotencyReplay()
+	}
+	return lock, storedData, nil
+}
@@ -0,0 +1,60 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+
+	"order_management/internal/ports"
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstrumentedStore_ClassifiesAcquire verifica las métricas de cada resultado de la reserva
+func TestInstrumentedStore_ClassifiesAcquire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	metrics := mocks.NewMockIdempotencyMetrics(ctrl)
+	store := NewInstrumentedStore(NewMemoryStore(testTTL), metrics)
+	ctx := context.Background()
+
+	// Clave nueva
+	metrics.EXPECT().IdempotencyMiss()
+	lock, _, err := store.Acquire(ctx, "client:key-1", "fp", testOptions)
+	assert.NoError(t, err)
+
+	// Clave en curso
+	metrics.EXPECT().IdempotencyHit()
+	metrics.EXPECT().IdempotencyConflict(ports.IdempotencyConflictInProgress)
+	_, _, err = store.Acquire(ctx, "client:key-1", "fp", testOptions)
+	assert.NoError(t, err)
+
+	// Clave completada
+	assert.NoError(t, lock.Complete(ctx, ports.IdempotencyRecord{Fingerprint: "fp", StatusCode: 201}))
+	metrics.EXPECT().IdempotencyHit()
+	metrics.EXPECT().IdempotencyReplay()
+	_, record, err := store.Acquire(ctx, "client:key-1", "fp", testOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, 201, record.StatusCode)
+
+	// Clave usada con otra solicitud
+	metrics.EXPECT().IdempotencyHit()
+	metrics.EXPECT().IdempotencyConflict(ports.IdempotencyConflictMismatch)
+	_, _, err = store.Acquire(ctx, "client:key-1", "otra", testOptions)
+	assert.NoError(t, err)
+}
+
+// TestInstrumentedStore_KeepsTransactionalLock verifica que el lock del store SQL siga permitiendo
+// asociar la clave con la orden
+func TestInstrumentedStore_KeepsTransactionalLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	metrics := mocks.NewMockIdempotencyMetrics(ctrl)
+	store := NewInstrumentedStore(NewSQLStore(newTestDB(t), testTTL), metrics)
+
+	metrics.EXPECT().IdempotencyMiss()
+	lock, _, err := store.Acquire(context.Background(), "client:key-1", "fp", testOptions)
+	assert.NoError(t, err)
+
+	_, ok := lock.(ports.TransactionalIdempotencyLock)
+	assert.True(t, ok)
+}
@@ -0,0 +1,155 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"order_management/internal/ports"
+)
+
+// memoryEntry es una clave guardada en memoria. expiresAt es el vencimiento de la reserva mientras
+// la clave está en curso y el de la respuesta una vez completada.
+type memoryEntry struct {
+	record    ports.IdempotencyRecord
+	lockToken string
+	expiresAt time.Time
+}
+
+// MemoryStore guarda las claves en memoria. Solo protege a una instancia de la aplicación y pierde las
+// claves al reiniciarla, por lo que está pensado para desarrollo y pruebas.
+type MemoryStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+// NewMemoryStore crea un store en memoria que guarda las respuestas durante ttl
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{ttl: ttl, now: time.Now, entries: make(map[string]*memoryEntry)}
+}
+
+// Acquire reserva la clave si no existe o si su reserva o su respuesta vencieron
+func (s *MemoryStore) Acquire(ctx context.Context, key, fingerprint string, options ports.IdempotencyOptions) (ports.IdempotencyLock, *ports.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.removeExpired(now)
+
+	if entry, ok := s.entries[key]; ok {
+		return nil, entry.snapshot(), nil
+	}
+
+	ttl := s.ttl
+	if options.TTL > 0 {
+		ttl = options.TTL
+	}
+	token := newLockToken()
+	s.entries[key] = &memoryEntry{
+		record:    ports.IdempotencyRecord{Status: ports.IdempotencyInProgress, Fingerprint: fingerprint},
+		lockToken: token,
+		expiresAt: now.Add(options.Lease),
+	}
+	return &memoryLock{store: s, key: key, token: token, lease: options.Lease, ttl: ttl}, nil, nil
+}
+
+// Get devuelve los datos de la clave si no venció
+func (s *MemoryStore) Get(ctx context.Context, key string) (*ports.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired(s.now())
+	if entry, ok := s.entries[key]; ok {
+		return entry.snapshot(), nil
+	}
+	return nil, nil
+}
+
+// Delete elimina la clave
+func (s *MemoryStore) Delete(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired(s.now())
+	_, ok := s.entries[key]
+	delete(s.entries, key)
+	return ok, nil
+}
+
+// snapshot devuelve una copia de los datos de la clave con su vencimiento
+func (e *memoryEntry) snapshot() *ports.IdempotencyRecord {
+	record := e.record
+	record.ExpiresAt = e.expiresAt
+	return &record
+}
+
+// removeExpired elimina las claves vencidas
+func (s *MemoryStore) removeExpired(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// held devuelve la clave si sigue reservada por token. Debe llamarse con el mutex tomado.
+func (s *MemoryStore) held(key, token string) (*memoryEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok || entry.lockToken != token || !s.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// memoryLock es la reserva de una clave en memoria
+type memoryLock struct {
+	store *MemoryStore
+	key   string
+	token string
+	lease time.Duration
+	ttl   time.Duration
+}
+
+// KeepAlive renueva la reserva cada tercio de su duración
+func (l *memoryLock) KeepAlive(ctx context.Context, lost func()) (stop func()) {
+	return keepAlive(ctx, l.lease, lost, func() (bool, error) {
+		l.store.mu.Lock()
+		defer l.store.mu.Unlock()
+
+		entry, ok := l.store.held(l.key, l.token)
+		if !ok {
+			return false, nil
+		}
+		entry.expiresAt = l.store.now().Add(l.lease)
+		return true, nil
+	}, l.key)
+}
+
+func (l *memoryLock) Complete(ctx context.Context, record ports.IdempotencyRecord) error {
+	l.store.mu.Lock()
+	defer l.store.mu.Unlock()
+
+	entry, ok := l.store.held(l.key, l.token)
+	if !ok {
+		return ports.ErrIdempotencyLockLost
+	}
+	record.Status = ports.IdempotencyCompleted
+	entry.record = record
+	entry.lockToken = ""
+	entry.expiresAt = l.store.now().Add(l.ttl)
+	return nil
+}
+
+func (l *memoryLock) Release(ctx context.Context) error {
+	l.store.mu.Lock()
+	defer l.store.mu.Unlock()
+
+	if _, ok := l.store.held(l.key, l.token); !ok {
+		return ports.ErrIdempotencyLockLost
+	}
+	delete(l.store.entries, l.key)
+	return nil
+}
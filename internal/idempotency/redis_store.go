@@ -0,0 +1,208 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"order_management/internal/ports"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	KeyPrefix = "idempotency:"
+	// maxAcquireAttempts acota los reintentos cuando la clave vence entre el SET NX y la lectura
+	maxAcquireAttempts = 3
+)
+
+var (
+	// renewScript extiende la reserva solo si la clave sigue guardando el valor de quien la tomó
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	// completeScript reemplaza la reserva por la respuesta solo si la clave sigue siendo de quien la tomó
+	completeScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return 1
+end
+return 0`)
+
+	// releaseScript elimina la reserva solo si la clave sigue siendo de quien la tomó
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// redisEntry es el valor guardado en Redis. El token distingue la reserva de cada solicitud.
+type redisEntry struct {
+	ports.IdempotencyRecord
+	LockToken string `json:"lock_token,omitempty"`
+}
+
+// RedisStore guarda las claves en Redis. La reserva se toma con SET NX y su vencimiento es el de la
+// clave, por lo que una instancia caída deja la clave libre al vencer la reserva.
+type RedisStore struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+// NewRedisStore crea un store sobre Redis que guarda las respuestas durante ttl
+func NewRedisStore(redisClient *redis.Client, ttl time.Duration) *RedisStore {
+	return &RedisStore{redisClient: redisClient, ttl: ttl}
+}
+
+// Acquire reserva la clave con SET NX
+func (s *RedisStore) Acquire(ctx context.Context, key, fingerprint string, options ports.IdempotencyOptions) (ports.IdempotencyLock, *ports.IdempotencyRecord, error) {
+	redisKey := KeyPrefix + key
+	ttl := s.ttl
+	if options.TTL > 0 {
+		ttl = options.TTL
+	}
+	jsonData, _ := json.Marshal(redisEntry{
+		IdempotencyRecord: ports.IdempotencyRecord{Status: ports.IdempotencyInProgress, Fingerprint: fingerprint},
+		LockToken:         newLockToken(),
+	})
+
+	for attempt := 0; attempt < maxAcquireAttempts; attempt++ {
+		reserved, err := s.redisClient.SetNX(ctx, redisKey, jsonData, options.Lease).Result()
+		if err != nil {
+			return nil, nil, err
+		}
+		if reserved {
+			return &redisLock{store: s, redisKey: redisKey, value: string(jsonData), lease: options.Lease, ttl: ttl}, nil, nil
+		}
+
+		data, err := s.redisClient.Get(ctx, redisKey).Bytes()
+		if err == redis.Nil {
+			// La clave venció entre el SET NX y la lectura: volver a intentar la reserva
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var entry redisEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, nil, err
+		}
+		return nil, &entry.IdempotencyRecord, nil
+	}
+
+	return nil, &ports.IdempotencyRecord{Status: ports.IdempotencyInProgress, Fingerprint: fingerprint}, nil
+}
+
+// Get devuelve los datos de la clave junto con su vencimiento
+func (s *RedisStore) Get(ctx context.Context, key string) (*ports.IdempotencyRecord, error) {
+	redisKey := KeyPrefix + key
+
+	data, err := s.redisClient.Get(ctx, redisKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry redisEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if ttl, err := s.redisClient.PTTL(ctx, redisKey).Result(); err == nil && ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+	return &entry.IdempotencyRecord, nil
+}
+
+// Delete elimina la clave
+func (s *RedisStore) Delete(ctx context.Context, key string) (bool, error) {
+	deleted, err := s.redisClient.Del(ctx, KeyPrefix+key).Result()
+	return deleted > 0, err
+}
+
+// redisLock es la reserva de una clave en Redis. Renovarla, completarla y liberarla son scripts que
+// comparan el valor guardado, de modo que no afectan a otra solicitud que haya tomado la clave
+// después de que la reserva venció.
+type redisLock struct {
+	store    *RedisStore
+	redisKey string
+	value    string
+	lease    time.Duration
+	ttl      time.Duration
+}
+
+// KeepAlive renueva la reserva cada tercio de su duración
+func (l *redisLock) KeepAlive(ctx context.Context, lost func()) (stop func()) {
+	return keepAlive(ctx, l.lease, lost, func() (bool, error) {
+		renewed, err := renewScript.Run(ctx, l.store.redisClient, []string{l.redisKey}, l.value, l.lease.Milliseconds()).Int()
+		return renewed == 1, err
+	}, l.redisKey)
+}
+
+func (l *redisLock) Complete(ctx context.Context, record ports.IdempotencyRecord) error {
+	record.Status = ports.IdempotencyCompleted
+	jsonData, _ := json.Marshal(redisEntry{IdempotencyRecord: record})
+
+	completed, err := completeScript.Run(ctx, l.store.redisClient, []string{l.redisKey}, l.value, jsonData, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if completed == 0 {
+		return ports.ErrIdempotencyLockLost
+	}
+	return nil
+}
+
+func (l *redisLock) Release(ctx context.Context) error {
+	released, err := releaseScript.Run(ctx, l.store.redisClient, []string{l.redisKey}, l.value).Int()
+	if err != nil {
+		return err
+	}
+	if released == 0 {
+		return ports.ErrIdempotencyLockLost
+	}
+	return nil
+}
+
+// keepAlive llama a renew cada tercio de lease hasta que se llama a la función devuelta, se cancela
+// ctx o la reserva se pierde, en cuyo caso llama a lost
+func keepAlive(ctx context.Context, lease time.Duration, lost func(), renew func() (bool, error), key string) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				renewed, err := renew()
+				if err != nil {
+					slog.WarnContext(ctx, "Error al renovar la reserva de la clave de idempotencia", "key", key, "error", err)
+					continue
+				}
+				if !renewed {
+					slog.WarnContext(ctx, "La reserva de la clave de idempotencia venció antes de terminar la solicitud", "key", key)
+					if lost != nil {
+						lost()
+					}
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,210 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"order_management/internal/models"
+	"order_management/internal/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SQLStore guarda las claves en la tabla idempotency_keys de la misma base de datos que las órdenes.
+// Sus reservas implementan ports.TransactionalIdempotencyLock: la transacción que crea la orden
+// asocia la clave con la orden, de modo que un reintento nunca crea una segunda orden aunque la
+// instancia se caiga antes de guardar la respuesta.
+//
+// A diferencia de Redis, una clave en curso no desaparece cuando vence su reserva: el reintento la
+// toma conservando la orden asociada, si la hay.
+type SQLStore struct {
+	db  *gorm.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewSQLStore crea un store sobre la base de datos que guarda las claves durante ttl
+func NewSQLStore(db *gorm.DB, ttl time.Duration) *SQLStore {
+	return &SQLStore{db: db, ttl: ttl, now: time.Now}
+}
+
+// Acquire inserta la clave si no existe o toma la reserva de una clave en curso cuya reserva venció
+func (s *SQLStore) Acquire(ctx context.Context, key, fingerprint string, options ports.IdempotencyOptions) (ports.IdempotencyLock, *ports.IdempotencyRecord, error) {
+	db := s.db.WithContext(ctx)
+	now := s.now()
+	token := newLockToken()
+	lease := options.Lease
+	ttl := s.ttl
+	if options.TTL > 0 {
+		ttl = options.TTL
+	}
+
+	// Las claves vencidas se eliminan antes de reservarlas de nuevo
+	if err := db.Where("idempotency_key = ? AND expires_at <= ?", key, now).Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, nil, err
+	}
+
+	row := models.IdempotencyKey{
+		Key:         key,
+		Status:      ports.IdempotencyInProgress,
+		Fingerprint: fingerprint,
+		LockToken:   token,
+		LockedUntil: now.Add(lease),
+		ExpiresAt:   now.Add(ttl),
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return &sqlLock{store: s, key: key, token: token, lease: lease, ttl: ttl}, nil, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := db.Where("idempotency_key = ?", key).First(&existing).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// Tomar la reserva de una solicitud que no la renovó, por ejemplo porque la instancia se cayó
+	if existing.Status == ports.IdempotencyInProgress && existing.Fingerprint == fingerprint && !now.Before(existing.LockedUntil) {
+		result := db.Model(&models.IdempotencyKey{}).
+			Where("idempotency_key = ? AND lock_token = ? AND status = ?", key, existing.LockToken, ports.IdempotencyInProgress).
+			Updates(map[string]interface{}{"lock_token": token, "locked_until": now.Add(lease)})
+		if result.Error != nil {
+			return nil, nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return &sqlLock{store: s, key: key, token: token, lease: lease, ttl: ttl}, nil, nil
+		}
+	}
+
+	return nil, toRecord(existing), nil
+}
+
+// Get devuelve los datos de la clave si no venció
+func (s *SQLStore) Get(ctx context.Context, key string) (*ports.IdempotencyRecord, error) {
+	var row models.IdempotencyKey
+	err := s.db.WithContext(ctx).Where("idempotency_key = ? AND expires_at > ?", key, s.now()).First(&row).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toRecord(row), nil
+}
+
+// Delete elimina la clave, incluso si tiene una orden asociada
+func (s *SQLStore) Delete(ctx context.Context, key string) (bool, error) {
+	result := s.db.WithContext(ctx).Where("idempotency_key = ?", key).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// toRecord convierte la fila en los datos de la clave
+func toRecord(row models.IdempotencyKey) *ports.IdempotencyRecord {
+	return &ports.IdempotencyRecord{
+		Status:      row.Status,
+		Fingerprint: row.Fingerprint,
+		StatusCode:  row.StatusCode,
+		Headers:     row.Headers,
+		Response:    row.Response,
+		ExpiresAt:   row.ExpiresAt,
+	}
+}
+
+// sqlLock es la reserva de una clave en la base de datos
+type sqlLock struct {
+	store *SQLStore
+	key   string
+	token string
+	lease time.Duration
+	ttl   time.Duration
+}
+
+// held filtra la clave reservada por este lock
+func (l *sqlLock) held(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.IdempotencyKey{}).Where("idempotency_key = ? AND lock_token = ?", l.key, l.token)
+}
+
+// KeepAlive renueva la reserva cada tercio de su duración
+func (l *sqlLock) KeepAlive(ctx context.Context, lost func()) (stop func()) {
+	return keepAlive(ctx, l.lease, lost, func() (bool, error) {
+		result := l.held(l.store.db.WithContext(ctx)).Update("locked_until", l.store.now().Add(l.lease))
+		return result.RowsAffected == 1, result.Error
+	}, l.key)
+}
+
+func (l *sqlLock) Complete(ctx context.Context, record ports.IdempotencyRecord) error {
+	result := l.held(l.store.db.WithContext(ctx)).
+		Select("status", "status_code", "headers", "response", "lock_token", "expires_at").
+		Updates(&models.IdempotencyKey{
+			Status:     ports.IdempotencyCompleted,
+			StatusCode: record.StatusCode,
+			Headers:    record.Headers,
+			Response:   record.Response,
+			LockToken:  "",
+			ExpiresAt:  l.store.now().Add(l.ttl),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ports.ErrIdempotencyLockLost
+	}
+	return nil
+}
+
+// Release elimina la clave si no tiene una orden asociada. Si la orden ya se creó, la clave se
+// conserva con la reserva vencida para que el reintento la tome y devuelva esa misma orden.
+func (l *sqlLock) Release(ctx context.Context) error {
+	db := l.store.db.WithContext(ctx)
+
+	result := l.held(db).Where("order_id IS NULL").Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil
+	}
+
+	result = l.held(db).Update("locked_until", l.store.now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ports.ErrIdempotencyLockLost
+	}
+	return nil
+}
+
+// LockTx bloquea la fila de la clave dentro de tx para que ningún reintento la tome mientras se
+// crea la orden
+func (l *sqlLock) LockTx(tx *gorm.DB) (uint, error) {
+	var row models.IdempotencyKey
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("idempotency_key = ?", l.key).First(&row).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, ports.ErrIdempotencyLockLost
+	}
+	if err != nil {
+		return 0, err
+	}
+	if row.LockToken != l.token {
+		return 0, ports.ErrIdempotencyLockLost
+	}
+	if row.OrderID == nil {
+		return 0, nil
+	}
+	return *row.OrderID, nil
+}
+
+func (l *sqlLock) BindTx(tx *gorm.DB, orderID uint) error {
+	result := l.held(tx).Update("order_id", orderID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ports.ErrIdempotencyLockLost
+	}
+	return nil
+}
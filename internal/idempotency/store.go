@@ -0,0 +1,53 @@
+// Package idempotency implementa ports.IdempotencyStore sobre Redis, en memoria y sobre la base de
+// datos. El store se elige con la configuración: Redis comparte las claves entre instancias, SQL
+// además las guarda en la misma transacción que la orden y la memoria sirve para desarrollo y pruebas.
+package idempotency
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"order_management/internal/ports"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	StoreRedis  = "redis"
+	StoreMemory = "memory"
+	StoreSQL    = "sql"
+
+	// DefaultTTL es el tiempo durante el que se repiten las respuestas de una clave
+	DefaultTTL = 10 * time.Minute
+)
+
+// Config define qué store usar y durante cuánto tiempo se guardan las respuestas
+type Config struct {
+	// Store es redis, memory o sql
+	Store string        `yaml:"store"`
+	TTL   time.Duration `yaml:"ttl"`
+}
+
+// NewStore crea el store indicado en la configuración
+func NewStore(config Config, redisClient *redis.Client, db *gorm.DB) (ports.IdempotencyStore, error) {
+	switch config.Store {
+	case StoreRedis:
+		return NewRedisStore(redisClient, config.TTL), nil
+	case StoreMemory:
+		return NewMemoryStore(config.TTL), nil
+	case StoreSQL:
+		return NewSQLStore(db, config.TTL), nil
+	default:
+		return nil, fmt.Errorf("store de idempotencia desconocido: %q", config.Store)
+	}
+}
+
+// newLockToken genera el identificador aleatorio de una reserva
+func newLockToken() string {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
@@ -0,0 +1,380 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"order_management/internal/models"
+	"order_management/internal/ports"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const (
+	testTTL   = time.Minute
+	testLease = time.Second
+)
+
+var testOptions = ports.IdempotencyOptions{Lease: testLease}
+
+// fakeClock es un reloj que solo avanza cuando el test lo indica
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// testStore es un store junto con la forma de avanzar su reloj
+type testStore struct {
+	store   ports.IdempotencyStore
+	advance func(time.Duration)
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	assert.NoError(t, db.AutoMigrate(&models.IdempotencyKey{}))
+	return db
+}
+
+// newTestStores crea cada implementación del store con un reloj controlado por el test
+func newTestStores(t *testing.T) map[string]func(t *testing.T) testStore {
+	return map[string]func(t *testing.T) testStore{
+		StoreRedis: func(t *testing.T) testStore {
+			redisServer := miniredis.RunT(t)
+			redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+			t.Cleanup(func() { redisClient.Close() })
+			return testStore{store: NewRedisStore(redisClient, testTTL), advance: redisServer.FastForward}
+		},
+		StoreMemory: func(t *testing.T) testStore {
+			clock := &fakeClock{now: time.Now()}
+			store := NewMemoryStore(testTTL)
+			store.now = clock.Now
+			return testStore{store: store, advance: clock.Advance}
+		},
+		StoreSQL: func(t *testing.T) testStore {
+			clock := &fakeClock{now: time.Now()}
+			store := NewSQLStore(newTestDB(t), testTTL)
+			store.now = clock.Now
+			return testStore{store: store, advance: clock.Advance}
+		},
+	}
+}
+
+// TestStores_OnlyOneHolder verifica que una clave reservada no pueda tomarse de nuevo
+func TestStores_OnlyOneHolder(t *testing.T) {
+	for name, newStore := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			ctx := context.Background()
+
+			lock, record, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.NotNil(t, lock)
+			assert.Nil(t, record)
+
+			second, record, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.Nil(t, second)
+			assert.Equal(t, ports.IdempotencyInProgress, record.Status)
+			assert.Equal(t, "fp", record.Fingerprint)
+		})
+	}
+}
+
+// TestStores_CompleteAndExpire verifica que la respuesta se repita hasta que vence el TTL
+func TestStores_CompleteAndExpire(t *testing.T) {
+	for name, newStore := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			ctx := context.Background()
+
+			lock, _, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.NoError(t, lock.Complete(ctx, ports.IdempotencyRecord{
+				Fingerprint: "fp",
+				StatusCode:  201,
+				Headers:     map[string]string{"Location": "/api/orders/1"},
+				Response:    []byte(`{"id": 1}`),
+			}))
+
+			// La respuesta sobrevive a la reserva
+			s.advance(2 * testLease)
+			_, record, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.Equal(t, ports.IdempotencyCompleted, record.Status)
+			assert.Equal(t, 201, record.StatusCode)
+			assert.Equal(t, "/api/orders/1", record.Headers["Location"])
+			assert.Equal(t, `{"id": 1}`, string(record.Response))
+
+			s.advance(testTTL)
+			lock, record, err = s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.NotNil(t, lock)
+			assert.Nil(t, record)
+		})
+	}
+}
+
+// TestStores_Release verifica que una clave liberada pueda reservarse de nuevo
+func TestStores_Release(t *testing.T) {
+	for name, newStore := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			ctx := context.Background()
+
+			lock, _, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.NoError(t, lock.Release(ctx))
+
+			lock, record, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.NotNil(t, lock)
+			assert.Nil(t, record)
+		})
+	}
+}
+
+// TestStores_TakeoverAfterLease verifica que un reintento tome la clave cuando vence la reserva y que
+// la solicitud original ya no pueda modificarla
+func TestStores_TakeoverAfterLease(t *testing.T) {
+	for name, newStore := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			ctx := context.Background()
+
+			stale, _, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+
+			// La instancia que tomó la clave deja de renovarla, por ejemplo porque se cayó
+			s.advance(testLease)
+
+			retry, record, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.NotNil(t, retry)
+			assert.Nil(t, record)
+
+			assert.ErrorIs(t, stale.Complete(ctx, ports.IdempotencyRecord{Fingerprint: "fp", Response: []byte(`{"id":1}`)}), ports.ErrIdempotencyLockLost)
+			assert.ErrorIs(t, stale.Release(ctx), ports.ErrIdempotencyLockLost)
+
+			assert.NoError(t, retry.Complete(ctx, ports.IdempotencyRecord{Fingerprint: "fp", Response: []byte(`{"id":2}`)}))
+			_, record, err = s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.Equal(t, ports.IdempotencyCompleted, record.Status)
+			assert.Equal(t, `{"id":2}`, string(record.Response))
+		})
+	}
+}
+
+// TestStores_KeepAliveRenewsLease verifica que la reserva se renueve mientras la solicitud sigue en curso
+func TestStores_KeepAliveRenewsLease(t *testing.T) {
+	lease := 60 * time.Millisecond
+
+	for name, newStore := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			ctx := context.Background()
+
+			lock, _, err := s.store.Acquire(ctx, "client:key-1", "fp", ports.IdempotencyOptions{Lease: lease})
+			assert.NoError(t, err)
+
+			stop := lock.KeepAlive(ctx, nil)
+			defer stop()
+
+			// Sin renovación la reserva habría vencido después de avanzar el reloj dos veces
+			s.advance(40 * time.Millisecond)
+			time.Sleep(lease)
+			s.advance(40 * time.Millisecond)
+
+			_, record, err := s.store.Acquire(ctx, "client:key-1", "fp", ports.IdempotencyOptions{Lease: lease})
+			assert.NoError(t, err)
+			assert.NotNil(t, record)
+			assert.Equal(t, ports.IdempotencyInProgress, record.Status)
+		})
+	}
+}
+
+// TestStores_KeepAliveReportsLostLease verifica que KeepAlive avise cuando otra solicitud toma la clave
+// y que la reserva perdida ya no pueda completarse
+func TestStores_KeepAliveReportsLostLease(t *testing.T) {
+	lease := 60 * time.Millisecond
+
+	for name, newStore := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			ctx := context.Background()
+
+			lock, _, err := s.store.Acquire(ctx, "client:key-1", "fp", ports.IdempotencyOptions{Lease: lease})
+			assert.NoError(t, err)
+
+			lost := make(chan struct{})
+			stop := lock.KeepAlive(ctx, func() { close(lost) })
+			defer stop()
+
+			// Un administrador elimina la clave y un reintento la vuelve a tomar
+			deleted, err := s.store.Delete(ctx, "client:key-1")
+			assert.NoError(t, err)
+			assert.True(t, deleted)
+			retry, _, err := s.store.Acquire(ctx, "client:key-1", "fp", ports.IdempotencyOptions{Lease: time.Minute})
+			assert.NoError(t, err)
+			assert.NotNil(t, retry)
+
+			select {
+			case <-lost:
+			case <-time.After(5 * lease):
+				assert.Fail(t, "KeepAlive no informó la pérdida de la reserva")
+			}
+			assert.ErrorIs(t, lock.Complete(ctx, ports.IdempotencyRecord{Fingerprint: "fp"}), ports.ErrIdempotencyLockLost)
+		})
+	}
+}
+
+// TestStores_RouteTTL verifica que el TTL de la ruta reemplace al del store
+func TestStores_RouteTTL(t *testing.T) {
+	for name, newStore := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			ctx := context.Background()
+			options := ports.IdempotencyOptions{Lease: testLease, TTL: 2 * testTTL}
+
+			lock, _, err := s.store.Acquire(ctx, "client:key-1", "fp", options)
+			assert.NoError(t, err)
+			assert.NoError(t, lock.Complete(ctx, ports.IdempotencyRecord{Fingerprint: "fp", Response: []byte(`{}`)}))
+
+			// Con el TTL del store la respuesta ya habría vencido
+			s.advance(testTTL + testLease)
+			_, record, err := s.store.Acquire(ctx, "client:key-1", "fp", options)
+			assert.NoError(t, err)
+			assert.Equal(t, ports.IdempotencyCompleted, record.Status)
+
+			s.advance(testTTL)
+			lock, record, err = s.store.Acquire(ctx, "client:key-1", "fp", options)
+			assert.NoError(t, err)
+			assert.NotNil(t, lock)
+			assert.Nil(t, record)
+		})
+	}
+}
+
+// TestStores_GetAndDelete verifica que una clave pueda inspeccionarse y eliminarse esté en curso o completada
+func TestStores_GetAndDelete(t *testing.T) {
+	for name, newStore := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			ctx := context.Background()
+
+			record, err := s.store.Get(ctx, "client:key-1")
+			assert.NoError(t, err)
+			assert.Nil(t, record)
+
+			lock, _, err := s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+
+			record, err = s.store.Get(ctx, "client:key-1")
+			assert.NoError(t, err)
+			assert.Equal(t, ports.IdempotencyInProgress, record.Status)
+
+			assert.NoError(t, lock.Complete(ctx, ports.IdempotencyRecord{
+				Fingerprint: "fp",
+				StatusCode:  201,
+				Response:    []byte(`{"id": 1}`),
+			}))
+
+			record, err = s.store.Get(ctx, "client:key-1")
+			assert.NoError(t, err)
+			assert.Equal(t, ports.IdempotencyCompleted, record.Status)
+			assert.Equal(t, "fp", record.Fingerprint)
+			assert.Equal(t, 201, record.StatusCode)
+			assert.Equal(t, `{"id": 1}`, string(record.Response))
+			assert.WithinDuration(t, time.Now().Add(testTTL), record.ExpiresAt, 5*time.Second)
+
+			deleted, err := s.store.Delete(ctx, "client:key-1")
+			assert.NoError(t, err)
+			assert.True(t, deleted)
+
+			deleted, err = s.store.Delete(ctx, "client:key-1")
+			assert.NoError(t, err)
+			assert.False(t, deleted)
+
+			// Una clave eliminada puede reservarse de nuevo
+			lock, record, err = s.store.Acquire(ctx, "client:key-1", "fp", testOptions)
+			assert.NoError(t, err)
+			assert.NotNil(t, lock)
+			assert.Nil(t, record)
+		})
+	}
+}
+
+// TestSQLStore_KeepsOrderAfterCrash verifica que la clave asociada con una orden no se pierda al
+// liberarla y que el reintento que la toma reciba esa orden
+func TestSQLStore_KeepsOrderAfterCrash(t *testing.T) {
+	db := newTestDB(t)
+	clock := &fakeClock{now: time.Now()}
+	store := NewSQLStore(db, testTTL)
+	store.now = clock.Now
+	ctx := context.Background()
+
+	lock, _, err := store.Acquire(ctx, "client:key-1", "fp", testOptions)
+	assert.NoError(t, err)
+	txLock := lock.(ports.TransactionalIdempotencyLock)
+
+	// La transacción de la orden asocia la clave antes de confirmarse
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		orderID, err := txLock.LockTx(tx)
+		assert.Zero(t, orderID)
+		if err != nil {
+			return err
+		}
+		return txLock.BindTx(tx, 7)
+	}))
+
+	// La respuesta no llega a guardarse: liberar la clave no debe permitir crear otra orden
+	assert.NoError(t, lock.Release(ctx))
+
+	retry, record, err := store.Acquire(ctx, "client:key-1", "fp", testOptions)
+	assert.NoError(t, err)
+	assert.NotNil(t, retry)
+	assert.Nil(t, record)
+
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		orderID, err := retry.(ports.TransactionalIdempotencyLock).LockTx(tx)
+		assert.Equal(t, uint(7), orderID)
+		return err
+	}))
+
+	// La reserva anterior ya no puede asociar la clave con otra orden
+	assert.ErrorIs(t, db.Transaction(func(tx *gorm.DB) error {
+		return txLock.BindTx(tx, 8)
+	}), ports.ErrIdempotencyLockLost)
+}
+
+func TestNewStore(t *testing.T) {
+	for _, name := range []string{StoreRedis, StoreMemory, StoreSQL} {
+		store, err := NewStore(Config{Store: name, TTL: testTTL}, nil, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, store)
+	}
+
+	_, err := NewStore(Config{Store: "etcd", TTL: testTTL}, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+// Package logging configura el log estructurado en JSON de la aplicación. Cada línea registrada con
+// un contexto incluye el ID de la solicitud que la originó y, si la solicitud tiene una traza, los IDs
+// de la traza y del span, para poder seguir una solicitud a través de los handlers, los servicios, los
+// repositorios y las consultas SQL, y saltar del log a la traza.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Atributos con los que se registran el ID de la solicitud y los de su traza
+const (
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+)
+
+// Levels asocia los niveles de log aceptados en la configuración con los de slog
+var Levels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// Config define el nivel mínimo de los mensajes que se registran
+type Config struct {
+	// Level es el nivel mínimo: debug, info, warn o error
+	Level string `yaml:"level"`
+}
+
+// New crea un logger que escribe en w una línea JSON por mensaje, a partir del nivel configurado
+func New(w io.Writer, config Config) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: Levels[config.Level]})
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+type requestIDKey struct{}
+
+// WithRequestID devuelve una copia de ctx con el ID de la solicitud
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext devuelve el ID de la solicitud guardado en ctx, o vacío si no tiene
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler agrega a cada mensaje el ID de la solicitud y de la traza del contexto con el que se
+// registra
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		record.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String(TraceIDKey, span.TraceID().String()), slog.String(SpanIDKey, span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
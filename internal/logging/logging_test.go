@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestLogger_AddsRequestIDFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: "info"})
+
+	ctx := WithRequestID(context.Background(), "abc-123")
+	logger.With("component", "test").InfoContext(ctx, "Orden creada", "order_id", 7)
+	logger.Info("Sin solicitud")
+
+	lines := decodeLines(t, &buf)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "Orden creada", lines[0]["msg"])
+	assert.Equal(t, "abc-123", lines[0][RequestIDKey])
+	assert.Equal(t, "test", lines[0]["component"])
+	assert.Equal(t, float64(7), lines[0]["order_id"])
+	assert.NotContains(t, lines[1], RequestIDKey)
+}
+
+func TestLogger_AddsTraceFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: "info"})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	logger.InfoContext(ctx, "Orden creada")
+	logger.Info("Sin traza")
+
+	lines := decodeLines(t, &buf)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", lines[0][TraceIDKey])
+	assert.Equal(t, "00f067aa0ba902b7", lines[0][SpanIDKey])
+	assert.NotContains(t, lines[1], TraceIDKey)
+}
+
+func TestLogger_FiltersByLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: "warn"})
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	lines := decodeLines(t, &buf)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "WARN", lines[0]["level"])
+	assert.Equal(t, "ERROR", lines[1]["level"])
+}
+
+func TestRequestIDFromContext_Empty(t *testing.T) {
+	assert.Empty(t, RequestIDFromContext(context.Background()))
+}
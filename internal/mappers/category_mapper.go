@@ -0,0 +1,33 @@
+package mappers
+
+import (
+	"order_management/internal/dtos"
+	"order_management/internal/models"
+)
+
+func ConvertCategoryRequestDTOToCategory(categoryRequestDTO dtos.CategoryRequestDTO) models.Category {
+	return models.Category{
+		Name:     categoryRequestDTO.Name,
+		ParentID: categoryRequestDTO.ParentID,
+	}
+}
+
+func ConvertCategoryToCategoryResponseDTO(category models.Category) dtos.CategoryResponseDTO {
+	return dtos.CategoryResponseDTO{
+		ID:       category.ID,
+		Name:     category.Name,
+		ParentID: category.ParentID,
+		Children: ConvertCategoriesToCategoryResponseDTO(category.Children),
+	}
+}
+
+func ConvertCategoriesToCategoryResponseDTO(categories []models.Category) []dtos.CategoryResponseDTO {
+	categoryDTOs := make([]dtos.CategoryResponseDTO, len(categories))
+
+	// Convertir recursivamente las subcategorías
+	for i, category := range categories {
+		categoryDTOs[i] = ConvertCategoryToCategoryResponseDTO(category)
+	}
+
+	return categoryDTOs
+}
@@ -0,0 +1,37 @@
+package mappers
+
+import (
+	"order_management/internal/dtos"
+	"order_management/internal/ports"
+)
+
+// ConvertEventQueryDTOToFilter convierte los parámetros de la suscripción en el filtro de eventos
+func ConvertEventQueryDTOToFilter(queryDTO dtos.EventQueryDTO) ports.EventFilter {
+	return ports.EventFilter{
+		OrderID:      queryDTO.OrderID,
+		ProductID:    queryDTO.ProductID,
+		CustomerName: queryDTO.CustomerName,
+	}
+}
+
+// ConvertEventToDTO convierte un evento al formato enviado a los clientes
+func ConvertEventToDTO(event ports.Event) dtos.EventDTO {
+	eventDTO := dtos.EventDTO{
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+	}
+
+	if event.Order != nil {
+		order := ConvertOrderToOrderV2ResponseDTO(*event.Order)
+		eventDTO.Order = &order
+	}
+	if event.Stock != nil {
+		eventDTO.Stock = &dtos.StockChangeEventDTO{
+			ProductID: event.Stock.ProductID,
+			VariantID: event.Stock.VariantID,
+			Stock:     event.Stock.Stock,
+		}
+	}
+
+	return eventDTO
+}
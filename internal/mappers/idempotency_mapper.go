@@ -0,0 +1,24 @@
+package mappers
+
+import (
+	"order_management/internal/dtos"
+	"order_management/internal/ports"
+)
+
+func ConvertIdempotencyRecordToResponseDTO(scope, key string, record ports.IdempotencyRecord) dtos.IdempotencyKeyResponseDTO {
+	keyDTO := dtos.IdempotencyKeyResponseDTO{
+		Scope:       scope,
+		Key:         key,
+		Status:      record.Status,
+		Fingerprint: record.Fingerprint,
+		StatusCode:  record.StatusCode,
+		Headers:     record.Headers,
+		Response:    string(record.Response),
+	}
+	// Algunos stores no informan el vencimiento de las claves sin TTL
+	if !record.ExpiresAt.IsZero() {
+		expiresAt := record.ExpiresAt
+		keyDTO.ExpiresAt = &expiresAt
+	}
+	return keyDTO
+}
@@ -16,6 +16,7 @@ func ConvertOrderRequestDTOToOrder(orderRequestDTO dtos.OrderRequestDTO) models.
 	for i, item := range orderRequestDTO.Items {
 		order.OrderItems[i] = models.OrderItem{
 			ProductID: item.ProductID,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
 			Subtotal:  0, // Se calculará después
 		}
@@ -29,6 +30,7 @@ func ConvertOrderToOrderResponseDTO(order models.Order) dtos.OrderResponseDTO {
 		ID:           order.ID,
 		CustomerName: order.CustomerName,
 		TotalAmount:  order.TotalAmount,
+		Status:       order.Status,
 		Items:        make([]dtos.OrderItemResponseDTO, len(order.OrderItems)),
 	}
 
@@ -38,10 +40,66 @@ func ConvertOrderToOrderResponseDTO(order models.Order) dtos.OrderResponseDTO {
 			ID:          item.ID,
 			ProductID:   item.ProductID,
 			ProductName: item.Product.Name,
+			VariantID:   item.VariantID,
 			Quantity:    item.Quantity,
 			Subtotal:    item.Subtotal,
 		}
+		if item.Variant != nil {
+			orderDTO.Items[i].SKU = item.Variant.SKU
+		}
+	}
+
+	return orderDTO
+}
+
+// ConvertOrderToOrderV2ResponseDTO convierte la orden al formato de respuesta de la versión 2 de la API
+func ConvertOrderToOrderV2ResponseDTO(order models.Order) dtos.OrderV2ResponseDTO {
+	orderDTO := dtos.OrderV2ResponseDTO{
+		ID:        order.ID,
+		Customer:  dtos.OrderCustomerV2DTO{Name: order.CustomerName},
+		Items:     make([]dtos.OrderItemV2ResponseDTO, len(order.OrderItems)),
+		Total:     order.TotalAmount,
+		Status:    order.Status,
+		CreatedAt: order.CreatedAt,
+	}
+
+	// Convertir los items
+	for i, item := range order.OrderItems {
+		orderDTO.Items[i] = dtos.OrderItemV2ResponseDTO{
+			ID:       item.ID,
+			Product:  dtos.OrderProductV2DTO{ID: item.ProductID, Name: item.Product.Name},
+			Quantity: item.Quantity,
+			Subtotal: item.Subtotal,
+		}
+		if item.Quantity > 0 {
+			orderDTO.Items[i].UnitPrice = item.Subtotal / float64(item.Quantity)
+		}
+		if item.VariantID != nil {
+			orderDTO.Items[i].Variant = &dtos.OrderVariantV2DTO{ID: *item.VariantID}
+			if item.Variant != nil {
+				orderDTO.Items[i].Variant.SKU = item.Variant.SKU
+			}
+		}
 	}
 
 	return orderDTO
 }
+
+// ConvertBatchOrderResultsToResponseDTO arma la respuesta del lote contando los resultados exitosos y fallidos
+func ConvertBatchOrderResultsToResponseDTO(mode string, results []dtos.BatchOrderResultDTO) dtos.BatchOrderResponseDTO {
+	response := dtos.BatchOrderResponseDTO{
+		Mode:    mode,
+		Total:   len(results),
+		Results: results,
+	}
+
+	for _, result := range results {
+		if result.Status == dtos.BatchStatusFailed {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+
+	return response
+}
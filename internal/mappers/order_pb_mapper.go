@@ -0,0 +1,61 @@
+package mappers
+
+import (
+	"order_management/internal/dtos"
+	"order_management/internal/grpcapi/pb"
+	"order_management/internal/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ConvertCreateOrderRequestToOrderRequestDTO convierte la solicitud gRPC en el DTO que validan ambas interfaces
+func ConvertCreateOrderRequestToOrderRequestDTO(req *pb.CreateOrderRequest) dtos.OrderRequestDTO {
+	orderRequestDTO := dtos.OrderRequestDTO{
+		CustomerName: req.GetCustomerName(),
+		Items:        make([]dtos.OrderItemRequestDTO, len(req.GetItems())),
+	}
+
+	for i, item := range req.GetItems() {
+		orderRequestDTO.Items[i] = dtos.OrderItemRequestDTO{
+			ProductID: uint(item.GetProductId()),
+			Quantity:  int(item.GetQuantity()),
+		}
+		if item.VariantId != nil {
+			variantID := uint(item.GetVariantId())
+			orderRequestDTO.Items[i].VariantID = &variantID
+		}
+	}
+
+	return orderRequestDTO
+}
+
+// ConvertOrderToPB convierte la orden al mensaje de respuesta gRPC
+func ConvertOrderToPB(order models.Order) *pb.Order {
+	orderPB := &pb.Order{
+		Id:           uint64(order.ID),
+		CustomerName: order.CustomerName,
+		TotalAmount:  order.TotalAmount,
+		Items:        make([]*pb.OrderItem, len(order.OrderItems)),
+		CreatedAt:    timestamppb.New(order.CreatedAt),
+	}
+
+	// Convertir los items
+	for i, item := range order.OrderItems {
+		orderPB.Items[i] = &pb.OrderItem{
+			Id:          uint64(item.ID),
+			ProductId:   uint64(item.ProductID),
+			ProductName: item.Product.Name,
+			Quantity:    int32(item.Quantity),
+			Subtotal:    item.Subtotal,
+		}
+		if item.VariantID != nil {
+			variantID := uint64(*item.VariantID)
+			orderPB.Items[i].VariantId = &variantID
+		}
+		if item.Variant != nil {
+			orderPB.Items[i].Sku = item.Variant.SKU
+		}
+	}
+
+	return orderPB
+}
@@ -0,0 +1,229 @@
+package mappers
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+)
+
+// ProductCSVHeader define las columnas del CSV de importación y exportación de productos
+var ProductCSVHeader = []string{"product_id", "name", "price", "stock", "category_id", "sku", "attributes"}
+
+// ParseProductImportCSV lee el CSV de importación. Los errores de formato de cada fila se
+// acumulan en ProductImportRow.Errors; solo se devuelve error, con el código INVALID_CSV, si el
+// archivo no puede leerse.
+func ParseProductImportCSV(r io.Reader) ([]ports.ProductImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, apperrors.Invalid(apperrors.CodeInvalidCSV, "el archivo CSV está vacío")
+	}
+	if err != nil {
+		return nil, csvLineError(1)
+	}
+
+	// Ubicar cada columna conocida dentro de la cabecera
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !isProductCSVColumn(name) {
+			return nil, apperrors.Invalidf(apperrors.CodeInvalidCSV, "columna desconocida en el CSV: %q", name).WithDetail("column", name)
+		}
+		columns[name] = i
+	}
+
+	var rows []ports.ProductImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, csvLineError(line)
+		}
+		rows = append(rows, parseProductImportRecord(line, record, columns))
+	}
+
+	return rows, nil
+}
+
+// csvLineError informa una línea del CSV que no pudo leerse, por ejemplo por comillas sin cerrar
+func csvLineError(line int) error {
+	return apperrors.Invalidf(apperrors.CodeInvalidCSV, "error al leer la línea %d del CSV", line).WithDetail("line", line)
+}
+
+func isProductCSVColumn(name string) bool {
+	for _, column := range ProductCSVHeader {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProductImportRecord convierte un registro del CSV en una fila de importación
+func parseProductImportRecord(line int, record []string, columns map[string]int) ports.ProductImportRow {
+	row := ports.ProductImportRow{Line: line}
+
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	if value := field("product_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 0)
+		if err != nil || id == 0 {
+			row.Errors = append(row.Errors, "product_id debe ser un entero positivo")
+		}
+		row.ProductID = uint(id)
+	}
+
+	row.Name = unescapeCSVCell(field("name"))
+	row.SKU = unescapeCSVCell(field("sku"))
+
+	if value := field("price"); value != "" {
+		price, err := strconv.ParseFloat(value, 64)
+		// ParseFloat acepta NaN e Inf, que la columna decimal de la base de datos no admite
+		if err != nil || price < 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+			row.Errors = append(row.Errors, "price debe ser un número mayor o igual a 0")
+		}
+		row.Price = &price
+	}
+
+	if value := field("stock"); value != "" {
+		stock, err := strconv.Atoi(value)
+		if err != nil || stock < 0 {
+			row.Errors = append(row.Errors, "stock debe ser un entero mayor o igual a 0")
+		}
+		row.Stock = &stock
+	}
+
+	if value := field("category_id"); value != "" {
+		categoryID, err := strconv.ParseUint(value, 10, 0)
+		if err != nil || categoryID == 0 {
+			row.Errors = append(row.Errors, "category_id debe ser un entero positivo")
+		}
+		id := uint(categoryID)
+		row.CategoryID = &id
+	}
+
+	if value := unescapeCSVCell(field("attributes")); value != "" {
+		attributes, ok := parseVariantAttributes(value)
+		if !ok {
+			row.Errors = append(row.Errors, "attributes debe tener el formato clave=valor;clave=valor")
+		}
+		row.Attributes = attributes
+	}
+
+	if len(row.SKU) > 64 {
+		row.Errors = append(row.Errors, "sku no puede superar los 64 caracteres")
+	}
+
+	return row
+}
+
+// csvFormulaPrefixes son los caracteres con los que una planilla de cálculo empieza una fórmula, más
+// el apóstrofo con que se escapan
+const csvFormulaPrefixes = "=+-@\t\r'"
+
+// escapeCSVCell antepone un apóstrofo a los textos que una planilla de cálculo interpretaría como
+// una fórmula, y a los que ya empiezan con un apóstrofo para que unescapeCSVCell no lo quite
+func escapeCSVCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVCell quita el apóstrofo que agrega escapeCSVCell, de modo que lo exportado se
+// reimporte con el mismo valor
+func unescapeCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// parseVariantAttributes interpreta atributos con el formato "clave=valor;clave=valor" e indica si
+// todos los pares tienen ese formato
+func parseVariantAttributes(value string) (map[string]string, bool) {
+	attributes := make(map[string]string)
+	for _, pair := range strings.Split(value, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, val, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, false
+		}
+		attributes[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return attributes, true
+}
+
+// formatVariantAttributes serializa los atributos ordenados por clave
+func formatVariantAttributes(attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + attributes[key]
+	}
+	return strings.Join(pairs, ";")
+}
+
+// ConvertProductToCSVRecords genera la fila del producto seguida de una fila por cada variante. Los
+// textos se escapan con escapeCSVCell para que no se ejecuten como fórmulas al abrir el CSV.
+func ConvertProductToCSVRecords(product models.Product) [][]string {
+	categoryID := ""
+	if product.CategoryID != nil {
+		categoryID = strconv.FormatUint(uint64(*product.CategoryID), 10)
+	}
+
+	productID := strconv.FormatUint(uint64(product.ID), 10)
+	name := escapeCSVCell(product.Name)
+	records := [][]string{{
+		productID,
+		name,
+		strconv.FormatFloat(product.Price, 'f', 2, 64),
+		strconv.Itoa(product.Stock),
+		categoryID,
+		"",
+		"",
+	}}
+
+	for _, variant := range product.Variants {
+		price := ""
+		if variant.Price != nil {
+			price = strconv.FormatFloat(*variant.Price, 'f', 2, 64)
+		}
+		records = append(records, []string{
+			productID,
+			name,
+			price,
+			strconv.Itoa(variant.Stock),
+			"",
+			escapeCSVCell(variant.SKU),
+			escapeCSVCell(formatVariantAttributes(variant.Attributes)),
+		})
+	}
+
+	return records
+}
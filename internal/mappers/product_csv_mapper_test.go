@@ -0,0 +1,212 @@
+package mappers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProductImportCSV_ParsesProductsAndVariants(t *testing.T) {
+	rows, err := ParseProductImportCSV(strings.NewReader("\ufeffProduct_ID, name,price,stock,category_id,sku,attributes\n" +
+		",Camisa,20.5,10,3,,\n" +
+		"7,Camisa,25,4,,CAM-M, talla = M ;color=azul;\n"))
+
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		price, stock, categoryID := 20.5, 10, uint(3)
+		assert.Equal(t, ports.ProductImportRow{Line: 2, Name: "Camisa", Price: &price, Stock: &stock, CategoryID: &categoryID}, rows[0])
+
+		variantPrice, variantStock := 25.0, 4
+		assert.Equal(t, ports.ProductImportRow{
+			Line:       3,
+			ProductID:  7,
+			Name:       "Camisa",
+			Price:      &variantPrice,
+			Stock:      &variantStock,
+			SKU:        "CAM-M",
+			Attributes: map[string]string{"talla": "M", "color": "azul"},
+		}, rows[1])
+	}
+}
+
+func TestParseProductImportCSV_ColumnsAreOptional(t *testing.T) {
+	rows, err := ParseProductImportCSV(strings.NewReader("stock,product_id\n5,1\n8\n"))
+
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, uint(1), rows[0].ProductID)
+		assert.Equal(t, 5, *rows[0].Stock)
+		assert.Nil(t, rows[0].Price)
+		// Las columnas que faltan en una fila se tratan como vacías
+		assert.Equal(t, uint(0), rows[1].ProductID)
+		assert.Empty(t, rows[1].Errors)
+	}
+}
+
+func TestParseProductImportCSV_RejectsBadHeader(t *testing.T) {
+	for name, content := range map[string]string{
+		"vacío":               "",
+		"columna":             "name,precio\nLaptop,10\n",
+		"comillas sin cerrar": "name,\"price\n",
+	} {
+		rows, err := ParseProductImportCSV(strings.NewReader(content))
+
+		assert.True(t, apperrors.IsCode(err, apperrors.CodeInvalidCSV), name)
+		assert.Nil(t, rows, name)
+	}
+}
+
+func TestParseProductImportCSV_RowErrors(t *testing.T) {
+	rows, err := ParseProductImportCSV(strings.NewReader("product_id,price,stock,category_id,sku,attributes\n" +
+		"abc,diez,-1,0,,\n" +
+		"0,-5,1.5,x,,\n" +
+		"1,10,1,,CAM-L,talla\n" +
+		"1,10,1,," + strings.Repeat("S", 65) + ",\n" +
+		"1,NaN,1,,,\n" +
+		"1,Inf,1,,,\n" +
+		"1,+Inf,1,,,\n"))
+
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 7) {
+		assert.ElementsMatch(t, []string{
+			"product_id debe ser un entero positivo",
+			"price debe ser un número mayor o igual a 0",
+			"stock debe ser un entero mayor o igual a 0",
+			"category_id debe ser un entero positivo",
+		}, rows[0].Errors)
+		assert.ElementsMatch(t, []string{
+			"product_id debe ser un entero positivo",
+			"price debe ser un número mayor o igual a 0",
+			"stock debe ser un entero mayor o igual a 0",
+			"category_id debe ser un entero positivo",
+		}, rows[1].Errors)
+		assert.Equal(t, []string{"attributes debe tener el formato clave=valor;clave=valor"}, rows[2].Errors)
+		assert.Equal(t, []string{"sku no puede superar los 64 caracteres"}, rows[3].Errors)
+		// Los precios no finitos se rechazan como cualquier otro precio inválido
+		for _, row := range rows[4:] {
+			assert.Equal(t, []string{"price debe ser un número mayor o igual a 0"}, row.Errors, row.Line)
+		}
+	}
+}
+
+func TestParseProductImportCSV_BadLine(t *testing.T) {
+	_, err := ParseProductImportCSV(strings.NewReader("name,price\nLaptop,10\n\"Mouse,5\n"))
+
+	appErr, ok := apperrors.As(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.CodeInvalidCSV, appErr.Code)
+		assert.Equal(t, 3, appErr.Details["line"])
+		// El mensaje se traduce como el resto de los errores
+		assert.Equal(t, "error reading line 3 of the CSV", appErr.Localize("en"))
+	}
+}
+
+// TestProductCSV_RoundTrip verifica que lo exportado vuelva a importarse con los mismos valores
+func TestProductCSV_RoundTrip(t *testing.T) {
+	categoryID := uint(3)
+	variantPrice := 25.0
+	product := models.Product{
+		ID:         7,
+		Name:       "Camisa, manga larga",
+		Price:      20.5,
+		Stock:      10,
+		CategoryID: &categoryID,
+		Variants: []models.ProductVariant{
+			{SKU: "CAM-M", Price: &variantPrice, Stock: 4, Attributes: map[string]string{"talla": "M", "color": "azul"}},
+			{SKU: "CAM-L", Stock: 2},
+		},
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	assert.NoError(t, writer.Write(ProductCSVHeader))
+	assert.NoError(t, writer.WriteAll(ConvertProductToCSVRecords(product)))
+
+	rows, err := ParseProductImportCSV(&buffer)
+
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 3) {
+		for _, row := range rows {
+			assert.Empty(t, row.Errors)
+			assert.Equal(t, product.ID, row.ProductID)
+			assert.Equal(t, product.Name, row.Name)
+		}
+		assert.Equal(t, product.Price, *rows[0].Price)
+		assert.Equal(t, product.Stock, *rows[0].Stock)
+		assert.Equal(t, categoryID, *rows[0].CategoryID)
+		assert.Empty(t, rows[0].SKU)
+
+		assert.Equal(t, "CAM-M", rows[1].SKU)
+		assert.Equal(t, variantPrice, *rows[1].Price)
+		assert.Equal(t, 4, *rows[1].Stock)
+		assert.Equal(t, map[string]string{"talla": "M", "color": "azul"}, rows[1].Attributes)
+
+		// Una variante sin precio propio no cambia el precio al reimportarse
+		assert.Equal(t, "CAM-L", rows[2].SKU)
+		assert.Nil(t, rows[2].Price)
+		assert.Nil(t, rows[2].Attributes)
+	}
+}
+
+// TestProductCSV_EscapesFormulas verifica que los textos que una planilla ejecutaría como fórmulas se
+// exporten con un apóstrofo y se reimporten sin él
+func TestProductCSV_EscapesFormulas(t *testing.T) {
+	product := models.Product{
+		ID:    7,
+		Name:  "=HYPERLINK(\"http://evil\")",
+		Price: 20.5,
+		Variants: []models.ProductVariant{
+			{SKU: "@SUM(A1)", Stock: 4, Attributes: map[string]string{"+talla": "-M"}},
+			{SKU: "'CAM-L", Stock: 2, Attributes: map[string]string{"color": "=azul"}},
+		},
+	}
+
+	records := ConvertProductToCSVRecords(product)
+
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, "'=HYPERLINK(\"http://evil\")", records[0][1])
+		assert.Equal(t, "'@SUM(A1)", records[1][5])
+		assert.Equal(t, "'+talla=-M", records[1][6])
+		// Un apóstrofo propio también se escapa para no perderse al reimportar
+		assert.Equal(t, "''CAM-L", records[2][5])
+		// Solo importa el comienzo de la celda
+		assert.Equal(t, "color==azul", records[2][6])
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	assert.NoError(t, writer.Write(ProductCSVHeader))
+	assert.NoError(t, writer.WriteAll(records))
+
+	rows, err := ParseProductImportCSV(&buffer)
+
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 3) {
+		for _, row := range rows {
+			assert.Empty(t, row.Errors)
+			assert.Equal(t, product.Name, row.Name)
+		}
+		assert.Equal(t, "@SUM(A1)", rows[1].SKU)
+		assert.Equal(t, map[string]string{"+talla": "-M"}, rows[1].Attributes)
+		assert.Equal(t, "'CAM-L", rows[2].SKU)
+		assert.Equal(t, map[string]string{"color": "=azul"}, rows[2].Attributes)
+	}
+}
+
+// TestParseProductImportCSV_KeepsApostrophes verifica que un apóstrofo que no escapa una fórmula se conserve
+func TestParseProductImportCSV_KeepsApostrophes(t *testing.T) {
+	rows, err := ParseProductImportCSV(strings.NewReader("name\n'90s\n'\n"))
+
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, "'90s", rows[0].Name)
+		assert.Equal(t, "'", rows[1].Name)
+	}
+}
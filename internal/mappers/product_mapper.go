@@ -1,21 +1,125 @@
 package mappers
 
 import (
+	"strings"
+
 	"order_management/internal/dtos"
 	"order_management/internal/models"
+	"order_management/internal/ports"
+)
+
+const (
+	DefaultPageSize = 20
 )
 
 func ConvertProductToProductResponseDTO(products []models.Product) []dtos.ProductResponseDTO {
-	var productResponseDTOs []dtos.ProductResponseDTO
+	productResponseDTOs := make([]dtos.ProductResponseDTO, 0, len(products))
 
 	for _, product := range products {
 		productResponseDTOs = append(productResponseDTOs, dtos.ProductResponseDTO{
-			ID:    product.ID,
-			Name:  product.Name,
-			Price: product.Price,
-			Stock: product.Stock,
+			ID:         product.ID,
+			Name:       product.Name,
+			Price:      product.Price,
+			Stock:      product.Stock,
+			CategoryID: product.CategoryID,
+			Variants:   convertProductVariantsToResponseDTO(product),
 		})
 	}
 
 	return productResponseDTOs
 }
+
+// convertProductVariantsToResponseDTO convierte las variantes resolviendo el precio efectivo de cada una
+func convertProductVariantsToResponseDTO(product models.Product) []dtos.ProductVariantResponseDTO {
+	variantDTOs := make([]dtos.ProductVariantResponseDTO, len(product.Variants))
+
+	for i, variant := range product.Variants {
+		variantDTOs[i] = dtos.ProductVariantResponseDTO{
+			ID:         variant.ID,
+			SKU:        variant.SKU,
+			Attributes: variant.Attributes,
+			Price:      variant.EffectivePrice(product.Price),
+			Stock:      variant.Stock,
+		}
+	}
+
+	return variantDTOs
+}
+
+// ConvertProductListQueryDTOToFilter convierte los parámetros de consulta en un filtro aplicando los valores por defecto
+func ConvertProductListQueryDTOToFilter(queryDTO dtos.ProductListQueryDTO) ports.ProductFilter {
+	filter := ports.ProductFilter{
+		Query:      strings.TrimSpace(queryDTO.Query),
+		CategoryID: queryDTO.CategoryID,
+		MinPrice:   queryDTO.MinPrice,
+		MaxPrice:   queryDTO.MaxPrice,
+		InStock:    queryDTO.InStock,
+		SortBy:     strings.TrimPrefix(queryDTO.Sort, "-"),
+		SortDesc:   strings.HasPrefix(queryDTO.Sort, "-"),
+		Page:       queryDTO.Page,
+		PageSize:   queryDTO.PageSize,
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = DefaultPageSize
+	}
+
+	return filter
+}
+
+// ConvertProductPageToResponseDTO arma la respuesta paginada del catálogo
+func ConvertProductPageToResponseDTO(products []models.Product, total int64, filter ports.ProductFilter) dtos.ProductPageResponseDTO {
+	totalPages := int((total + int64(filter.PageSize) - 1) / int64(filter.PageSize))
+
+	return dtos.ProductPageResponseDTO{
+		Items:      ConvertProductToProductResponseDTO(products),
+		Total:      total,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		TotalPages: totalPages,
+	}
+}
+
+// ConvertProductImportReportToResponseDTO resume el resultado de la importación. Los IDs de los
+// registros creados solo se informan cuando los cambios se confirmaron.
+func ConvertProductImportReportToResponseDTO(report ports.ProductImportReport, dryRun bool) dtos.ProductImportReportDTO {
+	reportDTO := dtos.ProductImportReportDTO{
+		DryRun:    dryRun,
+		Applied:   report.Applied,
+		TotalRows: len(report.Results),
+		Rows:      make([]dtos.ProductImportRowResultDTO, len(report.Results)),
+	}
+
+	for i, result := range report.Results {
+		rowDTO := dtos.ProductImportRowResultDTO{
+			Line:      result.Line,
+			Action:    result.Action,
+			ProductID: result.ProductID,
+			VariantID: result.VariantID,
+			SKU:       result.SKU,
+			Errors:    result.Errors,
+		}
+
+		switch result.Action {
+		case ports.ImportActionCreate:
+			reportDTO.Created++
+			if !report.Applied {
+				rowDTO.VariantID = 0
+				if result.SKU == "" {
+					rowDTO.ProductID = 0
+				}
+			}
+		case ports.ImportActionUpdate:
+			reportDTO.Updated++
+		case ports.ImportActionError:
+			reportDTO.Failed++
+		}
+
+		reportDTO.Rows[i] = rowDTO
+	}
+
+	return reportDTO
+}
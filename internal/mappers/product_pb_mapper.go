@@ -0,0 +1,99 @@
+package mappers
+
+import (
+	"order_management/internal/dtos"
+	"order_management/internal/grpcapi/pb"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+)
+
+// ConvertSearchProductsRequestToQueryDTO convierte la solicitud gRPC en los parámetros de consulta de la API REST
+func ConvertSearchProductsRequestToQueryDTO(req *pb.SearchProductsRequest) dtos.ProductListQueryDTO {
+	queryDTO := dtos.ProductListQueryDTO{
+		Query:    req.GetQuery(),
+		MinPrice: req.MinPrice,
+		MaxPrice: req.MaxPrice,
+		InStock:  req.GetInStock(),
+		Sort:     req.GetSort(),
+		Page:     int(req.GetPage()),
+		PageSize: int(req.GetPageSize()),
+	}
+	if req.CategoryId != nil {
+		categoryID := uint(req.GetCategoryId())
+		queryDTO.CategoryID = &categoryID
+	}
+
+	return queryDTO
+}
+
+// ConvertProductPageToPB arma la respuesta paginada del catálogo para gRPC
+func ConvertProductPageToPB(products []models.Product, total int64, filter ports.ProductFilter) *pb.SearchProductsResponse {
+	page := ConvertProductPageToResponseDTO(products, total, filter)
+
+	response := &pb.SearchProductsResponse{
+		Items:      make([]*pb.Product, len(products)),
+		Total:      page.Total,
+		Page:       int32(page.Page),
+		PageSize:   int32(page.PageSize),
+		TotalPages: int32(page.TotalPages),
+	}
+	for i, product := range products {
+		response.Items[i] = ConvertProductToPB(product)
+	}
+
+	return response
+}
+
+// ConvertProductToPB convierte el producto y sus variantes, resolviendo el precio efectivo de cada una
+func ConvertProductToPB(product models.Product) *pb.Product {
+	productPB := &pb.Product{
+		Id:       uint64(product.ID),
+		Name:     product.Name,
+		Price:    product.Price,
+		Stock:    int32(product.Stock),
+		Variants: make([]*pb.ProductVariant, len(product.Variants)),
+	}
+	if product.CategoryID != nil {
+		categoryID := uint64(*product.CategoryID)
+		productPB.CategoryId = &categoryID
+	}
+
+	for i, variant := range product.Variants {
+		productPB.Variants[i] = &pb.ProductVariant{
+			Id:         uint64(variant.ID),
+			Sku:        variant.SKU,
+			Attributes: variant.Attributes,
+			Price:      variant.EffectivePrice(product.Price),
+			Stock:      int32(variant.Stock),
+		}
+	}
+
+	return productPB
+}
+
+// ConvertProductImportReportToPB convierte el reporte de la importación a la respuesta gRPC
+func ConvertProductImportReportToPB(report ports.ProductImportReport, dryRun bool) *pb.ImportProductsResponse {
+	reportDTO := ConvertProductImportReportToResponseDTO(report, dryRun)
+
+	response := &pb.ImportProductsResponse{
+		DryRun:    reportDTO.DryRun,
+		Applied:   reportDTO.Applied,
+		TotalRows: int32(reportDTO.TotalRows),
+		Created:   int32(reportDTO.Created),
+		Updated:   int32(reportDTO.Updated),
+		Failed:    int32(reportDTO.Failed),
+		Rows:      make([]*pb.ImportProductsRowResult, len(reportDTO.Rows)),
+	}
+	for i, row := range reportDTO.Rows {
+		response.Rows[i] = &pb.ImportProductsRowResult{
+			Line:      int32(row.Line),
+			Action:    row.Action,
+			ProductId: uint64(row.ProductID),
+			VariantId: uint64(row.VariantID),
+			Sku:       row.SKU,
+			Errors:    row.Errors,
+		}
+	}
+
+	return response
+}
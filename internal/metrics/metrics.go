@@ -0,0 +1,150 @@
+// Package metrics expone las métricas de la aplicación en formato Prometheus: la duración de las
+// solicitudes HTTP, las métricas de negocio de las órdenes, el uso de las claves de idempotencia, los
+// pools de conexiones de MySQL y Redis y las métricas del runtime de Go y del proceso.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace es el prefijo de las métricas propias de la aplicación
+const Namespace = "order_management"
+
+var (
+	// HTTPDurationBuckets cubre desde las lecturas simples hasta las importaciones masivas
+	HTTPDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+	// OrderValueBuckets agrupa el importe total de las órdenes creadas
+	OrderValueBuckets = []float64{10, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+)
+
+// Metrics agrupa las métricas de la aplicación en un registro propio, de modo que /metrics solo
+// publica lo que se registra aquí. Implementa OrderMetrics, IdempotencyMetrics y HTTPMetrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration *prometheus.HistogramVec
+
+	ordersCreated prometheus.Counter
+	orderFailures *prometheus.CounterVec
+	orderValue    prometheus.Histogram
+
+	idempotencyMisses    prometheus.Counter
+	idempotencyHits      prometheus.Counter
+	idempotencyReplays   prometheus.Counter
+	idempotencyConflicts *prometheus.CounterVec
+}
+
+// New crea las métricas con las del runtime de Go y del proceso ya registradas
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duración de las solicitudes HTTP por método, ruta y código de respuesta.",
+			Buckets:   HTTPDurationBuckets,
+		}, []string{"method", "route", "status"}),
+		ordersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "orders_created_total",
+			Help:      "Órdenes creadas.",
+		}),
+		orderFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "order_failures_total",
+			Help:      "Órdenes que no se crearon, por motivo.",
+		}, []string{"reason"}),
+		orderValue: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "order_value",
+			Help:      "Importe total de las órdenes creadas.",
+			Buckets:   OrderValueBuckets,
+		}),
+		idempotencyMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "idempotency_misses_total",
+			Help:      "Solicitudes con una clave de idempotencia nueva.",
+		}),
+		idempotencyHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "idempotency_hits_total",
+			Help:      "Solicitudes con una clave de idempotencia que ya existía.",
+		}),
+		idempotencyReplays: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "idempotency_replays_total",
+			Help:      "Respuestas repetidas desde una clave de idempotencia completada.",
+		}),
+		idempotencyConflicts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "idempotency_conflicts_total",
+			Help:      "Solicitudes rechazadas por una clave de idempotencia en curso o usada con otra solicitud.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.ordersCreated,
+		m.orderFailures,
+		m.orderValue,
+		m.idempotencyMisses,
+		m.idempotencyHits,
+		m.idempotencyReplays,
+		m.idempotencyConflicts,
+	)
+	return m
+}
+
+// RegisterDB publica las estadísticas del pool de conexiones con MySQL
+func (m *Metrics) RegisterDB(db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, "mysql"))
+}
+
+// RegisterRedis publica las estadísticas del pool de conexiones con Redis
+func (m *Metrics) RegisterRedis(client *redis.Client) {
+	m.registry.MustRegister(newRedisPoolCollector(client))
+}
+
+// Handler devuelve el handler HTTP que publica las métricas
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func (m *Metrics) OrderCreated(totalAmount float64) {
+	m.ordersCreated.Inc()
+	m.orderValue.Observe(totalAmount)
+}
+
+func (m *Metrics) OrderFailed(reason string) {
+	m.orderFailures.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) IdempotencyMiss() {
+	m.idempotencyMisses.Inc()
+}
+
+func (m *Metrics) IdempotencyHit() {
+	m.idempotencyHits.Inc()
+}
+
+func (m *Metrics) IdempotencyReplay() {
+	m.idempotencyReplays.Inc()
+}
+
+func (m *Metrics) IdempotencyConflict(reason string) {
+	m.idempotencyConflicts.WithLabelValues(reason).Inc()
+}
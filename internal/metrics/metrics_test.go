@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"order_management/internal/ports"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// scrape consulta el handler de métricas y devuelve el texto publicado
+func scrape(t *testing.T, m *Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_Orders(t *testing.T) {
+	m := New()
+
+	m.OrderCreated(120)
+	m.OrderCreated(4000)
+	m.OrderFailed(ports.OrderFailureStock)
+	m.OrderFailed(ports.OrderFailureStock)
+	m.OrderFailed(ports.OrderFailureDatabase)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.ordersCreated))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.orderFailures.WithLabelValues(ports.OrderFailureStock)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.orderFailures.WithLabelValues(ports.OrderFailureDatabase)))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, "order_management_order_value_sum 4120")
+	assert.Contains(t, body, "order_management_order_value_count 2")
+	assert.Contains(t, body, `order_management_order_value_bucket{le="250"} 1`)
+}
+
+func TestMetrics_Idempotency(t *testing.T) {
+	m := New()
+
+	m.IdempotencyMiss()
+	m.IdempotencyHit()
+	m.IdempotencyReplay()
+	m.IdempotencyHit()
+	m.IdempotencyConflict(ports.IdempotencyConflictInProgress)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.idempotencyMisses))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.idempotencyHits))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.idempotencyReplays))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.idempotencyConflicts.WithLabelValues(ports.IdempotencyConflictInProgress)))
+}
+
+func TestMetrics_HTTPRequest(t *testing.T) {
+	m := New()
+
+	m.ObserveHTTPRequest(http.MethodPost, "/api/orders", http.StatusCreated, 30*time.Millisecond)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `order_management_http_request_duration_seconds_bucket{method="POST",route="/api/orders",status="201",le="0.05"} 1`)
+	assert.Contains(t, body, `order_management_http_request_duration_seconds_bucket{method="POST",route="/api/orders",status="201",le="0.025"} 0`)
+}
+
+func TestMetrics_ConnectionPools(t *testing.T) {
+	m := New()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	m.RegisterDB(sqlDB)
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+	assert.NoError(t, redisClient.Ping(context.Background()).Err())
+	m.RegisterRedis(redisClient)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="mysql"}`)
+	assert.Contains(t, body, "order_management_redis_pool_connections 1")
+	assert.Contains(t, body, "order_management_redis_pool_misses_total 1")
+	// Las métricas del runtime también se publican
+	assert.Contains(t, body, "go_goroutines")
+}
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"order_management/internal/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ClientAuthMiddleware identifica al cliente por la clave de la cabecera Authorization y deja su
+// identificador en ContextKeyClientID y en el contexto de la solicitud. Las solicitudes sin clave o
+// con una clave desconocida continúan sin identificar.
+func ClientAuthMiddleware(clients *auth.Clients) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if clientID, ok := clients.Identify(c.Request().Header.Get(echo.HeaderAuthorization)); ok {
+				c.Set(ContextKeyClientID, clientID)
+				c.SetRequest(c.Request().WithContext(auth.WithClientID(c.Request().Context(), clientID)))
+			}
+			return next(c)
+		}
+	}
+}
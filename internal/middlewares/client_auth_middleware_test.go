@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"order_management/internal/auth"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveClientAuth ejecuta el middleware con la cabecera Authorization indicada y devuelve el ámbito de
+// las claves de idempotencia y el cliente del contexto de la solicitud
+func serveClientAuth(t *testing.T, clients *auth.Clients, authorization string) (string, string) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", nil)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	var scope, clientID string
+	handler := ClientAuthMiddleware(clients)(func(c echo.Context) error {
+		scope = ClientScope(c)
+		clientID = auth.ClientIDFromContext(c.Request().Context())
+		return nil
+	})
+
+	assert.NoError(t, handler(c))
+	return scope, clientID
+}
+
+func TestClientAuthMiddleware_ScopesByClientAcrossKeyRotation(t *testing.T) {
+	clients, err := auth.ParseClients("tienda:clave-nueva,tienda:clave-anterior")
+	assert.NoError(t, err)
+
+	oldScope, clientID := serveClientAuth(t, clients, "Bearer clave-anterior")
+	newScope, _ := serveClientAuth(t, clients, "Bearer clave-nueva")
+
+	assert.Equal(t, "tienda", clientID)
+	assert.Equal(t, "tienda", oldScope)
+	assert.Equal(t, oldScope, newScope)
+}
+
+func TestClientAuthMiddleware_UnknownClients(t *testing.T) {
+	clients, err := auth.ParseClients("tienda:clave")
+	assert.NoError(t, err)
+
+	// Las claves desconocidas usan un hash de la cabecera y las solicitudes sin credenciales el ámbito anónimo
+	scope, clientID := serveClientAuth(t, clients, "Bearer otra")
+	assert.Empty(t, clientID)
+	assert.Len(t, scope, 64)
+
+	scope, _ = serveClientAuth(t, clients, "")
+	assert.Equal(t, AnonymousClient, scope)
+}
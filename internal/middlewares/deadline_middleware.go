@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DeadlineMiddleware limita el tiempo de procesamiento de la solicitud. Al vencer timeout se cancela
+// el contexto de la solicitud, y con él las consultas, la transacción en curso y los comandos de
+// Redis que lo usan; el error resultante se responde con 504. La desconexión del cliente cancela el
+// mismo contexto aunque no haya vencido.
+func DeadlineMiddleware(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
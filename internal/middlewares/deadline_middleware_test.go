@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"order_management/internal/logging"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadlineMiddleware_CancelsSlowHandler(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api/orders", nil), httptest.NewRecorder())
+
+	var handlerCtx context.Context
+	handler := DeadlineMiddleware(20 * time.Millisecond)(func(c echo.Context) error {
+		handlerCtx = c.Request().Context()
+		select {
+		case <-handlerCtx.Done():
+			return handlerCtx.Err()
+		case <-time.After(time.Second):
+			return c.NoContent(http.StatusCreated)
+		}
+	})
+
+	err := handler(c)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, ok := handlerCtx.Deadline()
+	assert.True(t, ok)
+}
+
+func TestDeadlineMiddleware_KeepsRequestContext(t *testing.T) {
+	e := echo.New()
+	parent, cancel := context.WithCancel(logging.WithRequestID(context.Background(), "req-1"))
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/1", nil).WithContext(parent)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	var handlerCtx context.Context
+	handler := DeadlineMiddleware(time.Minute)(func(c echo.Context) error {
+		handlerCtx = c.Request().Context()
+		// La desconexión del cliente cancela la solicitud antes del tiempo máximo
+		cancel()
+		return handlerCtx.Err()
+	})
+
+	err := handler(c)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "req-1", logging.RequestIDFromContext(handlerCtx))
+	// Al terminar la solicitud se liberan los recursos del contexto
+	assert.Error(t, handlerCtx.Err())
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderLink        = "Link"
+)
+
+// DeprecationMiddleware marca como obsoletas las respuestas de una versión de la API. Agrega la
+// cabecera Deprecation y un Link con rel="successor-version" que apunta a la misma ruta bajo
+// successorPrefix, para que los clientes puedan migrar ruta por ruta.
+func DeprecationMiddleware(prefix string, successorPrefix string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			successor := successorPrefix + strings.TrimPrefix(c.Request().URL.Path, prefix)
+
+			header := c.Response().Header()
+			header.Set(HeaderDeprecation, "true")
+			header.Add(HeaderLink, "<"+successor+`>; rel="successor-version"`)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeprecationMiddleware_SetsHeaders verifica que la respuesta indique la ruta equivalente en la versión sucesora
+func TestDeprecationMiddleware_SetsHeaders(t *testing.T) {
+	e := echo.New()
+	e.Group("/api/v1", DeprecationMiddleware("/api/v1", "/api/v2")).GET("/orders/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/orders/7", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(HeaderDeprecation))
+	assert.Equal(t, `</api/v2/orders/7>; rel="successor-version"`, rec.Header().Get(HeaderLink))
+}
+
+// TestDeprecationMiddleware_SetsHeadersOnErrors verifica que las respuestas de error también se marquen como obsoletas
+func TestDeprecationMiddleware_SetsHeadersOnErrors(t *testing.T) {
+	e := echo.New()
+	e.Group("/api", DeprecationMiddleware("/api", "/api/v2")).GET("/orders/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/orders/7", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(HeaderDeprecation))
+	assert.Equal(t, `</api/v2/orders/7>; rel="successor-version"`, rec.Header().Get(HeaderLink))
+}
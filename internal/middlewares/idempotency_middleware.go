@@ -3,63 +3,183 @@ package middlewares
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/go-redis/redis/v8"
+	"order_management/internal/apperrors"
+	"order_management/internal/auth"
+	"order_management/internal/ports"
+
 	"github.com/labstack/echo/v4"
 )
 
 const (
-	KeyPrefix        = "idempotency:"
-	StatusInProgress = "IN_PROGRESS"
-	StatusCompleted  = "COMPLETED"
-	TTL              = 10 * time.Minute
+	// DefaultLockLease es la duración de la reserva de una clave en curso. Mientras el handler se ejecuta
+	// la reserva se renueva; si la instancia se cae, la clave queda libre al vencer la reserva y un
+	// reintento puede tomarla, en lugar de quedar bloqueada durante todo el TTL.
+	DefaultLockLease = 15 * time.Second
+
+	// MaxIdempotencyKeyLength es la longitud máxima de la clave que envía el cliente. Con el ámbito del
+	// cliente y, en gRPC, el nombre del método, la clave guardada cabe en la columna idempotency_key
+	// del store SQL.
+	MaxIdempotencyKeyLength = 128
+
+	// ContextKeyClientID es la clave del contexto de Echo donde la autenticación deja el identificador del cliente
+	ContextKeyClientID = "client_id"
+	// AnonymousClient es el ámbito de las claves de las solicitudes sin credenciales
+	AnonymousClient = auth.AnonymousClientID
+	// ContextKeyIdempotencyLock es la clave del contexto de Echo donde el middleware deja la reserva de la clave en curso
+	ContextKeyIdempotencyLock = "idempotency_lock"
+)
+
+// ReplayAction indica qué hacer con la clave cuando termina la solicitud
+type ReplayAction int
+
+const (
+	// ReplayCache guarda la respuesta para repetirla en los reintentos
+	ReplayCache ReplayAction = iota
+	// ReplayRelease libera la clave para que un reintento vuelva a ejecutar la solicitud
+	ReplayRelease
 )
 
-type IdempotencyData struct {
-	Status   string      `json:"status"`
-	Response interface{} `json:"response"`
+// IdempotencyPolicy asocia cada clase de código HTTP (2 para 2xx, 4 para 4xx, etc.) con la acción a
+// aplicar. Las clases que no figuran liberan la clave.
+type IdempotencyPolicy map[int]ReplayAction
+
+// DefaultIdempotencyPolicy repite las respuestas exitosas y libera la clave ante errores, que pueden
+// deberse a una condición transitoria (stock, conflictos, fallos internos)
+var DefaultIdempotencyPolicy = IdempotencyPolicy{
+	2: ReplayCache,
+	3: ReplayCache,
+	4: ReplayRelease,
+	5: ReplayRelease,
+}
+
+// Action devuelve la acción que corresponde al código HTTP
+func (p IdempotencyPolicy) Action(statusCode int) ReplayAction {
+	if action, ok := p[statusCode/100]; ok {
+		return action
+	}
+	return ReplayRelease
 }
 
-// IdempotencyMiddleware contiene la lógica de idempotencia al crear una orden
-func IdempotencyMiddleware(redisClient *redis.Client) echo.MiddlewareFunc {
+// IdempotencyConfig define la configuración del middleware de idempotencia
+type IdempotencyConfig struct {
+	Policy IdempotencyPolicy
+	// LockLease es la duración de la reserva de una clave en curso; por defecto DefaultLockLease
+	LockLease time.Duration
+	// TTL es el tiempo durante el que se repite la respuesta de la ruta; 0 usa el TTL del store
+	TTL time.Duration
+	// Outcome decide la acción a partir del código y del cuerpo de la respuesta, para las rutas que
+	// informan errores con código 200, como GraphQL. Si es nil se usa Policy.
+	Outcome func(statusCode int, body []byte) ReplayAction
+}
+
+// action devuelve la acción que corresponde a la respuesta
+func (config IdempotencyConfig) action(statusCode int, body []byte) ReplayAction {
+	if config.Outcome != nil {
+		return config.Outcome(statusCode, body)
+	}
+	return config.Policy.Action(statusCode)
+}
+
+// Options devuelve los tiempos con los que se reservan las claves
+func (config IdempotencyConfig) Options() ports.IdempotencyOptions {
+	return ports.IdempotencyOptions{Lease: config.LockLease, TTL: config.TTL}
+}
+
+// ReplayedHeaders son las cabeceras de la respuesta original que se repiten junto con el cuerpo
+var ReplayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, HeaderContentLanguage}
+
+// HeaderIdempotentReplayed marca las respuestas que se repiten desde la clave de idempotencia
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+// IdempotencyMiddleware contiene la lógica de idempotencia con la política y el TTL por defecto
+func IdempotencyMiddleware(store ports.IdempotencyStore) echo.MiddlewareFunc {
+	return IdempotencyMiddlewareWithConfig(store, IdempotencyConfig{Policy: DefaultIdempotencyPolicy})
+}
+
+// IdempotencyMiddlewareWithConfig devuelve el middleware de idempotencia con la configuración indicada.
+// Las claves se guardan por cliente y junto con la huella de la solicitud: reutilizar una clave con
+// otro método, ruta o cuerpo se rechaza con 422 en lugar de devolver la respuesta de otra solicitud.
+// Los reintentos reciben el mismo código, las cabeceras de ReplayedHeaders y el mismo cuerpo que la
+// respuesta original, incluidas las respuestas de error si la política las guarda. La clave se toma
+// de forma atómica en el store bajo una reserva corta que se renueva mientras el handler se ejecuta,
+// por lo que dos solicitudes concurrentes nunca se procesan a la vez y una instancia caída no
+// bloquea los reintentos.
+func IdempotencyMiddlewareWithConfig(store ports.IdempotencyStore, config IdempotencyConfig) echo.MiddlewareFunc {
+	if config.Policy == nil {
+		config.Policy = DefaultIdempotencyPolicy
+	}
+	if config.LockLease <= 0 {
+		config.LockLease = DefaultLockLease
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			ctx := context.Background()
 			idempotencyKey := c.Request().Header.Get("Idempotency-Key")
 
-			// Si no tiene Idempotency-Key, continuar sin usar Redis
+			// Si no tiene Idempotency-Key, continuar sin usar el store
 			if idempotencyKey == "" {
 				return next(c)
 			}
 
-			redisKey := KeyPrefix + idempotencyKey
+			if err := ValidateIdempotencyKey(idempotencyKey); err != nil {
+				return err
+			}
 
-			// Revisar si la clave ya existe en Redis
-			data, err := redisClient.Get(ctx, redisKey).Result()
-			if err == nil {
-				var storedData IdempotencyData
-				json.Unmarshal([]byte(data), &storedData)
+			// Leer el cuerpo para calcular la huella y restaurarlo para el handler
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return apperrors.Invalid(apperrors.CodeInvalidRequest, "Datos de entrada inválidos")
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+			fingerprint := RequestFingerprint(c.Request().Method, c.Request().URL.RequestURI(), fingerprintBody(c.Request().Header.Get(echo.HeaderContentType), body))
 
+			// Reservar la clave de forma atómica: solo una solicitud puede tomarla
+			lock, storedData, err := AcquireIdempotencyKey(c.Request().Context(), store, ScopedIdempotencyKey(ClientScope(c), idempotencyKey), fingerprint, config.Options())
+			if err != nil {
+				if _, ok := apperrors.As(err); ok {
+					return err
+				}
+				return apperrors.Internal(apperrors.CodeInternal, "error al verificar la clave de idempotencia", err)
+			}
+			if storedData != nil {
 				// Si la solicitud está en progreso, devolver error 409
-				if storedData.Status == StatusInProgress {
-					return c.JSON(http.StatusConflict, map[string]string{"error": "Petición esta siendo procesada"})
+				if storedData.Status == ports.IdempotencyInProgress {
+					return apperrors.Conflict(apperrors.CodeIdempotencyInProgress, "la petición está siendo procesada")
 				}
 
-				// Si la solicitud ya fue completada, devolver la respuesta almacenada
-				return c.JSON(http.StatusOK, storedData.Response)
+				// Si la solicitud ya fue completada, repetir exactamente la respuesta almacenada
+				return replayResponse(c, *storedData)
 			}
 
-			// Guardar el estado IN_PROGRESS en Redis antes de procesar la solicitud
-			storedData := IdempotencyData{
-				Status:   StatusInProgress,
-				Response: nil,
-			}
-			jsonData, _ := json.Marshal(storedData)
-			redisClient.Set(ctx, redisKey, jsonData, TTL)
+			// Una vez tomada, la reserva se renueva y la respuesta se guarda aunque el cliente se
+			// desconecte o venza la solicitud, pero el contexto conserva el ID de la solicitud y la
+			// traza para los mensajes de log y los comandos del store
+			ctx := context.WithoutCancel(c.Request().Context())
+
+			// Renovar la reserva mientras el handler se ejecuta. Si la reserva vence y un reintento toma
+			// la clave, se cancela el contexto del handler para que no complete la operación a la vez
+			// que el reintento. El handler puede usar la reserva para asociar la clave con lo que crea
+			// dentro de su propia transacción.
+			handlerCtx, cancelHandler := context.WithCancelCause(c.Request().Context())
+			defer cancelHandler(nil)
+			stopKeepAlive := lock.KeepAlive(ctx, func() { cancelHandler(ports.ErrIdempotencyLockLost) })
+			defer stopKeepAlive()
+			c.Set(ContextKeyIdempotencyLock, lock)
+			c.SetRequest(c.Request().WithContext(WithIdempotencyLock(handlerCtx, lock)))
 
 			// Capturar la respuesta antes de enviarla al cliente
 			rec := c.Response().Writer
@@ -67,24 +187,69 @@ func IdempotencyMiddleware(redisClient *redis.Client) echo.MiddlewareFunc {
 			multiWriter := io.MultiWriter(rec, buffer)
 			c.Response().Writer = &responseWriterInterceptor{ResponseWriter: rec, writer: multiWriter}
 
-			// Procesar la solicitud
-			err = next(c)
+			// Procesar la solicitud. Los errores se escriben aquí con HTTPErrorHandler para capturar
+			// la respuesta que recibe el cliente, igual que las respuestas exitosas.
+			if err := next(c); err != nil {
+				if errors.Is(context.Cause(handlerCtx), ports.ErrIdempotencyLockLost) {
+					// La clave pertenece ahora al reintento que la tomó
+					err = apperrors.Conflict(apperrors.CodeIdempotencyInProgress, "la petición está siendo procesada")
+				}
+				c.Error(err)
+			}
+			stopKeepAlive()
+
+			statusCode := c.Response().Status
+			if config.action(statusCode, buffer.Bytes()) == ReplayRelease {
+				// Liberar la clave para que el cliente pueda reintentar
+				if err := lock.Release(ctx); err != nil {
+					slog.WarnContext(ctx, "Error al liberar la clave de idempotencia", "key", idempotencyKey, "error", err)
+				}
+				return nil
+			}
 
-			// Guardar la respuesta en Redis solo si no hay errores
-			if err == nil {
-				responseData := IdempotencyData{
-					Status:   StatusCompleted,
-					Response: json.RawMessage(buffer.Bytes()),
+			headers := make(map[string]string)
+			for _, name := range ReplayedHeaders {
+				if value := c.Response().Header().Get(name); value != "" {
+					headers[name] = value
 				}
-				jsonData, _ := json.Marshal(responseData)
-				redisClient.Set(ctx, redisKey, jsonData, TTL)
+			}
+			responseData := ports.IdempotencyRecord{
+				Fingerprint: fingerprint,
+				StatusCode:  statusCode,
+				Headers:     headers,
+				Response:    buffer.Bytes(),
+			}
+			if err := lock.Complete(ctx, responseData); err != nil {
+				// La respuesta ya se envió; si la reserva venció, la clave pertenece a un reintento
+				slog.WarnContext(ctx, "Error al guardar la respuesta idempotente", "key", idempotencyKey, "error", err)
 			}
 
-			return err
+			return nil
 		}
 	}
 }
 
+// replayResponse repite la respuesta almacenada con su código, sus cabeceras y su cuerpo
+func replayResponse(c echo.Context, storedData ports.IdempotencyRecord) error {
+	header := c.Response().Header()
+	for name, value := range storedData.Headers {
+		header.Set(name, value)
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+
+	// Las claves guardadas sin código se repiten como una respuesta exitosa
+	statusCode := storedData.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	contentType := storedData.Headers[echo.HeaderContentType]
+	if contentType == "" {
+		contentType = echo.MIMEApplicationJSON
+	}
+
+	return c.Blob(statusCode, contentType, storedData.Response)
+}
+
 // responseWriterInterceptor captura la respuesta antes de enviarla
 type responseWriterInterceptor struct {
 	http.ResponseWriter
@@ -94,3 +259,150 @@ type responseWriterInterceptor struct {
 func (r *responseWriterInterceptor) Write(b []byte) (int, error) {
 	return r.writer.Write(b)
 }
+
+// ClientScope devuelve el ámbito de las claves de idempotencia del cliente que hace la solicitud, para
+// que dos clientes que usan la misma clave no compartan respuestas. Se usa el identificador que deja
+// ClientAuthMiddleware en el contexto, que no cambia al rotar la clave del cliente.
+func ClientScope(c echo.Context) string {
+	clientID, _ := c.Get(ContextKeyClientID).(string)
+	return ScopeFor(clientID, c.Request().Header.Get(echo.HeaderAuthorization))
+}
+
+// ScopeFor devuelve el ámbito de un cliente autenticado o, si no se identificó, un hash de su cabecera
+// Authorization, de modo que las credenciales nunca se guardan en el store. Las solicitudes sin
+// credenciales comparten el ámbito AnonymousClient. auth.ParseClients rechaza los identificadores que
+// coinciden con esos ámbitos, por lo que un cliente configurado nunca comparte claves con otros.
+func ScopeFor(clientID, authorization string) string {
+	if clientID != "" {
+		return clientID
+	}
+	if authorization != "" {
+		sum := sha256.Sum256([]byte(authorization))
+		return hex.EncodeToString(sum[:])
+	}
+	return AnonymousClient
+}
+
+// RequestFingerprint calcula la huella de una solicitud a partir del método, la ruta (con su query) y el cuerpo.
+// Los cuerpos JSON se normalizan antes, por lo que el orden de las propiedades y los espacios no la cambian.
+func RequestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + "\n" + path + "\n"))
+	hash.Write(canonicalBody(body))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// canonicalBody reescribe un cuerpo JSON con las propiedades ordenadas y sin espacios. Los cuerpos
+// que no son JSON se usan tal cual.
+func canonicalBody(body []byte) []byte {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return canonical
+}
+
+// fingerprintBody devuelve el contenido de la solicitud sobre el que se calcula la huella. De un
+// cuerpo multipart se usan las cabeceras y el contenido de cada parte, no el separador entre partes,
+// que el cliente genera al azar en cada reintento. Si el cuerpo no se puede leer como multipart se
+// usa tal cual.
+func fingerprintBody(contentType string, body []byte) []byte {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return body
+	}
+
+	var parts bytes.Buffer
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return parts.Bytes()
+		}
+		if err != nil {
+			return body
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return body
+		}
+
+		names := make([]string, 0, len(part.Header))
+		for name := range part.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range part.Header[name] {
+				writeField(&parts, []byte(name))
+				writeField(&parts, []byte(value))
+			}
+		}
+		writeField(&parts, content)
+	}
+}
+
+// writeField escribe value precedido de su longitud, para que dos partes distintas nunca produzcan
+// los mismos bytes
+func writeField(buffer *bytes.Buffer, value []byte) {
+	buffer.WriteString(strconv.Itoa(len(value)))
+	buffer.WriteByte(':')
+	buffer.Write(value)
+}
+
+// ScopedIdempotencyKey devuelve la clave del store de una clave de idempotencia en el ámbito del cliente
+func ScopedIdempotencyKey(scope, idempotencyKey string) string {
+	return scope + ":" + idempotencyKey
+}
+
+// ValidateIdempotencyKey rechaza las claves más largas que MaxIdempotencyKeyLength antes de llegar al store
+func ValidateIdempotencyKey(idempotencyKey string) error {
+	if len(idempotencyKey) > MaxIdempotencyKeyLength {
+		return apperrors.Invalidf(apperrors.CodeIdempotencyKeyTooLong, "la clave de idempotencia no puede superar los %d caracteres", MaxIdempotencyKeyLength)
+	}
+	return nil
+}
+
+func fingerprintMismatch() error {
+	return apperrors.Unprocessable(apperrors.CodeIdempotencyMismatch, "la clave de idempotencia ya se usó con una solicitud distinta")
+}
+
+// AcquireIdempotencyKey reserva la clave en el store y rechaza su reutilización con otra huella.
+// Si la clave ya existía con la misma huella devuelve sus datos; si la reserva tuvo éxito devuelve
+// el lock y datos nil.
+func AcquireIdempotencyKey(ctx context.Context, store ports.IdempotencyStore, key, fingerprint string, options ports.IdempotencyOptions) (ports.IdempotencyLock, *ports.IdempotencyRecord, error) {
+	lock, storedData, err := store.Acquire(ctx, key, fingerprint, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	if storedData != nil && storedData.Fingerprint != fingerprint {
+		return nil, nil, fingerprintMismatch()
+	}
+	return lock, storedData, nil
+}
+
+// IdempotencyLockFromContext devuelve la reserva de la clave de la solicitud, o nil si la solicitud
+// no tiene Idempotency-Key
+func IdempotencyLockFromContext(c echo.Context) ports.IdempotencyLock {
+	lock, _ := c.Get(ContextKeyIdempotencyLock).(ports.IdempotencyLock)
+	return lock
+}
+
+type idempotencyLockKey struct{}
+
+// WithIdempotencyLock devuelve una copia de ctx con la reserva de la clave de la solicitud, para los
+// handlers que no reciben el contexto de Echo, como los de gRPC y GraphQL
+func WithIdempotencyLock(ctx context.Context, lock ports.IdempotencyLock) context.Context {
+	return context.WithValue(ctx, idempotencyLockKey{}, lock)
+}
+
+// IdempotencyLockFromRequest devuelve la reserva de la clave guardada en ctx, o nil si la solicitud no
+// tiene clave de idempotencia
+func IdempotencyLockFromRequest(ctx context.Context) ports.IdempotencyLock {
+	lock, _ := ctx.Value(idempotencyLockKey{}).(ports.IdempotencyLock)
+	return lock
+}
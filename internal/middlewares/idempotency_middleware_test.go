@@ -0,0 +1,403 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/idempotency"
+	"order_management/internal/ports"
+	"order_management/test/mocks"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// newIdempotentServer registra POST /orders con el middleware de idempotencia y cuenta las veces que se ejecuta el handler
+func newIdempotentServer(t *testing.T) (*echo.Echo, *int) {
+	store := idempotency.NewMemoryStore(idempotency.DefaultTTL)
+
+	calls := 0
+	e := echo.New()
+	e.POST("/orders", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusCreated, map[string]int{"id": calls})
+	}, IdempotencyMiddleware(store))
+
+	return e, &calls
+}
+
+func postOrder(e *echo.Echo, body, idempotencyKey, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestIdempotencyMiddleware_ReplaysEquivalentBody verifica que un cuerpo JSON equivalente reutilice la respuesta almacenada
+func TestIdempotencyMiddleware_ReplaysEquivalentBody(t *testing.T) {
+	e, calls := newIdempotentServer(t)
+
+	first := postOrder(e, `{"customer_name":"Customer 1","items":[{"product_id":1,"quantity":2}]}`, "key-1", "")
+	retry := postOrder(e, `{ "items": [ {"quantity": 2, "product_id": 1} ], "customer_name": "Customer 1" }`, "key-1", "")
+
+	assert.Equal(t, 1, *calls)
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+}
+
+// TestIdempotencyMiddleware_RejectsDifferentBody verifica que reutilizar la clave con otro cuerpo se rechace con 422
+func TestIdempotencyMiddleware_RejectsDifferentBody(t *testing.T) {
+	e, calls := newIdempotentServer(t)
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		appErr, _ := apperrors.As(err)
+		c.JSON(http.StatusUnprocessableEntity, map[string]string{"code": string(appErr.Code)})
+	}
+
+	postOrder(e, `{"customer_name":"Customer 1","items":[{"product_id":1,"quantity":2}]}`, "key-1", "")
+	rec := postOrder(e, `{"customer_name":"Customer 1","items":[{"product_id":1,"quantity":5}]}`, "key-1", "")
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"code":"IDEMPOTENCY_KEY_MISMATCH"}`, rec.Body.String())
+}
+
+// TestIdempotencyMiddleware_RejectsLongKey verifica que una clave más larga que MaxIdempotencyKeyLength
+// se rechace con 400 sin ejecutar el handler ni llegar al store
+func TestIdempotencyMiddleware_RejectsLongKey(t *testing.T) {
+	e, calls := newIdempotentServer(t)
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		appErr, _ := apperrors.As(err)
+		c.JSON(http.StatusBadRequest, map[string]string{"code": string(appErr.Code)})
+	}
+	body := `{"customer_name":"Customer 1","items":[{"product_id":1,"quantity":2}]}`
+
+	rec := postOrder(e, body, strings.Repeat("k", MaxIdempotencyKeyLength+1), "")
+	accepted := postOrder(e, body, strings.Repeat("k", MaxIdempotencyKeyLength), "")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"code":"IDEMPOTENCY_KEY_TOO_LONG"}`, rec.Body.String())
+	assert.Equal(t, http.StatusCreated, accepted.Code)
+	assert.Equal(t, 1, *calls)
+}
+
+// TestIdempotencyMiddleware_ScopesKeysPerClient verifica que dos clientes puedan usar la misma clave sin compartir respuestas
+func TestIdempotencyMiddleware_ScopesKeysPerClient(t *testing.T) {
+	e, calls := newIdempotentServer(t)
+	body := `{"customer_name":"Customer 1","items":[{"product_id":1,"quantity":2}]}`
+
+	first := postOrder(e, body, "key-1", "Bearer client-a")
+	second := postOrder(e, body, "key-1", "Bearer client-b")
+	retry := postOrder(e, body, "key-1", "Bearer client-a")
+
+	assert.Equal(t, 2, *calls)
+	assert.NotEqual(t, first.Body.String(), second.Body.String())
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+}
+
+// TestIdempotencyMiddleware_ReplaysStatusAndHeaders verifica que el reintento reciba el mismo código, cabeceras y cuerpo
+func TestIdempotencyMiddleware_ReplaysStatusAndHeaders(t *testing.T) {
+	e, calls := newIdempotentServer(t)
+	body := `{"customer_name":"Customer 1","items":[{"product_id":1,"quantity":2}]}`
+
+	first := postOrder(e, body, "key-1", "")
+	retry := postOrder(e, body, "key-1", "")
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Header().Get(echo.HeaderContentType), retry.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "true", retry.Header().Get(HeaderIdempotentReplayed))
+	assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+}
+
+// newFailingServer registra POST /orders con un handler que devuelve el error indicado
+func newFailingServer(t *testing.T, err error, config IdempotencyConfig) (*echo.Echo, *int) {
+	store := idempotency.NewMemoryStore(idempotency.DefaultTTL)
+
+	calls := 0
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		appErr, _ := apperrors.As(err)
+		c.JSON(http.StatusConflict, map[string]string{"code": string(appErr.Code)})
+	}
+	e.POST("/orders", func(c echo.Context) error {
+		calls++
+		return err
+	}, IdempotencyMiddlewareWithConfig(store, config))
+
+	return e, &calls
+}
+
+// TestIdempotencyMiddleware_ReleasesErrorsByDefault verifica que los errores liberen la clave para reintentar
+func TestIdempotencyMiddleware_ReleasesErrorsByDefault(t *testing.T) {
+	e, calls := newFailingServer(t, apperrors.Conflict(apperrors.CodeInsufficientStock, "stock insuficiente"), IdempotencyConfig{})
+	body := `{"customer_name":"Customer 1"}`
+
+	first := postOrder(e, body, "key-1", "")
+	retry := postOrder(e, body, "key-1", "")
+
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, http.StatusConflict, first.Code)
+	assert.Equal(t, http.StatusConflict, retry.Code)
+	assert.Empty(t, retry.Header().Get(HeaderIdempotentReplayed))
+}
+
+// TestIdempotencyMiddleware_CachesErrorsWithPolicy verifica que una política que guarda los 4xx repita el error original
+func TestIdempotencyMiddleware_CachesErrorsWithPolicy(t *testing.T) {
+	policy := IdempotencyPolicy{2: ReplayCache, 4: ReplayCache, 5: ReplayRelease}
+	e, calls := newFailingServer(t, apperrors.Conflict(apperrors.CodeInsufficientStock, "stock insuficiente"), IdempotencyConfig{Policy: policy})
+	body := `{"customer_name":"Customer 1"}`
+
+	first := postOrder(e, body, "key-1", "")
+	retry := postOrder(e, body, "key-1", "")
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusConflict, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(HeaderIdempotentReplayed))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+}
+
+// TestIdempotencyMiddleware_ConcurrentRequestsRunOnce verifica que dos solicitudes simultáneas con la
+// misma clave no ejecuten el handler dos veces
+func TestIdempotencyMiddleware_ConcurrentRequestsRunOnce(t *testing.T) {
+	store := idempotency.NewMemoryStore(idempotency.DefaultTTL)
+
+	var calls int32
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		appErr, _ := apperrors.As(err)
+		c.JSON(http.StatusConflict, map[string]string{"code": string(appErr.Code)})
+	}
+	e.POST("/orders", func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		close(started)
+		<-finish
+		return c.JSON(http.StatusCreated, map[string]int{"id": 1})
+	}, IdempotencyMiddleware(store))
+
+	body := `{"customer_name":"Customer 1"}`
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- postOrder(e, body, "key-1", "") }()
+
+	<-started
+	concurrent := postOrder(e, body, "key-1", "")
+	close(finish)
+
+	assert.Equal(t, http.StatusConflict, concurrent.Code)
+	assert.JSONEq(t, `{"code":"IDEMPOTENCY_KEY_IN_PROGRESS"}`, concurrent.Body.String())
+	assert.Equal(t, http.StatusCreated, (<-first).Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyPolicy_Action(t *testing.T) {
+	assert.Equal(t, ReplayCache, DefaultIdempotencyPolicy.Action(http.StatusCreated))
+	assert.Equal(t, ReplayCache, DefaultIdempotencyPolicy.Action(http.StatusSeeOther))
+	assert.Equal(t, ReplayRelease, DefaultIdempotencyPolicy.Action(http.StatusUnprocessableEntity))
+	assert.Equal(t, ReplayRelease, DefaultIdempotencyPolicy.Action(http.StatusServiceUnavailable))
+	assert.Equal(t, ReplayRelease, IdempotencyPolicy{}.Action(http.StatusOK))
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	fingerprint := RequestFingerprint(http.MethodPost, "/api/orders", []byte(`{"a":1,"b":[1,2]}`))
+
+	assert.Equal(t, fingerprint, RequestFingerprint(http.MethodPost, "/api/orders", []byte(`{"b":[1,2], "a":1}`)))
+	assert.NotEqual(t, fingerprint, RequestFingerprint(http.MethodPost, "/api/v2/orders", []byte(`{"a":1,"b":[1,2]}`)))
+	assert.NotEqual(t, fingerprint, RequestFingerprint(http.MethodPut, "/api/orders", []byte(`{"a":1,"b":[1,2]}`)))
+	assert.NotEqual(t, fingerprint, RequestFingerprint(http.MethodPost, "/api/orders", []byte(`{"a":1,"b":[2,1]}`)))
+}
+
+// TestIdempotencyMiddleware_UsesRouteTTL verifica que la clave se reserve con el TTL de la ruta
+func TestIdempotencyMiddleware_UsesRouteTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockIdempotencyStore(ctrl)
+	store.EXPECT().
+		Acquire(gomock.Any(), "anonymous:key-1", gomock.Any(), ports.IdempotencyOptions{Lease: DefaultLockLease, TTL: time.Hour}).
+		DoAndReturn(func(ctx context.Context, key, fingerprint string, options ports.IdempotencyOptions) (ports.IdempotencyLock, *ports.IdempotencyRecord, error) {
+			return nil, &ports.IdempotencyRecord{Status: ports.IdempotencyCompleted, Fingerprint: fingerprint, StatusCode: http.StatusOK, Response: []byte(`{}`)}, nil
+		}).
+		Times(1)
+
+	e := echo.New()
+	e.PUT("/products/:id/stock", func(c echo.Context) error {
+		t.Fatal("el handler no debe ejecutarse al repetir la respuesta")
+		return nil
+	}, IdempotencyMiddlewareWithConfig(store, IdempotencyConfig{TTL: time.Hour}))
+
+	req := httptest.NewRequest(http.MethodPut, "/products/1/stock", strings.NewReader(`{"stock":5}`))
+	req.Header.Set("Idempotency-Key", "key-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+}
+
+// TestIdempotencyMiddleware_IgnoresMultipartBoundary verifica que el reintento de un formulario multipart se
+// reconozca aunque el cliente genere otro separador, y que la query forme parte de la huella
+func TestIdempotencyMiddleware_IgnoresMultipartBoundary(t *testing.T) {
+	store := idempotency.NewMemoryStore(idempotency.DefaultTTL)
+
+	calls := 0
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		appErr, _ := apperrors.As(err)
+		c.JSON(http.StatusUnprocessableEntity, map[string]string{"code": string(appErr.Code)})
+	}
+	e.POST("/products/import", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusOK, map[string]int{"import": calls})
+	}, IdempotencyMiddleware(store))
+
+	upload := func(target, boundary string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.SetBoundary(boundary)
+		part, _ := writer.CreateFormFile("file", "products.csv")
+		part.Write([]byte("sku,name,price\nSKU-1,Producto,10\n"))
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, target, &body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		req.Header.Set("Idempotency-Key", "key-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := upload("/products/import", "boundary-a")
+	retry := upload("/products/import", "boundary-b")
+	dryRun := upload("/products/import?dry_run=true", "boundary-c")
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, http.StatusUnprocessableEntity, dryRun.Code)
+}
+
+// TestFingerprintBody_HashesMultipartParts verifica que la huella de un formulario multipart dependa de
+// las cabeceras y del contenido de cada parte, incluso si el contenido incluye el separador
+func TestFingerprintBody_HashesMultipartParts(t *testing.T) {
+	form := func(boundary, filename, content string) (string, []byte) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.SetBoundary(boundary)
+		part, _ := writer.CreateFormFile("file", filename)
+		part.Write([]byte(content))
+		writer.Close()
+		return writer.FormDataContentType(), body.Bytes()
+	}
+	fingerprint := func(contentType string, body []byte) string {
+		return RequestFingerprint(http.MethodPost, "/products/import", fingerprintBody(contentType, body))
+	}
+
+	original := fingerprint(form("boundary-a", "products.csv", "sku\nSKU-boundary-a-1\n"))
+
+	// Otro separador con el mismo contenido es la misma solicitud
+	assert.Equal(t, original, fingerprint(form("boundary-b", "products.csv", "sku\nSKU-boundary-a-1\n")))
+	// Quitar del contenido los bytes que coinciden con el separador cambia la solicitud
+	assert.NotEqual(t, original, fingerprint(form("boundary-a", "products.csv", "sku\nSKU--1\n")))
+	// Las cabeceras de la parte también forman parte de la huella
+	assert.NotEqual(t, original, fingerprint(form("boundary-a", "otros.csv", "sku\nSKU-boundary-a-1\n")))
+}
+
+// TestIdempotencyMiddleware_OutcomeDecidesFromBody verifica que Outcome pueda liberar la clave de una
+// respuesta 200 que informa errores en el cuerpo
+func TestIdempotencyMiddleware_OutcomeDecidesFromBody(t *testing.T) {
+	store := idempotency.NewMemoryStore(idempotency.DefaultTTL)
+
+	calls := 0
+	e := echo.New()
+	e.POST("/orders", func(c echo.Context) error {
+		calls++
+		if calls == 1 {
+			return c.JSON(http.StatusOK, map[string]string{"error": "stock insuficiente"})
+		}
+		return c.JSON(http.StatusOK, map[string]int{"id": calls})
+	}, IdempotencyMiddlewareWithConfig(store, IdempotencyConfig{
+		Outcome: func(statusCode int, body []byte) ReplayAction {
+			if strings.Contains(string(body), `"error"`) {
+				return ReplayRelease
+			}
+			return DefaultIdempotencyPolicy.Action(statusCode)
+		},
+	}))
+
+	failed := postOrder(e, `{}`, "key-1", "")
+	retry := postOrder(e, `{}`, "key-1", "")
+	replayed := postOrder(e, `{}`, "key-1", "")
+
+	assert.Equal(t, 2, calls)
+	assert.Contains(t, failed.Body.String(), "error")
+	assert.Empty(t, retry.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, "true", replayed.Header().Get(HeaderIdempotentReplayed))
+	assert.JSONEq(t, retry.Body.String(), replayed.Body.String())
+}
+
+// TestIdempotencyMiddleware_CancelsHandlerOnLostLease verifica que, si la reserva en Redis vence y un
+// reintento toma la clave, se cancele el contexto del handler y la respuesta no se guarde
+func TestIdempotencyMiddleware_CancelsHandlerOnLostLease(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer redisClient.Close()
+	store := idempotency.NewRedisStore(redisClient, idempotency.DefaultTTL)
+
+	started := make(chan struct{})
+	var canceledByLostLease atomic.Bool
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		appErr, _ := apperrors.As(err)
+		c.JSON(http.StatusConflict, map[string]string{"code": string(appErr.Code)})
+	}
+	e.POST("/orders", func(c echo.Context) error {
+		close(started)
+		ctx := c.Request().Context()
+		select {
+		case <-ctx.Done():
+			canceledByLostLease.Store(errors.Is(context.Cause(ctx), ports.ErrIdempotencyLockLost))
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return c.JSON(http.StatusCreated, map[string]int{"id": 1})
+		}
+	}, IdempotencyMiddlewareWithConfig(store, IdempotencyConfig{LockLease: 60 * time.Millisecond}))
+
+	go func() {
+		<-started
+		// La reserva vence sin renovarse a tiempo y un reintento toma la clave
+		redisServer.Del(idempotency.KeyPrefix + ScopedIdempotencyKey(AnonymousClient, "key-1"))
+		store.Acquire(context.Background(), ScopedIdempotencyKey(AnonymousClient, "key-1"), "otra", ports.IdempotencyOptions{Lease: time.Minute})
+	}()
+
+	rec := postOrder(e, `{"customer_name":"Customer 1"}`, "key-1", "")
+
+	assert.True(t, canceledByLostLease.Load())
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), string(apperrors.CodeIdempotencyInProgress))
+
+	// La clave sigue siendo del reintento, que no se ve afectado por la solicitud cancelada
+	record, err := store.Get(context.Background(), ScopedIdempotencyKey(AnonymousClient, "key-1"))
+	assert.NoError(t, err)
+	if assert.NotNil(t, record) {
+		assert.Equal(t, ports.IdempotencyInProgress, record.Status)
+		assert.Equal(t, "otra", record.Fingerprint)
+	}
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"order_management/internal/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderAcceptLanguage  = "Accept-Language"
+	HeaderContentLanguage = "Content-Language"
+)
+
+// LanguageMiddleware negocia el idioma de la respuesta a partir de la cabecera Accept-Language y lo
+// guarda en el contexto de la solicitud para que los mensajes se traduzcan a ese idioma
+func LanguageMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			lang := i18n.MatchLanguage(c.Request().Header.Get(HeaderAcceptLanguage))
+
+			c.SetRequest(c.Request().WithContext(i18n.WithLanguage(c.Request().Context(), lang)))
+
+			header := c.Response().Header()
+			header.Set(HeaderContentLanguage, lang)
+			header.Add(echo.HeaderVary, HeaderAcceptLanguage)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"order_management/internal/i18n"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageMiddleware_NegotiatesLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                      "es",
+		"en-US,en;q=0.9":        "en",
+		"fr-FR, pt-BR;q=0.8":    "pt",
+		"de-DE":                 "es",
+		"es-AR, en;q=0.5":       "es",
+		"en;q=0.2, es-MX;q=0.9": "es",
+	}
+
+	for acceptLanguage, expected := range cases {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/orders/1", nil)
+		req.Header.Set(HeaderAcceptLanguage, acceptLanguage)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		var lang string
+		handler := LanguageMiddleware()(func(c echo.Context) error {
+			lang = i18n.LanguageFromContext(c.Request().Context())
+			return c.NoContent(http.StatusOK)
+		})
+
+		assert.NoError(t, handler(c))
+		assert.Equal(t, expected, lang, acceptLanguage)
+		assert.Equal(t, expected, rec.Header().Get(HeaderContentLanguage))
+		assert.Equal(t, HeaderAcceptLanguage, rec.Header().Get(echo.HeaderVary))
+	}
+}
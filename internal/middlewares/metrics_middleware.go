@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"time"
+
+	"order_management/internal/ports"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UnmatchedRoute es la ruta con la que se registran las solicitudes que no coinciden con ninguna ruta,
+// para no crear una serie por cada URL desconocida
+const UnmatchedRoute = "unmatched"
+
+// MetricsMiddleware registra la duración de cada solicitud por método, ruta registrada y código de
+// respuesta. Se usa la ruta con sus parámetros (/api/orders/:id) y no la URL, para acotar las series.
+func MetricsMiddleware(metrics ports.HTTPMetrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+			if err != nil && !c.Response().Committed {
+				// Escribir el error con HTTPErrorHandler para conocer el código real de la respuesta
+				c.Error(err)
+				err = nil
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = UnmatchedRoute
+			}
+			metrics.ObserveHTTPRequest(c.Request().Method, route, c.Response().Status, time.Since(start))
+			return err
+		}
+	}
+}
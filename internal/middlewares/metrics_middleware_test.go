@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware_ObservesRouteAndStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	metrics := mocks.NewMockHTTPMetrics(ctrl)
+
+	e := echo.New()
+	e.Use(MetricsMiddleware(metrics))
+	e.GET("/api/orders/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.POST("/api/orders", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+
+	metrics.EXPECT().ObserveHTTPRequest(http.MethodGet, "/api/orders/:id", http.StatusOK, gomock.Any())
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/orders/9", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// El error se traduce antes de registrar la solicitud y se escribe una sola vez
+	metrics.EXPECT().ObserveHTTPRequest(http.MethodPost, "/api/orders", http.StatusInternalServerError, gomock.Any())
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/orders", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestMetricsMiddleware_GroupsUnmatchedRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	metrics := mocks.NewMockHTTPMetrics(ctrl)
+
+	e := echo.New()
+	e.Use(MetricsMiddleware(metrics))
+	e.GET("/api/orders/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	metrics.EXPECT().ObserveHTTPRequest(http.MethodGet, UnmatchedRoute, http.StatusNotFound, gomock.Any())
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no-existe/123", nil))
+}
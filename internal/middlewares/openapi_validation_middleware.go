@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"errors"
+
+	"order_management/internal/apperrors"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+// El registro de decodificadores de openapi3filter es global, por lo que se hace una sola vez y no en
+// cada llamada a OpenAPIValidationMiddleware
+func init() {
+	// La importación de productos recibe el CSV directamente como cuerpo
+	openapi3filter.RegisterBodyDecoder("text/csv", openapi3filter.FileBodyDecoder)
+}
+
+// OpenAPIValidationMiddleware valida parámetros, cabeceras y cuerpo de cada solicitud contra el
+// documento OpenAPI antes de llegar al handler. Las rutas que no figuran en el documento no se validan.
+// La validación lee el cuerpo completo, por lo que el tamaño del cuerpo debe limitarse antes.
+func OpenAPIValidationMiddleware(doc *openapi3.T) (echo.MiddlewareFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route, pathParams, err := router.FindRoute(c.Request())
+			if err != nil {
+				return next(c)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    c.Request(),
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			if err := openapi3filter.ValidateRequest(c.Request().Context(), input); err != nil {
+				return apperrors.Invalid(apperrors.CodeInvalidRequest, "la solicitud no cumple la especificación de la API").
+					WithDetail("violations", validationViolations(err))
+			}
+
+			return next(c)
+		}
+	}, nil
+}
+
+// validationViolations aplana los errores de validación en una lista de mensajes
+func validationViolations(err error) []string {
+	var multiErr openapi3.MultiError
+	if !errors.As(err, &multiErr) {
+		return []string{err.Error()}
+	}
+
+	violations := make([]string, 0, len(multiErr))
+	for _, e := range multiErr {
+		violations = append(violations, validationViolations(e)...)
+	}
+	return violations
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/openapi"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// newValidatedServer crea un Echo con la validación OpenAPI y un handler que solo responde 201
+func newValidatedServer(t *testing.T) *echo.Echo {
+	specValidator, err := OpenAPIValidationMiddleware(openapi.NewSpec())
+	assert.NoError(t, err)
+
+	e := echo.New()
+	e.POST("/api/orders", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	}, specValidator)
+	return e
+}
+
+// TestOpenAPIValidation_AcceptsValidRequest verifica que una solicitud válida llegue al handler
+func TestOpenAPIValidation_AcceptsValidRequest(t *testing.T) {
+	e := newValidatedServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader(`{"customer_name":"Ana","items":[{"product_id":1,"quantity":2}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+// TestOpenAPIValidation_RejectsInvalidRequest verifica que se rechace un cuerpo que no cumple el esquema
+func TestOpenAPIValidation_RejectsInvalidRequest(t *testing.T) {
+	specValidator, err := OpenAPIValidationMiddleware(openapi.NewSpec())
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader(`{"items":[{"product_id":1,"quantity":0}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	err = specValidator(func(c echo.Context) error { return nil })(c)
+
+	appErr, ok := apperrors.As(err)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.CodeInvalidRequest, appErr.Code)
+	assert.Len(t, appErr.Details["violations"], 2)
+}
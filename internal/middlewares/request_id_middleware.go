@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"order_management/internal/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+// validRequestID limita los IDs aceptados del cliente para que no puedan inyectar contenido en el log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware asigna a cada solicitud un ID de correlación. Se reutiliza el de la cabecera
+// X-Request-ID si es válido, para seguir la solicitud desde el balanceador o el cliente, o se genera
+// uno nuevo. El ID se devuelve en la misma cabecera y se guarda en el contexto de la solicitud para
+// que todos los mensajes de log que se registren con ese contexto lo incluyan.
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := RequestIDOrNew(c.Request().Header.Get(echo.HeaderXRequestID))
+
+			c.SetRequest(c.Request().WithContext(logging.WithRequestID(c.Request().Context(), id)))
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+			return next(c)
+		}
+	}
+}
+
+// RequestIDOrNew devuelve id si es un ID de correlación válido o, si no, uno nuevo generado al azar
+func RequestIDOrNew(id string) string {
+	if validRequestID.MatchString(id) {
+		return id
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
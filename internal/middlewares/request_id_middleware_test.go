@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"order_management/internal/logging"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveRequestID ejecuta el middleware con la cabecera X-Request-ID indicada y devuelve el ID del
+// contexto de la solicitud y el de la respuesta
+func serveRequestID(t *testing.T, header string) (string, string) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/1", nil)
+	if header != "" {
+		req.Header.Set(echo.HeaderXRequestID, header)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var id string
+	handler := RequestIDMiddleware()(func(c echo.Context) error {
+		id = logging.RequestIDFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+
+	assert.NoError(t, handler(c))
+	return id, rec.Header().Get(echo.HeaderXRequestID)
+}
+
+func TestRequestIDMiddleware_GeneratesID(t *testing.T) {
+	id, header := serveRequestID(t, "")
+
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, header)
+
+	other, _ := serveRequestID(t, "")
+	assert.NotEqual(t, id, other)
+}
+
+func TestRequestIDMiddleware_ReusesClientID(t *testing.T) {
+	id, header := serveRequestID(t, "lb-7f3a:42")
+
+	assert.Equal(t, "lb-7f3a:42", id)
+	assert.Equal(t, "lb-7f3a:42", header)
+}
+
+func TestRequestIDMiddleware_ReplacesInvalidID(t *testing.T) {
+	for _, invalid := range []string{"con espacios", `{"inyectado":true}`, strings.Repeat("a", 129)} {
+		id, header := serveRequestID(t, invalid)
+
+		assert.NotEqual(t, invalid, id)
+		assert.Len(t, id, 32, invalid)
+		assert.Equal(t, id, header)
+	}
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RequestLoggerMiddleware registra cada solicitud terminada en el log estructurado con su ruta, su
+// código y su duración. Se registra después de RequestIDMiddleware para que el mensaje incluya el
+// ID de la solicitud. Las solicitudes que devuelven 5xx se registran como errores. skipper permite
+// omitir rutas como las sondas de salud.
+func RequestLoggerMiddleware(skipper middleware.Skipper) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		Skipper:      skipper,
+		LogMethod:    true,
+		LogURI:       true,
+		LogRoutePath: true,
+		LogStatus:    true,
+		LogLatency:   true,
+		LogRemoteIP:  true,
+		LogError:     true,
+		// El error se traduce con HTTPErrorHandler antes de registrar la solicitud para conocer el código real
+		HandleError: true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			level := slog.LevelInfo
+			if v.Status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", v.Method),
+				slog.String("uri", v.URI),
+				slog.String("route", v.RoutePath),
+				slog.Int("status", v.Status),
+				slog.Float64("latency_ms", float64(v.Latency.Microseconds())/1000),
+				slog.String("remote_ip", v.RemoteIP),
+			}
+			if v.Error != nil {
+				attrs = append(attrs, slog.String("error", v.Error.Error()))
+			}
+			slog.LogAttrs(c.Request().Context(), level, "Solicitud HTTP", attrs...)
+			return nil
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"order_management/internal/logging"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs reemplaza el logger por defecto durante el test y devuelve las líneas registradas
+func captureLogs(t *testing.T) func() []map[string]interface{} {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, logging.Config{Level: "debug"}))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return func() []map[string]interface{} {
+		var lines []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+			lines = append(lines, entry)
+		}
+		return lines
+	}
+}
+
+func TestRequestLoggerMiddleware_LogsRequestWithRequestID(t *testing.T) {
+	logs := captureLogs(t)
+
+	e := echo.New()
+	e.Use(RequestIDMiddleware(), RequestLoggerMiddleware(func(c echo.Context) bool {
+		return c.Path() == "/healthz"
+	}))
+	e.GET("/api/orders/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound)
+	})
+	e.GET("/healthz", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/9", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	lines := logs()
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, "Solicitud HTTP", lines[0]["msg"])
+		assert.Equal(t, "INFO", lines[0]["level"])
+		assert.Equal(t, "req-1", lines[0][logging.RequestIDKey])
+		assert.Equal(t, "/api/orders/:id", lines[0]["route"])
+		assert.Equal(t, "/api/orders/9", lines[0]["uri"])
+		assert.Equal(t, float64(http.StatusNotFound), lines[0]["status"])
+	}
+}
+
+func TestRequestLoggerMiddleware_LogsServerErrorsAsErrors(t *testing.T) {
+	logs := captureLogs(t)
+
+	e := echo.New()
+	e.Use(RequestLoggerMiddleware(nil))
+	e.GET("/boom", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	lines := logs()
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, "ERROR", lines[0]["level"])
+		assert.Equal(t, float64(http.StatusInternalServerError), lines[0]["status"])
+		assert.Equal(t, "boom", lines[0]["error"])
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Category representa una categoría del catálogo. Las categorías forman un árbol mediante ParentID.
+type Category struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	ParentID  *uint     `gorm:"index" json:"parent_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relación con las subcategorías
+	Children []Category `gorm:"foreignKey:ParentID" json:"children"`
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
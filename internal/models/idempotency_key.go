@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// IdempotencyKey es una clave de idempotencia guardada en la base de datos junto con su respuesta.
+type IdempotencyKey struct {
+	Key         string            `gorm:"column:idempotency_key;type:varchar(255);primaryKey" json:"key"`
+	Status      string            `gorm:"type:varchar(20);not null" json:"status"`
+	Fingerprint string            `gorm:"type:varchar(64);not null" json:"fingerprint"`
+	LockToken   string            `gorm:"type:varchar(32)" json:"-"`
+	LockedUntil time.Time         `gorm:"not null" json:"locked_until"`
+	OrderID     *uint             `json:"order_id,omitempty"`
+	StatusCode  int               `json:"status_code"`
+	Headers     map[string]string `gorm:"type:json;serializer:json" json:"headers"`
+	Response    []byte            `gorm:"type:mediumblob" json:"-"`
+	ExpiresAt   time.Time         `gorm:"not null;index" json:"expires_at"`
+	CreatedAt   time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -4,11 +4,27 @@ import (
 	"time"
 )
 
+// Estados de una orden. Una orden nueva queda pendiente y avanza de a un estado hasta ser entregada.
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+)
+
+// nextOrderStatus indica el único estado al que puede pasar una orden desde cada estado
+var nextOrderStatus = map[string]string{
+	OrderStatusPending:   OrderStatusConfirmed,
+	OrderStatusConfirmed: OrderStatusShipped,
+	OrderStatusShipped:   OrderStatusDelivered,
+}
+
 // Order representa un pedido realizado por un cliente.
 type Order struct {
 	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	CustomerName string    `gorm:"type:varchar(255);not null" json:"customer_name"`
 	TotalAmount  float64   `gorm:"type:decimal(10,2);not null" json:"total_amount"`
+	Status       string    `gorm:"type:varchar(20);not null;default:pending" json:"status"`
 	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
@@ -19,3 +35,10 @@ type Order struct {
 func (Order) TableName() string {
 	return "orders"
 }
+
+// CanTransitionTo indica si la orden puede pasar al estado indicado. Los estados solo avanzan y
+// de a uno: una orden pendiente se confirma, luego se envía y por último se entrega.
+func (o Order) CanTransitionTo(status string) bool {
+	next, ok := nextOrderStatus[o.Status]
+	return ok && next == status
+}
@@ -2,11 +2,16 @@ package models
 
 import "time"
 
+// MaxOrderItemQuantity es la cantidad máxima de un producto o variante en una orden, también después
+// de combinar las líneas repetidas. Debe coincidir con la regla lte de OrderItemRequestDTO.Quantity.
+const MaxOrderItemQuantity = 10000
+
 // OrderItem representa los productos dentro de un pedido.
 type OrderItem struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	OrderID   uint      `gorm:"not null" json:"order_id"`
 	ProductID uint      `gorm:"not null" json:"product_id"`
+	VariantID *uint     `json:"variant_id,omitempty"`
 	Quantity  int       `gorm:"not null" json:"quantity"`
 	Subtotal  float64   `gorm:"type:decimal(10,2);not null" json:"subtotal"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
@@ -17,6 +22,9 @@ type OrderItem struct {
 
 	// Relación con Product
 	Product Product `gorm:"foreignKey:ProductID" json:"product"`
+
+	// Relación con ProductVariant (opcional)
+	Variant *ProductVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
 }
 
 func (OrderItem) TableName() string {
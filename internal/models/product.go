@@ -5,12 +5,19 @@ import (
 )
 
 type Product struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
-	Price     float64   `gorm:"type:decimal(10,2);not null" json:"price"`
-	Stock     int       `gorm:"not null" json:"stock"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `gorm:"type:varchar(255);not null;index" json:"name"`
+	Price      float64   `gorm:"type:decimal(10,2);not null;index" json:"price"`
+	Stock      int       `gorm:"not null;index" json:"stock"`
+	CategoryID *uint     `gorm:"index" json:"category_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relación con Category
+	Category *Category `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL" json:"category,omitempty"`
+
+	// Relación con ProductVariants
+	Variants []ProductVariant `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"variants"`
 }
 
 func (Product) TableName() string {
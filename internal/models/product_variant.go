@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ProductVariant representa una variante de un producto (talla, color, etc.) con su propio SKU y stock.
+type ProductVariant struct {
+	ID         uint              `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductID  uint              `gorm:"not null;index" json:"product_id"`
+	SKU        string            `gorm:"type:varchar(64);not null;uniqueIndex" json:"sku"`
+	Attributes map[string]string `gorm:"type:json;serializer:json" json:"attributes"`
+	Price      *float64          `gorm:"type:decimal(10,2)" json:"price,omitempty"`
+	Stock      int               `gorm:"not null;index" json:"stock"`
+	CreatedAt  time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// EffectivePrice devuelve el precio de la variante o, si no lo sobrescribe, el del producto padre.
+func (v ProductVariant) EffectivePrice(productPrice float64) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return productPrice
+}
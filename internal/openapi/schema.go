@@ -0,0 +1,145 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+)
+
+// addSchema genera el esquema de un DTO a partir de sus tags json y validate y lo registra
+// en los componentes con el nombre del tipo Go, que es el que se usa en las referencias
+func addSchema(schemas openapi3.Schemas, value interface{}) {
+	schemaRef, err := openapi3gen.NewSchemaRefForValue(value, schemas, openapi3gen.SchemaCustomizer(applyValidateTag))
+	if err != nil {
+		// Los DTOs son tipos estáticos: un error aquí es un error de programación
+		panic(err)
+	}
+
+	t := reflect.TypeOf(value)
+	applyRequired(t, schemaRef.Value)
+	schemas[t.Name()] = schemaRef.Value.NewRef()
+}
+
+// applyValidateTag traduce las reglas de go-playground/validator a restricciones del esquema.
+// Solo se consideran las reglas anteriores a "dive", que son las que aplican al propio campo.
+func applyValidateTag(_ string, _ reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	// Los objetos no tienen restricciones de valor; openapi3gen también llama a esta función
+	// con el tag del campo para el esquema de los elementos de un slice de structs
+	if schema.Type.Is(openapi3.TypeObject) {
+		return nil
+	}
+
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return nil
+		case "gt", "gte", "min":
+			if value, err := strconv.ParseFloat(param, 64); err == nil {
+				switch {
+				case schema.Type.Is(openapi3.TypeString):
+					schema.MinLength = uint64(value)
+				case schema.Type.Is(openapi3.TypeArray):
+					schema.MinItems = uint64(value)
+				default:
+					schema.Min = &value
+					schema.ExclusiveMin = name == "gt"
+				}
+			}
+		case "lt", "lte", "max":
+			if value, err := strconv.ParseFloat(param, 64); err == nil {
+				limit := uint64(value)
+				switch {
+				case schema.Type.Is(openapi3.TypeString):
+					schema.MaxLength = &limit
+				case schema.Type.Is(openapi3.TypeArray):
+					schema.MaxItems = &limit
+				default:
+					schema.Max = &value
+					schema.ExclusiveMax = name == "lt"
+				}
+			}
+		case "oneof":
+			for _, option := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, option)
+			}
+		}
+	}
+	return nil
+}
+
+// applyRequired marca como obligatorias las propiedades con la regla validate:"required",
+// recorriendo en paralelo el tipo Go y el esquema generado
+func applyRequired(t reflect.Type, schema *openapi3.Schema) {
+	if schema == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if schema.Items != nil {
+			applyRequired(t.Elem(), schema.Items.Value)
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+			// Los campos de un struct embebido se publican en el mismo objeto
+			if field.Anonymous && name == "" {
+				applyRequired(field.Type, schema)
+				continue
+			}
+
+			property, ok := schema.Properties[name]
+			if !ok {
+				continue
+			}
+			if hasRule(field.Tag.Get("validate"), "required") {
+				schema.Required = append(schema.Required, name)
+			}
+			applyRequired(field.Type, property.Value)
+		}
+	}
+}
+
+// queryParameters genera los parámetros de consulta de un DTO a partir de sus tags query
+func queryParameters(value interface{}) openapi3.Parameters {
+	t := reflect.TypeOf(value)
+
+	var parameters openapi3.Parameters
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("query")
+		if name == "" {
+			continue
+		}
+
+		fieldSchema, err := openapi3gen.NewSchemaRefForValue(reflect.Zero(field.Type).Interface(), nil)
+		if err != nil {
+			panic(err)
+		}
+		_ = applyValidateTag(name, field.Type, field.Tag, fieldSchema.Value)
+
+		parameters = append(parameters, &openapi3.ParameterRef{Value: openapi3.NewQueryParameter(name).
+			WithSchema(fieldSchema.Value).
+			WithRequired(hasRule(field.Tag.Get("validate"), "required"))})
+	}
+
+	return parameters
+}
+
+func hasRule(validateTag, rule string) bool {
+	for _, r := range strings.Split(validateTag, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,329 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"order_management/internal/dtos"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	Title   = "Order Management API"
+	Version = "2.0.0"
+)
+
+// apiVersion describe un prefijo bajo el que se publican las rutas de la API
+type apiVersion struct {
+	prefix     string
+	deprecated bool
+	// orderResponse es el esquema con el que la versión devuelve las órdenes
+	orderResponse string
+}
+
+// apiVersions lista las versiones publicadas; /api es un alias de la versión 1
+var apiVersions = []apiVersion{
+	{prefix: "/api", deprecated: true, orderResponse: "OrderResponseDTO"},
+	{prefix: "/api/v1", deprecated: true, orderResponse: "OrderResponseDTO"},
+	{prefix: "/api/v2", orderResponse: "OrderV2ResponseDTO"},
+}
+
+// operation describe una ruta de la API para construir el documento
+type operation struct {
+	method      string
+	path        string
+	tag         string
+	summary     string
+	query       interface{}
+	headers     openapi3.Parameters
+	requestBody *openapi3.RequestBody
+	responses   map[int]*openapi3.Response
+}
+
+// NewSpec construye el documento OpenAPI 3 de la API. Los esquemas se generan a partir
+// de los DTOs, por lo que cualquier cambio en sus campos o reglas de validación se refleja aquí.
+func NewSpec() *openapi3.T {
+	schemas := openapi3.Schemas{
+		"Message": messageSchema().NewRef(),
+	}
+	for _, dto := range []interface{}{
+		dtos.OrderRequestDTO{},
+		dtos.OrderResponseDTO{},
+		dtos.OrderV2ResponseDTO{},
+		dtos.UpdateOrderStatusRequestDTO{},
+		dtos.BatchOrderRequestDTO{},
+		dtos.BatchOrderResponseDTO{},
+		dtos.ProductPageResponseDTO{},
+		dtos.UpdateStocRequestkDTO{},
+		dtos.ProductImportReportDTO{},
+		dtos.CategoryRequestDTO{},
+		dtos.CategoryResponseDTO{},
+		dtos.ErrorResponseDTO{},
+	} {
+		addSchema(schemas, dto)
+	}
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       Title,
+			Version:     Version,
+			Description: "API para la gestión de órdenes, productos, variantes y categorías.",
+		},
+		Paths:      openapi3.NewPaths(),
+		Components: &openapi3.Components{Schemas: schemas},
+	}
+
+	for _, version := range apiVersions {
+		for _, op := range operations(version) {
+			operation := op.build()
+			operation.Deprecated = version.deprecated
+			doc.AddOperation(version.prefix+op.path, op.method, operation)
+		}
+	}
+
+	// Resolver las referencias #/components/schemas/... para poder validar solicitudes
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		panic(err)
+	}
+
+	return doc
+}
+
+// operations lista las rutas que registran los handlers en cada versión de la API,
+// relativas al prefijo de la versión
+func operations(version apiVersion) []operation {
+	return []operation{
+		{
+			method:  http.MethodGet,
+			path:    "/products",
+			tag:     "products",
+			summary: "Busca, filtra, ordena y pagina el catálogo de productos",
+			query:   dtos.ProductListQueryDTO{},
+			responses: map[int]*openapi3.Response{
+				http.StatusOK:         jsonResponse("Página de productos con sus variantes", "ProductPageResponseDTO"),
+				http.StatusBadRequest: errorResponse("Parámetros de consulta inválidos"),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/products/export",
+			tag:     "products",
+			summary: "Exporta el catálogo completo en CSV",
+			responses: map[int]*openapi3.Response{
+				http.StatusOK: csvResponse("Productos y variantes en CSV. Los textos que empiezan con =, +, -, @, tabulación, retorno de carro o apóstrofo llevan un apóstrofo adelante, que la importación quita"),
+			},
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/products/import",
+			tag:     "products",
+			summary: "Importa productos y variantes desde un CSV, todo o nada",
+			query:   dtos.ProductImportQueryDTO{},
+			headers: idempotencyKeyHeader("Clave para reintentar la importación sin aplicarla dos veces; la respuesta se repite durante 24 horas"),
+			requestBody: openapi3.NewRequestBody().
+				WithRequired(true).
+				WithContent(openapi3.Content{
+					"text/csv": openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema().WithFormat("binary")),
+					"multipart/form-data": openapi3.NewMediaType().WithSchema(openapi3.NewObjectSchema().
+						WithProperty("file", openapi3.NewStringSchema().WithFormat("binary"))),
+				}),
+			responses: map[int]*openapi3.Response{
+				http.StatusOK:                  jsonResponse("Reporte de la importación", "ProductImportReportDTO"),
+				http.StatusBadRequest:          errorResponse("Archivo CSV inválido"),
+				http.StatusConflict:            errorResponse("Solicitud idempotente en curso"),
+				http.StatusUnprocessableEntity: jsonResponse("Reporte con filas rechazadas; no se aplicó ningún cambio", "ProductImportReportDTO"),
+			},
+		},
+		{
+			method:      http.MethodPut,
+			path:        "/products/{id}/stock",
+			tag:         "products",
+			summary:     "Actualiza el stock de un producto",
+			headers:     idempotencyKeyHeader("Clave para reintentar el ajuste sin aplicarlo dos veces; la respuesta se repite durante una hora"),
+			requestBody: jsonRequestBody("UpdateStocRequestkDTO"),
+			responses: map[int]*openapi3.Response{
+				http.StatusOK:                  jsonResponse("Stock actualizado", "Message"),
+				http.StatusBadRequest:          errorResponse("ID o payload inválido"),
+				http.StatusConflict:            errorResponse("Solicitud idempotente en curso"),
+				http.StatusUnprocessableEntity: errorResponse("La clave de idempotencia ya se usó con otra solicitud"),
+				http.StatusNotFound:            errorResponse("El producto no existe"),
+			},
+		},
+		{
+			method:      http.MethodPut,
+			path:        "/products/{id}/variants/{variantId}/stock",
+			tag:         "products",
+			summary:     "Actualiza el stock de una variante de producto",
+			headers:     idempotencyKeyHeader("Clave para reintentar el ajuste sin aplicarlo dos veces; la respuesta se repite durante una hora"),
+			requestBody: jsonRequestBody("UpdateStocRequestkDTO"),
+			responses: map[int]*openapi3.Response{
+				http.StatusOK:                  jsonResponse("Stock actualizado", "Message"),
+				http.StatusBadRequest:          errorResponse("ID o payload inválido"),
+				http.StatusConflict:            errorResponse("Solicitud idempotente en curso"),
+				http.StatusUnprocessableEntity: errorResponse("La clave de idempotencia ya se usó con otra solicitud"),
+				http.StatusNotFound:            errorResponse("La variante no existe o no pertenece al producto"),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/categories",
+			tag:     "categories",
+			summary: "Obtiene el árbol de categorías",
+			responses: map[int]*openapi3.Response{
+				http.StatusOK: jsonArrayResponse("Categorías raíz con sus subcategorías", "CategoryResponseDTO"),
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/categories",
+			tag:         "categories",
+			summary:     "Crea una categoría",
+			headers:     idempotencyKeyHeader("Clave para reintentar la solicitud sin crear categorías duplicadas"),
+			requestBody: jsonRequestBody("CategoryRequestDTO"),
+			responses: map[int]*openapi3.Response{
+				http.StatusCreated:             jsonResponse("Categoría creada", "CategoryResponseDTO"),
+				http.StatusBadRequest:          errorResponse("Payload inválido"),
+				http.StatusConflict:            errorResponse("Solicitud idempotente en curso"),
+				http.StatusUnprocessableEntity: errorResponse("La categoría padre no existe o la clave de idempotencia ya se usó con otra solicitud"),
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/orders",
+			tag:         "orders",
+			summary:     "Crea una orden descontando el stock de sus productos",
+			headers:     idempotencyKeyHeader("Clave del cliente para reintentar la solicitud sin crear órdenes duplicadas"),
+			requestBody: jsonRequestBody("OrderRequestDTO"),
+			responses: map[int]*openapi3.Response{
+				http.StatusCreated:             jsonResponse("Orden creada; la cabecera Location apunta a la orden", version.orderResponse),
+				http.StatusBadRequest:          errorResponse("Payload inválido"),
+				http.StatusNotFound:            errorResponse("Producto o variante inexistente"),
+				http.StatusConflict:            errorResponse("Stock insuficiente o solicitud idempotente en curso"),
+				http.StatusUnprocessableEntity: errorResponse("La variante no pertenece al producto o la clave de idempotencia ya se usó con otra solicitud"),
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/orders/batch",
+			tag:         "orders",
+			summary:     "Crea un lote de órdenes, todas o ninguna (all_or_nothing) o cada una por separado (independent)",
+			headers:     idempotencyKeyHeader("Clave para reintentar el lote completo; cada orden puede traer además su propia idempotency_key"),
+			requestBody: jsonRequestBody("BatchOrderRequestDTO"),
+			responses: map[int]*openapi3.Response{
+				http.StatusOK:                  jsonResponse("Todas las órdenes se crearon o ya existían", "BatchOrderResponseDTO"),
+				http.StatusMultiStatus:         jsonResponse("Modo independent: algunas órdenes fallaron", "BatchOrderResponseDTO"),
+				http.StatusBadRequest:          errorResponse("Payload inválido"),
+				http.StatusConflict:            errorResponse("Solicitud idempotente en curso"),
+				http.StatusUnprocessableEntity: jsonResponse("Modo all_or_nothing: alguna orden falló y no se creó ninguna", "BatchOrderResponseDTO"),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/orders/{id}",
+			tag:     "orders",
+			summary: "Obtiene una orden por su ID",
+			responses: map[int]*openapi3.Response{
+				http.StatusOK:         jsonResponse("Orden encontrada", version.orderResponse),
+				http.StatusBadRequest: errorResponse("ID inválido"),
+				http.StatusNotFound:   errorResponse("La orden no existe"),
+			},
+		},
+		{
+			method:      http.MethodPut,
+			path:        "/orders/{id}/status",
+			tag:         "orders",
+			summary:     "Avanza el estado de una orden: pending, confirmed, shipped y delivered, de a uno",
+			headers:     idempotencyKeyHeader("Clave para reintentar el cambio de estado sin aplicarlo dos veces"),
+			requestBody: jsonRequestBody("UpdateOrderStatusRequestDTO"),
+			responses: map[int]*openapi3.Response{
+				http.StatusOK:                  jsonResponse("Orden con el nuevo estado", version.orderResponse),
+				http.StatusBadRequest:          errorResponse("ID o payload inválido"),
+				http.StatusNotFound:            errorResponse("La orden no existe"),
+				http.StatusConflict:            errorResponse("La orden no puede pasar a ese estado o solicitud idempotente en curso"),
+				http.StatusUnprocessableEntity: errorResponse("La clave de idempotencia ya se usó con otra solicitud"),
+			},
+		},
+	}
+}
+
+// build convierte la descripción en una operación OpenAPI
+func (op operation) build() *openapi3.Operation {
+	result := openapi3.NewOperation()
+	result.Tags = []string{op.tag}
+	result.Summary = op.summary
+	result.Responses = openapi3.NewResponsesWithCapacity(len(op.responses) + 1)
+
+	for _, name := range pathParameterNames(op.path) {
+		result.AddParameter(openapi3.NewPathParameter(name).WithSchema(openapi3.NewIntegerSchema().WithMin(1)))
+	}
+	if op.query != nil {
+		result.Parameters = append(result.Parameters, queryParameters(op.query)...)
+	}
+	result.Parameters = append(result.Parameters, op.headers...)
+
+	if op.requestBody != nil {
+		result.RequestBody = &openapi3.RequestBodyRef{Value: op.requestBody}
+	}
+
+	for status, response := range op.responses {
+		result.AddResponse(status, response)
+	}
+	result.AddResponse(http.StatusInternalServerError, errorResponse("Error interno"))
+
+	return result
+}
+
+// pathParameterNames extrae los nombres de los parámetros {name} de una ruta
+func pathParameterNames(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, strings.Trim(segment, "{}"))
+		}
+	}
+	return names
+}
+
+// idempotencyKeyHeader describe la cabecera Idempotency-Key que aceptan las rutas que modifican datos
+func idempotencyKeyHeader(description string) openapi3.Parameters {
+	return openapi3.Parameters{
+		{Value: openapi3.NewHeaderParameter("Idempotency-Key").
+			WithDescription(description + ". Admite hasta 128 caracteres. Los reintentos repiten el código, las cabeceras y el cuerpo de la respuesta original con Idempotent-Replayed: true; los errores liberan la clave. Reutilizarla con otro cuerpo devuelve 422").
+			WithSchema(openapi3.NewStringSchema())},
+	}
+}
+
+func schemaRef(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+}
+
+func jsonRequestBody(schemaName string) *openapi3.RequestBody {
+	return openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(schemaRef(schemaName))
+}
+
+func jsonResponse(description, schemaName string) *openapi3.Response {
+	return openapi3.NewResponse().WithDescription(description).WithJSONSchemaRef(schemaRef(schemaName))
+}
+
+func jsonArrayResponse(description, schemaName string) *openapi3.Response {
+	arraySchema := openapi3.NewArraySchema()
+	arraySchema.Items = schemaRef(schemaName)
+	return openapi3.NewResponse().WithDescription(description).WithJSONSchema(arraySchema)
+}
+
+func csvResponse(description string) *openapi3.Response {
+	return openapi3.NewResponse().WithDescription(description).WithContent(openapi3.Content{
+		"text/csv": openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema()),
+	})
+}
+
+func errorResponse(description string) *openapi3.Response {
+	return jsonResponse(description, "ErrorResponseDTO")
+}
+
+// messageSchema describe las respuestas {"message": "..."} de las operaciones sin cuerpo propio
+func messageSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().WithProperty("message", openapi3.NewStringSchema())
+}
@@ -0,0 +1,93 @@
+package openapi_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"testing"
+
+	"order_management/internal/handlers"
+	"order_management/internal/models"
+	"order_management/internal/openapi"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var echoPathParam = regexp.MustCompile(`:([^/]+)`)
+
+// registeredRoutes registra los handlers de la API tal como lo hace cmd/main.go y devuelve
+// sus rutas con el formato "METODO /ruta/{param}" usado por OpenAPI
+func registeredRoutes() []string {
+	e := echo.New()
+	handlers.RegisterAPIRoutes(e, handlers.Services{}, nil)
+
+	var routes []string
+	for _, route := range e.Routes() {
+		// Los grupos con middlewares registran rutas internas para las solicitudes no encontradas
+		if route.Method == echo.RouteNotFound {
+			continue
+		}
+		routes = append(routes, route.Method+" "+echoPathParam.ReplaceAllString(route.Path, "{$1}"))
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// documentedRoutes devuelve las operaciones del documento OpenAPI con el mismo formato
+func documentedRoutes() []string {
+	var routes []string
+	for path, item := range openapi.NewSpec().Paths.Map() {
+		for method := range item.Operations() {
+			routes = append(routes, method+" "+path)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// TestSpecMatchesRegisteredRoutes falla si se agrega, elimina o renombra una ruta sin actualizar el documento
+func TestSpecMatchesRegisteredRoutes(t *testing.T) {
+	assert.Equal(t, registeredRoutes(), documentedRoutes())
+}
+
+// TestSpecIsValid verifica que el documento generado cumpla con OpenAPI 3
+func TestSpecIsValid(t *testing.T) {
+	doc := openapi.NewSpec()
+
+	assert.NoError(t, doc.Validate(context.Background()))
+}
+
+// TestSpecSchemasFollowValidateTags verifica que las reglas de validación de los DTOs se reflejen en los esquemas
+func TestSpecSchemasFollowValidateTags(t *testing.T) {
+	doc := openapi.NewSpec()
+
+	orderRequest := doc.Components.Schemas["OrderRequestDTO"].Value
+	assert.ElementsMatch(t, []string{"customer_name", "items"}, orderRequest.Required)
+
+	item := orderRequest.Properties["items"].Value.Items.Value
+	assert.ElementsMatch(t, []string{"product_id", "quantity"}, item.Required)
+	assert.True(t, item.Properties["quantity"].Value.ExclusiveMin)
+	if assert.NotNil(t, item.Properties["quantity"].Value.Max) {
+		assert.Equal(t, float64(models.MaxOrderItemQuantity), *item.Properties["quantity"].Value.Max)
+	}
+
+	listProducts := doc.Paths.Find("/api/products").GetOperation(http.MethodGet)
+	assert.NotNil(t, listProducts.Parameters.GetByInAndName("query", "page_size"))
+}
+
+// TestSpecDocumentsAPIVersions verifica que v1 y su alias se marquen obsoletos y que v2 use sus propios esquemas
+func TestSpecDocumentsAPIVersions(t *testing.T) {
+	doc := openapi.NewSpec()
+
+	for _, prefix := range []string{"/api", "/api/v1"} {
+		getOrder := doc.Paths.Find(prefix + "/orders/{id}").GetOperation(http.MethodGet)
+		assert.True(t, getOrder.Deprecated, prefix)
+		assert.Equal(t, "#/components/schemas/OrderResponseDTO", getOrder.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema.Ref)
+	}
+
+	getOrder := doc.Paths.Find("/api/v2/orders/{id}").GetOperation(http.MethodGet)
+	assert.False(t, getOrder.Deprecated)
+	assert.Equal(t, "#/components/schemas/OrderV2ResponseDTO", getOrder.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema.Ref)
+}
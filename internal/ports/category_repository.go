@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"order_management/internal/models"
+)
+
+// CategoryRepository define las operaciones disponibles para gestionar categorías.
+type CategoryRepository interface {
+	GetAll(ctx context.Context) ([]models.Category, error)
+	GetByID(ctx context.Context, id uint) (*models.Category, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]models.Category, error)
+	Create(ctx context.Context, category *models.Category) error
+}
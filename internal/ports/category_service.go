@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"order_management/internal/models"
+)
+
+// CategoryService define los métodos disponibles para manejar el árbol de categorías.
+type CategoryService interface {
+	GetCategoryTree(ctx context.Context) ([]models.Category, error)
+	CreateCategory(ctx context.Context, category *models.Category) error
+	GetCategoriesByIDs(ctx context.Context, ids []uint) ([]models.Category, error)
+}
@@ -0,0 +1,48 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"order_management/internal/models"
+)
+
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventStockChanged       = "stock.changed"
+)
+
+// Event representa un cambio de estado que se notifica en tiempo real a los clientes suscritos.
+// Order se informa en los eventos de órdenes y Stock en los de stock.
+type Event struct {
+	Type       string
+	OccurredAt time.Time
+	Order      *models.Order
+	Stock      *StockChange
+}
+
+// StockChange representa el nuevo stock de un producto o, si VariantID no es nil, de una de sus variantes
+type StockChange struct {
+	ProductID uint
+	VariantID *uint
+	Stock     int
+}
+
+// EventFilter restringe los eventos que recibe un suscriptor. Los campos vacíos no filtran y,
+// si se informan varios, el evento debe cumplirlos todos.
+type EventFilter struct {
+	OrderID      uint
+	ProductID    uint
+	CustomerName string
+}
+
+// EventPublisher publica los eventos generados por los servicios una vez confirmados los cambios
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// EventSubscriber entrega a cada suscriptor los eventos que cumplen su filtro hasta que cancela la suscripción
+type EventSubscriber interface {
+	Subscribe(filter EventFilter) (events <-chan Event, cancel func())
+}
@@ -0,0 +1,15 @@
+package ports
+
+import "context"
+
+// HealthChecker verifica que una dependencia de la aplicación responda antes de que venza ctx
+type HealthChecker interface {
+	Check(ctx context.Context) error
+}
+
+// HealthCheckFunc permite usar una función como HealthChecker
+type HealthCheckFunc func(ctx context.Context) error
+
+func (f HealthCheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
@@ -0,0 +1,72 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	IdempotencyInProgress = "IN_PROGRESS"
+	IdempotencyCompleted  = "COMPLETED"
+)
+
+// ErrIdempotencyLockLost indica que la reserva venció y otra solicitud tomó la clave
+var ErrIdempotencyLockLost = errors.New("la reserva de la clave de idempotencia venció")
+
+// IdempotencyRecord es el estado guardado de una clave de idempotencia
+type IdempotencyRecord struct {
+	Status string `json:"status"`
+	// Fingerprint identifica la solicitud original para rechazar la reutilización de la clave con otro contenido
+	Fingerprint string            `json:"fingerprint"`
+	StatusCode  int               `json:"status_code,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	// Response guarda el cuerpo byte a byte para repetirlo sin cambios, aunque no sea JSON
+	Response []byte `json:"response"`
+	// ExpiresAt es el vencimiento de la clave; lo informa el store al leerla
+	ExpiresAt time.Time `json:"-"`
+}
+
+// IdempotencyOptions define los tiempos de una clave
+type IdempotencyOptions struct {
+	// Lease es la duración de la reserva mientras la solicitud está en curso
+	Lease time.Duration
+	// TTL es el tiempo durante el que se repite la respuesta; 0 usa el TTL del store
+	TTL time.Duration
+}
+
+// IdempotencyStore guarda las claves de idempotencia y sus respuestas.
+type IdempotencyStore interface {
+	// Acquire reserva la clave de forma atómica durante options.Lease. Si la clave ya existía devuelve
+	// sus datos sin modificarlos; si la reserva tuvo éxito devuelve el lock y datos nil.
+	Acquire(ctx context.Context, key, fingerprint string, options IdempotencyOptions) (IdempotencyLock, *IdempotencyRecord, error)
+	// Get devuelve los datos de la clave, o nil si no existe o venció
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Delete elimina la clave, esté en curso o completada. Devuelve false si no existía.
+	Delete(ctx context.Context, key string) (bool, error)
+}
+
+// IdempotencyLock es la reserva de una clave en curso. Renovarla, completarla o liberarla después de
+// que otra solicitud la tomó devuelve ErrIdempotencyLockLost sin modificar la clave.
+type IdempotencyLock interface {
+	// KeepAlive renueva la reserva hasta que se llama a la función devuelta, que puede llamarse más de una
+	// vez, o hasta que se cancela ctx. Si otra solicitud toma la clave llama a lost, que puede ser nil,
+	// para que quien la reservó deje de trabajar con ella.
+	KeepAlive(ctx context.Context, lost func()) (stop func())
+	// Complete guarda la respuesta para devolverla en los reintentos
+	Complete(ctx context.Context, record IdempotencyRecord) error
+	// Release libera la reserva para que la operación pueda reintentarse
+	Release(ctx context.Context) error
+}
+
+// TransactionalIdempotencyLock es la reserva de un store que guarda las claves en la misma base de
+// datos que las órdenes, lo que permite asociar la clave con la orden en la transacción que la crea.
+type TransactionalIdempotencyLock interface {
+	IdempotencyLock
+	// LockTx bloquea la clave dentro de tx y devuelve el ID de la orden ya asociada, o 0 si aún no tiene ninguna
+	LockTx(tx *gorm.DB) (uint, error)
+	// BindTx asocia la clave con la orden creada dentro de tx
+	BindTx(tx *gorm.DB, orderID uint) error
+}
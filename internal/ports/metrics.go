@@ -0,0 +1,45 @@
+package ports
+
+import "time"
+
+// Motivos por los que no se crea una orden, para las métricas de negocio
+const (
+	OrderFailureStock           = "stock"
+	OrderFailureProductNotFound = "product_not_found"
+	OrderFailureDatabase        = "database"
+	OrderFailureBatchAborted    = "batch_aborted"
+	OrderFailureCanceled        = "canceled"
+	OrderFailureOther           = "other"
+)
+
+// Motivos por los que se rechaza una solicitud con una clave de idempotencia ya usada
+const (
+	IdempotencyConflictInProgress = "in_progress"
+	IdempotencyConflictMismatch   = "fingerprint_mismatch"
+)
+
+// OrderMetrics registra las métricas de negocio de las órdenes
+type OrderMetrics interface {
+	// OrderCreated registra una orden confirmada con su importe total
+	OrderCreated(totalAmount float64)
+	// OrderFailed registra una orden que no se creó, con uno de los motivos OrderFailure*
+	OrderFailed(reason string)
+}
+
+// IdempotencyMetrics registra cómo se resuelven las claves de idempotencia
+type IdempotencyMetrics interface {
+	// IdempotencyMiss registra una clave nueva, que la solicitud toma para ejecutarse
+	IdempotencyMiss()
+	// IdempotencyHit registra una clave que ya existía en el store
+	IdempotencyHit()
+	// IdempotencyReplay registra una respuesta que se repite desde una clave completada
+	IdempotencyReplay()
+	// IdempotencyConflict registra una clave que se rechaza, con uno de los motivos IdempotencyConflict*
+	IdempotencyConflict(reason string)
+}
+
+// HTTPMetrics registra la duración de las solicitudes HTTP
+type HTTPMetrics interface {
+	// ObserveHTTPRequest registra una solicitud terminada por método, ruta registrada y código de respuesta
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
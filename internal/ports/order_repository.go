@@ -1,13 +1,25 @@
 package ports
 
 import (
+	"context"
+
 	"order_management/internal/models"
 
 	"gorm.io/gorm"
 )
 
+// OrderFilter agrupa los criterios de búsqueda y paginación de órdenes
+type OrderFilter struct {
+	CustomerName string
+	Page         int
+	PageSize     int
+}
+
 // OrderRepository define las operaciones disponibles para gestionar órdenes.
 type OrderRepository interface {
-	Create(order *models.Order, tx *gorm.DB) error
-	FindByID(id uint) (*models.Order, error)
+	Create(ctx context.Context, order *models.Order, tx *gorm.DB) error
+	FindByID(ctx context.Context, id uint) (*models.Order, error)
+	Search(ctx context.Context, filter OrderFilter) ([]models.Order, int64, error)
+	// UpdateStatus cambia el estado de la orden solo si sigue en el estado from e indica si la actualizó
+	UpdateStatus(ctx context.Context, id uint, from, to string) (bool, error)
 }
@@ -1,11 +1,23 @@
 package ports
 
 import (
+	"context"
+
 	"order_management/internal/models"
 )
 
 // OrderService define los métodos disponibles para manejar órdenes.
 type OrderService interface {
-	CreateOrder(order *models.Order) error
-	GetOrderById(id uint) (*models.Order, error)
+	CreateOrder(ctx context.Context, order *models.Order) error
+	// CreateOrderIdempotent crea la orden como CreateOrder. Si lock es una reserva transaccional, la
+	// clave se asocia con la orden en la misma transacción y, si ya tenía una orden asociada, se
+	// devuelve esa orden sin crear otra. lock puede ser nil.
+	CreateOrderIdempotent(ctx context.Context, order *models.Order, lock IdempotencyLock) error
+	// CreateOrders crea un lote de órdenes. locks[i] es la reserva de la clave de orders[i], o nil si
+	// no tiene; locks puede ser nil.
+	CreateOrders(ctx context.Context, orders []*models.Order, locks []IdempotencyLock, allOrNothing bool) []error
+	GetOrderById(ctx context.Context, id uint) (*models.Order, error)
+	SearchOrders(ctx context.Context, filter OrderFilter) ([]models.Order, int64, error)
+	// UpdateOrderStatus avanza la orden al estado indicado y devuelve la orden actualizada
+	UpdateOrderStatus(ctx context.Context, id uint, status string) (*models.Order, error)
 }
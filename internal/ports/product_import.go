@@ -0,0 +1,38 @@
+package ports
+
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionError  = "error"
+)
+
+// ProductImportRow representa una fila de la importación masiva de productos.
+// Si SKU está vacío la fila describe un producto; en caso contrario describe una variante de ProductID.
+// Los campos nil o vacíos no se modifican al actualizar un registro existente.
+type ProductImportRow struct {
+	Line       int
+	ProductID  uint
+	Name       string
+	Price      *float64
+	Stock      *int
+	CategoryID *uint
+	SKU        string
+	Attributes map[string]string
+	Errors     []string
+}
+
+// ProductImportResult representa el resultado de procesar una fila de la importación.
+type ProductImportResult struct {
+	Line      int
+	Action    string
+	ProductID uint
+	VariantID uint
+	SKU       string
+	Errors    []string
+}
+
+// ProductImportReport agrupa el resultado de todas las filas y si los cambios se confirmaron.
+type ProductImportReport struct {
+	Results []ProductImportResult
+	Applied bool
+}
@@ -1,15 +1,38 @@
 package ports
 
 import (
+	"context"
+
 	"order_management/internal/models"
 
 	"gorm.io/gorm"
 )
 
+// ProductFilter agrupa los criterios de búsqueda, ordenamiento y paginación del catálogo
+type ProductFilter struct {
+	// Query busca los productos cuyo nombre contiene el texto o con una variante cuyo SKU empieza con él
+	Query      string
+	CategoryID *uint
+	MinPrice   *float64
+	MaxPrice   *float64
+	InStock    bool
+	SortBy     string
+	SortDesc   bool
+	Page       int
+	PageSize   int
+}
+
 // ProductRepository define las operaciones que pueden realizarse sobre la entidad Product
 type ProductRepository interface {
-	GetAll() ([]models.Product, error)
-	GetByID(id uint, tx *gorm.DB) (*models.Product, error)
-	Update(product *models.Product) error
-	UpdateStock(id uint, newStock int, tx *gorm.DB) error
+	Search(ctx context.Context, filter ProductFilter) ([]models.Product, int64, error)
+	GetByID(ctx context.Context, id uint, tx *gorm.DB) (*models.Product, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]models.Product, error)
+	Update(ctx context.Context, product *models.Product) error
+	UpdateStock(ctx context.Context, id uint, newStock int, tx *gorm.DB) error
+	GetVariantByID(ctx context.Context, id uint, tx *gorm.DB) (*models.ProductVariant, error)
+	UpdateVariantStock(ctx context.Context, id uint, newStock int, tx *gorm.DB) error
+	Save(ctx context.Context, product *models.Product, tx *gorm.DB) error
+	GetVariantBySKU(ctx context.Context, sku string, tx *gorm.DB) (*models.ProductVariant, error)
+	SaveVariant(ctx context.Context, variant *models.ProductVariant, tx *gorm.DB) error
+	FindInBatches(ctx context.Context, batchSize int, fn func(products []models.Product) error) error
 }
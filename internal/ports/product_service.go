@@ -1,10 +1,16 @@
 package ports
 
 import (
+	"context"
+
 	"order_management/internal/models"
 )
 
 type ProductService interface {
-	GetAllProducts() ([]models.Product, error)
-	UpdateStock(id uint, stock int) error
+	SearchProducts(ctx context.Context, filter ProductFilter) ([]models.Product, int64, error)
+	GetProductsByIDs(ctx context.Context, ids []uint) ([]models.Product, error)
+	UpdateStock(ctx context.Context, id uint, stock int) error
+	UpdateVariantStock(ctx context.Context, productID uint, variantID uint, stock int) error
+	ImportProducts(ctx context.Context, rows []ProductImportRow, dryRun bool) (*ProductImportReport, error)
+	ExportProducts(ctx context.Context, fn func(products []models.Product) error) error
 }
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+
+	"order_management/internal/models"
+	"order_management/internal/ports"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRepositoryImpl implementa CategoryRepository usando GORM.
+type CategoryRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository crea una nueva instancia de CategoryRepositoryImpl.
+func NewCategoryRepository(db *gorm.DB) ports.CategoryRepository {
+	return &CategoryRepositoryImpl{db: db}
+}
+
+// GetAll obtiene todas las categorías sin anidar.
+func (r *CategoryRepositoryImpl) GetAll(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.WithContext(ctx).Order("name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetByID busca una categoría por ID.
+func (r *CategoryRepositoryImpl) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// FindByIDs obtiene las categorías indicadas que existan, en una sola consulta.
+func (r *CategoryRepositoryImpl) FindByIDs(ctx context.Context, ids []uint) ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// Create inserta una nueva categoría en la base de datos.
+func (r *CategoryRepositoryImpl) Create(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"order_management/internal/models"
 	"order_management/internal/ports"
 
@@ -18,16 +20,58 @@ func NewOrderRepository(db *gorm.DB) ports.OrderRepository {
 }
 
 // Create inserta una nueva orden en la base de datos.
-func (r *OrderRepositoryImpl) Create(order *models.Order, tx *gorm.DB) error {
-	return tx.Create(order).Error
+func (r *OrderRepositoryImpl) Create(ctx context.Context, order *models.Order, tx *gorm.DB) error {
+	return tx.WithContext(ctx).Create(order).Error
 }
 
 // FindByID busca una orden por ID.
-func (r *OrderRepositoryImpl) FindByID(id uint) (*models.Order, error) {
+func (r *OrderRepositoryImpl) FindByID(ctx context.Context, id uint) (*models.Order, error) {
 	var order models.Order
-	err := r.db.Preload("OrderItems.Product").First(&order, id).Error
+	err := r.db.WithContext(ctx).Preload("OrderItems.Product").Preload("OrderItems.Variant").First(&order, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
+
+// UpdateStatus cambia el estado de la orden solo si sigue en el estado from, para que dos cambios
+// concurrentes no avancen la orden desde el mismo estado
+func (r *OrderRepositoryImpl) UpdateStatus(ctx context.Context, id uint, from, to string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Search obtiene una página de órdenes, de la más reciente a la más antigua, junto con el total de coincidencias.
+// Solo carga los items; los productos se resuelven aparte para poder agruparlos en una única consulta.
+func (r *OrderRepositoryImpl) Search(ctx context.Context, filter ports.OrderFilter) ([]models.Order, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Order{})
+
+	if filter.CustomerName != "" {
+		query = query.Where("customer_name LIKE ?", "%"+filter.CustomerName+"%")
+	}
+
+	// Reutilizar las condiciones tanto para el conteo como para la página
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []models.Order
+	err := query.
+		Preload("OrderItems").
+		Order("id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
@@ -1,7 +1,11 @@
 package repositories
 
 import (
+	"context"
+	"strings"
+
 	"order_management/internal/models"
+	"order_management/internal/ports"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -17,32 +21,174 @@ func NewProductRepository(db *gorm.DB) *ProductRepositoryImpl {
 	return &ProductRepositoryImpl{db: db}
 }
 
-// GetAll obtiene todos los productos de la base de datos
-func (r *ProductRepositoryImpl) GetAll() ([]models.Product, error) {
+// productSortColumns lista las columnas por las que se permite ordenar el catálogo
+var productSortColumns = map[string]string{
+	"id":         "products.id",
+	"name":       "products.name",
+	"price":      "products.price",
+	"stock":      "products.stock",
+	"created_at": "products.created_at",
+}
+
+// likeEscaper escapa los comodines de LIKE con '!', que a diferencia de la barra invertida se
+// escribe igual en MySQL y en SQLite
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// likePrefix arma el patrón LIKE de los valores que empiezan con value. Los comodines de value se
+// buscan literalmente para que el patrón nunca empiece con % y la consulta pueda usar el índice.
+func likePrefix(value string) string {
+	return likeEscaper.Replace(value) + "%"
+}
+
+// likeContains arma el patrón LIKE de los valores que contienen value, con sus comodines literales
+func likeContains(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
+}
+
+// productSearchMatches devuelve los IDs de los productos cuyo nombre contiene la búsqueda o que tienen
+// una variante cuyo SKU empieza con ella. Cada rama del UNION usa su propio índice: el nombre se busca
+// recorriendo solo idx_products_name, que contiene el nombre y el ID, y el SKU por rango en
+// idx_product_variants_sku. Con un OR en el WHERE de products MySQL no puede usar ninguno de los dos.
+const productSearchMatches = `SELECT id FROM products WHERE name LIKE ? ESCAPE '!'
+	UNION
+	SELECT product_id FROM product_variants WHERE sku LIKE ? ESCAPE '!'`
+
+// Search obtiene una página de productos que cumplen el filtro, junto con el total de coincidencias
+func (r *ProductRepositoryImpl) Search(ctx context.Context, filter ports.ProductFilter) ([]models.Product, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Product{})
+
+	// Búsqueda por nombre del producto o por el comienzo del SKU de alguna de sus variantes. El UNION
+	// no repite IDs, por lo que unirlo con products no duplica productos.
+	if filter.Query != "" {
+		query = query.Joins(
+			"JOIN (?) AS search_matches ON search_matches.id = products.id",
+			r.db.Raw(productSearchMatches, likeContains(filter.Query), likePrefix(filter.Query)),
+		)
+	}
+
+	// Filtrar por la categoría indicada y todas sus subcategorías
+	if filter.CategoryID != nil {
+		query = query.Where(
+			"products.category_id IN (?)",
+			r.db.Raw(`WITH RECURSIVE category_tree AS (
+				SELECT id FROM categories WHERE id = ?
+				UNION ALL
+				SELECT c.id FROM categories c JOIN category_tree ct ON c.parent_id = ct.id
+			) SELECT id FROM category_tree`, *filter.CategoryID),
+		)
+	}
+
+	if filter.MinPrice != nil {
+		query = query.Where("products.price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("products.price <= ?", *filter.MaxPrice)
+	}
+
+	// Un producto tiene stock si lo tiene él mismo o alguna de sus variantes
+	if filter.InStock {
+		query = query.Where(
+			"products.stock > 0 OR products.id IN (?)",
+			r.db.Model(&models.ProductVariant{}).Select("product_id").Where("stock > 0"),
+		)
+	}
+
+	// Reutilizar las condiciones tanto para el conteo como para la página
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sortColumn, ok := productSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = productSortColumns["id"]
+	}
+
 	var products []models.Product
-	if err := r.db.Find(&products).Error; err != nil {
-		return nil, err
+	err := query.
+		Preload("Variants").
+		Order(clause.OrderByColumn{Column: clause.Column{Name: sortColumn, Raw: true}, Desc: filter.SortDesc}).
+		Order("products.id").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
 	}
-	return products, nil
+	return products, total, nil
 }
 
 // GetByID obtiene un producto por su ID
-func (r *ProductRepositoryImpl) GetByID(id uint, tx *gorm.DB) (*models.Product, error) {
+func (r *ProductRepositoryImpl) GetByID(ctx context.Context, id uint, tx *gorm.DB) (*models.Product, error) {
 	var product models.Product
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, id).Error; err != nil {
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, id).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
+// FindByIDs obtiene con sus variantes los productos indicados que existan, en una sola consulta
+func (r *ProductRepositoryImpl) FindByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
+	var products []models.Product
+	if err := r.db.WithContext(ctx).Preload("Variants").Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
 // Update actualiza un producto existente en la base de datos
-func (r *ProductRepositoryImpl) Update(product *models.Product) error {
-	return r.db.Save(product).Error
+func (r *ProductRepositoryImpl) Update(ctx context.Context, product *models.Product) error {
+	return r.db.WithContext(ctx).Save(product).Error
 }
 
 // UpdateStock actualiza el stock de un producto.
-func (r *ProductRepositoryImpl) UpdateStock(id uint, newStock int, tx *gorm.DB) error {
-	return tx.Model(&models.Product{}).
+func (r *ProductRepositoryImpl) UpdateStock(ctx context.Context, id uint, newStock int, tx *gorm.DB) error {
+	return tx.WithContext(ctx).Model(&models.Product{}).
 		Where("id = ?", id).
 		Update("stock", newStock).Error
 }
+
+// GetVariantByID obtiene una variante por su ID bloqueando la fila dentro de la transacción
+func (r *ProductRepositoryImpl) GetVariantByID(ctx context.Context, id uint, tx *gorm.DB) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, id).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// UpdateVariantStock actualiza el stock de una variante.
+func (r *ProductRepositoryImpl) UpdateVariantStock(ctx context.Context, id uint, newStock int, tx *gorm.DB) error {
+	return tx.WithContext(ctx).Model(&models.ProductVariant{}).
+		Where("id = ?", id).
+		Update("stock", newStock).Error
+}
+
+// Save inserta o actualiza un producto dentro de la transacción sin tocar sus variantes.
+func (r *ProductRepositoryImpl) Save(ctx context.Context, product *models.Product, tx *gorm.DB) error {
+	return tx.WithContext(ctx).Omit(clause.Associations).Save(product).Error
+}
+
+// GetVariantBySKU obtiene una variante por su SKU bloqueando la fila dentro de la transacción
+func (r *ProductRepositoryImpl) GetVariantBySKU(ctx context.Context, sku string, tx *gorm.DB) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("sku = ?", sku).First(&variant).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// SaveVariant inserta o actualiza una variante dentro de la transacción.
+func (r *ProductRepositoryImpl) SaveVariant(ctx context.Context, variant *models.ProductVariant, tx *gorm.DB) error {
+	return tx.WithContext(ctx).Save(variant).Error
+}
+
+// FindInBatches recorre todo el catálogo en lotes para no cargarlo completo en memoria
+func (r *ProductRepositoryImpl) FindInBatches(ctx context.Context, batchSize int, fn func(products []models.Product) error) error {
+	var products []models.Product
+	return r.db.WithContext(ctx).Preload("Variants").FindInBatches(&products, batchSize, func(_ *gorm.DB, _ int) error {
+		return fn(products)
+	}).Error
+}
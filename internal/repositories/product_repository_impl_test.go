@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"order_management/internal/models"
+	"order_management/internal/ports"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newCatalogDB crea un catálogo en SQLite con una categoría y su subcategoría, y productos con y
+// sin variantes
+func newCatalogDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	assert.NoError(t, db.AutoMigrate(&models.Category{}, &models.Product{}, &models.ProductVariant{}))
+
+	electronics := models.Category{ID: 1, Name: "Electrónica"}
+	laptops := models.Category{ID: 2, Name: "Laptops", ParentID: &electronics.ID}
+	clothing := models.Category{ID: 3, Name: "Ropa"}
+	assert.NoError(t, db.Create([]*models.Category{&electronics, &laptops, &clothing}).Error)
+
+	products := []models.Product{
+		{ID: 1, Name: "Laptop Pro", Price: 1500, Stock: 3, CategoryID: &laptops.ID},
+		{ID: 2, Name: "Laptop Air", Price: 1100, Stock: 0, CategoryID: &laptops.ID},
+		{ID: 3, Name: "Mouse", Price: 25, Stock: 0, CategoryID: &electronics.ID},
+		{ID: 4, Name: "Camisa", Price: 30, Stock: 0, CategoryID: &clothing.ID, Variants: []models.ProductVariant{
+			{SKU: "CAM-M", Stock: 2},
+			{SKU: "CAM-L", Stock: 0},
+		}},
+		{ID: 5, Name: "Funda 100% Laptop", Price: 40, Stock: 8},
+	}
+	assert.NoError(t, db.Create(&products).Error)
+
+	return db
+}
+
+// search devuelve los IDs de la página y el total de coincidencias
+func search(t *testing.T, repo *ProductRepositoryImpl, filter ports.ProductFilter) ([]uint, int64) {
+	if filter.Page == 0 {
+		filter.Page, filter.PageSize = 1, 10
+	}
+	products, total, err := repo.Search(context.Background(), filter)
+	assert.NoError(t, err)
+
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	return ids, total
+}
+
+func TestProductRepository_SearchFilters(t *testing.T) {
+	repo := NewProductRepository(newCatalogDB(t))
+	minPrice, maxPrice := 30.0, 1200.0
+	categoryID := uint(1)
+
+	for name, tc := range map[string]struct {
+		filter   ports.ProductFilter
+		expected []uint
+	}{
+		"sin filtros":                    {ports.ProductFilter{}, []uint{1, 2, 3, 4, 5}},
+		"prefijo del nombre":             {ports.ProductFilter{Query: "Laptop"}, []uint{1, 2, 5}},
+		"palabra dentro del nombre":      {ports.ProductFilter{Query: "Pro"}, []uint{1}},
+		"el SKU se busca por prefijo":    {ports.ProductFilter{Query: "-M"}, []uint{}},
+		"prefijo del SKU":                {ports.ProductFilter{Query: "CAM-"}, []uint{4}},
+		"los comodines son literales":    {ports.ProductFilter{Query: "Funda 100%"}, []uint{5}},
+		"comodín literal":                {ports.ProductFilter{Query: "%Laptop"}, []uint{}},
+		"guion bajo literal":             {ports.ProductFilter{Query: "Lapto_"}, []uint{}},
+		"categoría y subcategorías":      {ports.ProductFilter{CategoryID: &categoryID}, []uint{1, 2, 3}},
+		"rango de precios":               {ports.ProductFilter{MinPrice: &minPrice, MaxPrice: &maxPrice}, []uint{2, 4, 5}},
+		"con stock propio o de variante": {ports.ProductFilter{InStock: true}, []uint{1, 4, 5}},
+		"nombre y SKU sin duplicados":    {ports.ProductFilter{Query: "Cam"}, []uint{4}},
+		"filtros combinados":             {ports.ProductFilter{Query: "Laptop", CategoryID: &categoryID, InStock: true}, []uint{1}},
+	} {
+		ids, total := search(t, repo, tc.filter)
+
+		assert.Equal(t, tc.expected, ids, name)
+		assert.Equal(t, int64(len(tc.expected)), total, name)
+	}
+}
+
+func TestProductRepository_SearchSortsAndPaginates(t *testing.T) {
+	repo := NewProductRepository(newCatalogDB(t))
+
+	// El total cuenta todas las coincidencias, no solo las de la página
+	ids, total := search(t, repo, ports.ProductFilter{SortBy: "price", SortDesc: true, Page: 1, PageSize: 2})
+	assert.Equal(t, []uint{1, 2}, ids)
+	assert.Equal(t, int64(5), total)
+
+	ids, total = search(t, repo, ports.ProductFilter{SortBy: "price", SortDesc: true, Page: 3, PageSize: 2})
+	assert.Equal(t, []uint{3}, ids)
+	assert.Equal(t, int64(5), total)
+
+	ids, _ = search(t, repo, ports.ProductFilter{SortBy: "stock", Page: 1, PageSize: 3})
+	// Los empates se desempatan por ID
+	assert.Equal(t, []uint{2, 3, 4}, ids)
+
+	// Una columna desconocida ordena por ID
+	ids, _ = search(t, repo, ports.ProductFilter{SortBy: "products.name; DROP TABLE products", Page: 2, PageSize: 2})
+	assert.Equal(t, []uint{3, 4}, ids)
+
+	// Las variantes se cargan con el producto
+	products, _, err := repo.Search(context.Background(), ports.ProductFilter{Query: "CAM-M", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, products, 1) {
+		assert.Len(t, products[0].Variants, 2)
+	}
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// startSpan inicia el span de una llamada al repositorio. Las consultas SQL de la llamada cuelgan de él.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan termina el span de la llamada. Un registro inexistente es un resultado esperado y no marca
+// el span como fallido.
+func endSpan(span trace.Span, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+func idAttr(key string, id uint) attribute.KeyValue {
+	return attribute.Int64(key, int64(id))
+}
+
+// tracedProductRepository registra un span por cada llamada a ProductRepository
+type tracedProductRepository struct {
+	next ports.ProductRepository
+}
+
+// NewTracedProductRepository envuelve repo para registrar cada llamada en la traza de la solicitud
+func NewTracedProductRepository(repo ports.ProductRepository) ports.ProductRepository {
+	return &tracedProductRepository{next: repo}
+}
+
+func (r *tracedProductRepository) Search(ctx context.Context, filter ports.ProductFilter) ([]models.Product, int64, error) {
+	ctx, span := startSpan(ctx, "ProductRepository.Search")
+	products, total, err := r.next.Search(ctx, filter)
+	endSpan(span, err)
+	return products, total, err
+}
+
+func (r *tracedProductRepository) GetByID(ctx context.Context, id uint, tx *gorm.DB) (*models.Product, error) {
+	ctx, span := startSpan(ctx, "ProductRepository.GetByID", idAttr("product.id", id))
+	product, err := r.next.GetByID(ctx, id, tx)
+	endSpan(span, err)
+	return product, err
+}
+
+func (r *tracedProductRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
+	ctx, span := startSpan(ctx, "ProductRepository.FindByIDs", attribute.Int("product.count", len(ids)))
+	products, err := r.next.FindByIDs(ctx, ids)
+	endSpan(span, err)
+	return products, err
+}
+
+func (r *tracedProductRepository) Update(ctx context.Context, product *models.Product) error {
+	ctx, span := startSpan(ctx, "ProductRepository.Update", idAttr("product.id", product.ID))
+	err := r.next.Update(ctx, product)
+	endSpan(span, err)
+	return err
+}
+
+func (r *tracedProductRepository) UpdateStock(ctx context.Context, id uint, newStock int, tx *gorm.DB) error {
+	ctx, span := startSpan(ctx, "ProductRepository.UpdateStock", idAttr("product.id", id))
+	err := r.next.UpdateStock(ctx, id, newStock, tx)
+	endSpan(span, err)
+	return err
+}
+
+func (r *tracedProductRepository) GetVariantByID(ctx context.Context, id uint, tx *gorm.DB) (*models.ProductVariant, error) {
+	ctx, span := startSpan(ctx, "ProductRepository.GetVariantByID", idAttr("variant.id", id))
+	variant, err := r.next.GetVariantByID(ctx, id, tx)
+	endSpan(span, err)
+	return variant, err
+}
+
+func (r *tracedProductRepository) UpdateVariantStock(ctx context.Context, id uint, newStock int, tx *gorm.DB) error {
+	ctx, span := startSpan(ctx, "ProductRepository.UpdateVariantStock", idAttr("variant.id", id))
+	err := r.next.UpdateVariantStock(ctx, id, newStock, tx)
+	endSpan(span, err)
+	return err
+}
+
+func (r *tracedProductRepository) Save(ctx context.Context, product *models.Product, tx *gorm.DB) error {
+	ctx, span := startSpan(ctx, "ProductRepository.Save")
+	err := r.next.Save(ctx, product, tx)
+	endSpan(span, err)
+	return err
+}
+
+func (r *tracedProductRepository) GetVariantBySKU(ctx context.Context, sku string, tx *gorm.DB) (*models.ProductVariant, error) {
+	ctx, span := startSpan(ctx, "ProductRepository.GetVariantBySKU")
+	variant, err := r.next.GetVariantBySKU(ctx, sku, tx)
+	endSpan(span, err)
+	return variant, err
+}
+
+func (r *tracedProductRepository) SaveVariant(ctx context.Context, variant *models.ProductVariant, tx *gorm.DB) error {
+	ctx, span := startSpan(ctx, "ProductRepository.SaveVariant")
+	err := r.next.SaveVariant(ctx, variant, tx)
+	endSpan(span, err)
+	return err
+}
+
+func (r *tracedProductRepository) FindInBatches(ctx context.Context, batchSize int, fn func(products []models.Product) error) error {
+	ctx, span := startSpan(ctx, "ProductRepository.FindInBatches", attribute.Int("batch.size", batchSize))
+	err := r.next.FindInBatches(ctx, batchSize, fn)
+	endSpan(span, err)
+	return err
+}
+
+// tracedOrderRepository registra un span por cada llamada a OrderRepository
+type tracedOrderRepository struct {
+	next ports.OrderRepository
+}
+
+// NewTracedOrderRepository envuelve repo para registrar cada llamada en la traza de la solicitud
+func NewTracedOrderRepository(repo ports.OrderRepository) ports.OrderRepository {
+	return &tracedOrderRepository{next: repo}
+}
+
+func (r *tracedOrderRepository) Create(ctx context.Context, order *models.Order, tx *gorm.DB) error {
+	ctx, span := startSpan(ctx, "OrderRepository.Create", attribute.Int("order.items", len(order.OrderItems)))
+	err := r.next.Create(ctx, order, tx)
+	span.SetAttributes(idAttr("order.id", order.ID))
+	endSpan(span, err)
+	return err
+}
+
+func (r *tracedOrderRepository) FindByID(ctx context.Context, id uint) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "OrderRepository.FindByID", idAttr("order.id", id))
+	order, err := r.next.FindByID(ctx, id)
+	endSpan(span, err)
+	return order, err
+}
+
+func (r *tracedOrderRepository) Search(ctx context.Context, filter ports.OrderFilter) ([]models.Order, int64, error) {
+	ctx, span := startSpan(ctx, "OrderRepository.Search")
+	orders, total, err := r.next.Search(ctx, filter)
+	endSpan(span, err)
+	return orders, total, err
+}
+
+func (r *tracedOrderRepository) UpdateStatus(ctx context.Context, id uint, from, to string) (bool, error) {
+	ctx, span := startSpan(ctx, "OrderRepository.UpdateStatus", idAttr("order.id", id), attribute.String("order.status", to))
+	updated, err := r.next.UpdateStatus(ctx, id, from, to)
+	endSpan(span, err)
+	return updated, err
+}
+
+// tracedCategoryRepository registra un span por cada llamada a CategoryRepository
+type tracedCategoryRepository struct {
+	next ports.CategoryRepository
+}
+
+// NewTracedCategoryRepository envuelve repo para registrar cada llamada en la traza de la solicitud
+func NewTracedCategoryRepository(repo ports.CategoryRepository) ports.CategoryRepository {
+	return &tracedCategoryRepository{next: repo}
+}
+
+func (r *tracedCategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
+	ctx, span := startSpan(ctx, "CategoryRepository.GetAll")
+	categories, err := r.next.GetAll(ctx)
+	endSpan(span, err)
+	return categories, err
+}
+
+func (r *tracedCategoryRepository) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	ctx, span := startSpan(ctx, "CategoryRepository.GetByID", idAttr("category.id", id))
+	category, err := r.next.GetByID(ctx, id)
+	endSpan(span, err)
+	return category, err
+}
+
+func (r *tracedCategoryRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Category, error) {
+	ctx, span := startSpan(ctx, "CategoryRepository.FindByIDs", attribute.Int("category.count", len(ids)))
+	categories, err := r.next.FindByIDs(ctx, ids)
+	endSpan(span, err)
+	return categories, err
+}
+
+func (r *tracedCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	ctx, span := startSpan(ctx, "CategoryRepository.Create")
+	err := r.next.Create(ctx, category)
+	endSpan(span, err)
+	return err
+}
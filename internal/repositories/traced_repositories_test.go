@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"order_management/internal/models"
+	"order_management/test/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+func TestTracedRepositories(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctrl := gomock.NewController(t)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	productRepo := NewTracedProductRepository(mockProductRepo)
+	orderRepo := NewTracedOrderRepository(mockOrderRepo)
+
+	// El repositorio recibe el contexto con su span, del que cuelgan las consultas SQL
+	var repoSpan trace.SpanContext
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), nil).DoAndReturn(func(ctx context.Context, _ uint, _ *gorm.DB) (*models.Product, error) {
+		repoSpan = trace.SpanContextFromContext(ctx)
+		return nil, gorm.ErrRecordNotFound
+	})
+	mockOrderRepo.EXPECT().Create(gomock.Any(), gomock.Any(), nil).DoAndReturn(func(_ context.Context, order *models.Order, _ *gorm.DB) error {
+		order.ID = 7
+		return errors.New("conexión perdida")
+	})
+
+	_, err := productRepo.GetByID(context.Background(), 1, nil)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Error(t, orderRepo.Create(context.Background(), &models.Order{}, nil))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "ProductRepository.GetByID", spans[0].Name())
+		assert.Equal(t, spans[0].SpanContext().SpanID(), repoSpan.SpanID())
+		assert.Contains(t, spans[0].Attributes(), attribute.Int64("product.id", 1))
+		// Un registro inexistente no es un error
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+		assert.Equal(t, "OrderRepository.Create", spans[1].Name())
+		assert.Contains(t, spans[1].Attributes(), attribute.Int64("order.id", 7))
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"order_management/internal/apperrors"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+
+	"gorm.io/gorm"
+)
+
+// CategoryServiceImpl implementa CategoryService.
+type CategoryServiceImpl struct {
+	repo ports.CategoryRepository
+}
+
+// NewCategoryService crea una nueva instancia de CategoryService.
+func NewCategoryService(repo ports.CategoryRepository) ports.CategoryService {
+	return &CategoryServiceImpl{repo: repo}
+}
+
+// GetCategoryTree devuelve las categorías raíz con sus subcategorías anidadas.
+func (s *CategoryServiceImpl) GetCategoryTree(ctx context.Context) ([]models.Category, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Agrupar las categorías por su padre
+	childrenByParent := make(map[uint][]models.Category)
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		childrenByParent[*category.ParentID] = append(childrenByParent[*category.ParentID], category)
+	}
+
+	return buildCategoryTree(roots, childrenByParent), nil
+}
+
+// buildCategoryTree asigna recursivamente las subcategorías a cada nodo
+func buildCategoryTree(nodes []models.Category, childrenByParent map[uint][]models.Category) []models.Category {
+	tree := make([]models.Category, len(nodes))
+	for i, node := range nodes {
+		node.Children = buildCategoryTree(childrenByParent[node.ID], childrenByParent)
+		tree[i] = node
+	}
+	return tree
+}
+
+// CreateCategory crea una categoría verificando que su categoría padre exista.
+func (s *CategoryServiceImpl) CreateCategory(ctx context.Context, category *models.Category) error {
+	if category.ParentID != nil {
+		if _, err := s.repo.GetByID(ctx, *category.ParentID); err != nil {
+			slog.InfoContext(ctx, "Categoría padre no encontrada", "parent_id", *category.ParentID, "error", err)
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.Internal(apperrors.CodeInternal, "error al buscar la categoría padre", err)
+			}
+			return apperrors.Unprocessable(apperrors.CodeCategoryNotFound, "categoría padre no encontrada").
+				WithDetail("parent_id", *category.ParentID)
+		}
+	}
+
+	if err := s.repo.Create(ctx, category); err != nil {
+		slog.ErrorContext(ctx, "Error al crear la categoría", "error", err)
+		return apperrors.Internal(apperrors.CodeCategoryCreateFailed, "error al crear la categoría", err)
+	}
+	return nil
+}
+
+// GetCategoriesByIDs obtiene en una sola consulta las categorías indicadas que existan.
+func (s *CategoryServiceImpl) GetCategoriesByIDs(ctx context.Context, ids []uint) ([]models.Category, error) {
+	if len(ids) == 0 {
+		return []models.Category{}, nil
+	}
+
+	categories, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error al buscar categorías", "error", err)
+		return nil, apperrors.Internal(apperrors.CodeInternal, "error al buscar categorías", err)
+	}
+	return categories, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"order_management/internal/apperrors"
+	"order_management/internal/models"
+	"order_management/test/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestGetCategoryTree_Success verifica que las categorías se aniden bajo su categoría padre.
+func TestGetCategoryTree_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCategoryRepo := mocks.NewMockCategoryRepository(ctrl)
+	categoryService := NewCategoryService(mockCategoryRepo)
+
+	ropa, camisetas, manga := uint(1), uint(2), uint(3)
+	mockCategoryRepo.
+		EXPECT().
+		GetAll(gomock.Any()).
+		Return([]models.Category{
+			{ID: ropa, Name: "Ropa"},
+			{ID: camisetas, Name: "Camisetas", ParentID: &ropa},
+			{ID: manga, Name: "Manga corta", ParentID: &camisetas},
+			{ID: 4, Name: "Hogar"},
+		}, nil)
+
+	// Ejecutar
+	tree, err := categoryService.GetCategoryTree(context.Background())
+
+	// Verificar
+	assert.NoError(t, err)
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "Ropa", tree[0].Name)
+	assert.Len(t, tree[0].Children, 1)
+	assert.Equal(t, "Camisetas", tree[0].Children[0].Name)
+	assert.Equal(t, "Manga corta", tree[0].Children[0].Children[0].Name)
+	assert.Empty(t, tree[1].Children)
+}
+
+// TestCreateCategory_ParentNotFound verifica que no se cree una categoría con un padre inexistente.
+func TestCreateCategory_ParentNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCategoryRepo := mocks.NewMockCategoryRepository(ctrl)
+	categoryService := NewCategoryService(mockCategoryRepo)
+
+	parentID := uint(99)
+	mockCategoryRepo.
+		EXPECT().
+		GetByID(gomock.Any(), parentID).
+		Return(nil, gorm.ErrRecordNotFound)
+
+	// Ejecutar
+	err := categoryService.CreateCategory(context.Background(), &models.Category{Name: "Zapatos", ParentID: &parentID})
+
+	// Verificar
+	assert.Error(t, err)
+	assert.Equal(t, "categoría padre no encontrada", err.Error())
+	assert.True(t, apperrors.IsCode(err, apperrors.CodeCategoryNotFound))
+}
+
+// TestGetCategoriesByIDs_Failure verifica que un error del repositorio se informe como error interno.
+func TestGetCategoriesByIDs_Failure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCategoryRepo := mocks.NewMockCategoryRepository(ctrl)
+	categoryService := NewCategoryService(mockCategoryRepo)
+
+	mockCategoryRepo.EXPECT().FindByIDs(gomock.Any(), []uint{1, 2}).Return(nil, gorm.ErrInvalidDB)
+
+	// Ejecutar
+	categories, err := categoryService.GetCategoriesByIDs(context.Background(), []uint{1, 2})
+
+	// Verificar
+	assert.Nil(t, categories)
+	assert.True(t, apperrors.IsCode(err, apperrors.CodeInternal))
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"order_management/internal/models"
+	"order_management/internal/ports"
+)
+
+// publishEvent publica el evento si el servicio tiene un publicador configurado. Se llama después
+// de confirmar la transacción, por lo que un error al publicar solo se registra y el evento se
+// publica aunque el cliente se haya desconectado o la solicitud haya vencido.
+func publishEvent(ctx context.Context, publisher ports.EventPublisher, event ports.Event) {
+	if publisher == nil {
+		return
+	}
+
+	event.OccurredAt = time.Now()
+	if err := publisher.Publish(context.WithoutCancel(ctx), event); err != nil {
+		slog.ErrorContext(ctx, "Error al publicar el evento", "type", event.Type, "error", err)
+	}
+}
+
+// publishOrderEvents publica la creación de cada orden y el stock resultante de los productos y
+// variantes que descontaron, una sola vez por registro
+func publishOrderEvents(ctx context.Context, publisher ports.EventPublisher, orders ...*models.Order) {
+	for _, order := range orders {
+		publishEvent(ctx, publisher, ports.Event{Type: ports.EventOrderCreated, Order: order})
+	}
+
+	type stockKey struct {
+		productID uint
+		variantID uint
+	}
+	published := make(map[stockKey]bool)
+
+	for _, order := range orders {
+		for _, item := range order.OrderItems {
+			key := stockKey{productID: item.ProductID}
+			change := ports.StockChange{ProductID: item.ProductID, Stock: item.Product.Stock}
+			if item.Variant != nil {
+				variantID := item.Variant.ID
+				key.variantID = variantID
+				change.VariantID = &variantID
+				change.Stock = item.Variant.Stock
+			}
+
+			if published[key] {
+				continue
+			}
+			published[key] = true
+			publishEvent(ctx, publisher, ports.Event{Type: ports.EventStockChanged, Stock: &change})
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"order_management/internal/apperrors"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+)
+
+// recordOrdersCreated registra las órdenes confirmadas si el servicio tiene métricas configuradas
+func recordOrdersCreated(metrics ports.OrderMetrics, orders ...*models.Order) {
+	if metrics == nil {
+		return
+	}
+	for _, order := range orders {
+		metrics.OrderCreated(order.TotalAmount)
+	}
+}
+
+// recordOrderFailures registra cada error de creación de una orden con su motivo
+func recordOrderFailures(metrics ports.OrderMetrics, errs ...error) {
+	if metrics == nil {
+		return
+	}
+	for _, err := range errs {
+		if err != nil {
+			metrics.OrderFailed(orderFailureReason(err))
+		}
+	}
+}
+
+// orderFailureReason clasifica el error de creación de una orden. Los errores que no son de dominio
+// ni del contexto de la solicitud vienen de la base de datos.
+func orderFailureReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return ports.OrderFailureCanceled
+	}
+
+	appErr, ok := apperrors.As(err)
+	if !ok {
+		return ports.OrderFailureDatabase
+	}
+
+	switch {
+	case appErr.Code == apperrors.CodeInsufficientStock:
+		return ports.OrderFailureStock
+	case appErr.Code == apperrors.CodeProductNotFound, appErr.Code == apperrors.CodeVariantNotFound:
+		return ports.OrderFailureProductNotFound
+	case appErr.Code == apperrors.CodeBatchAborted:
+		return ports.OrderFailureBatchAborted
+	case appErr.Kind == apperrors.KindInternal, appErr.Code == apperrors.CodeTransactionConflict:
+		return ports.OrderFailureDatabase
+	default:
+		return ports.OrderFailureOther
+	}
+}
@@ -1,91 +1,593 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"log"
+	"log/slog"
+	"order_management/internal/apperrors"
 	"order_management/internal/models"
 	"order_management/internal/ports"
+	"order_management/internal/tracing"
+	"order_management/pkg/database"
+	"slices"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
 // OrderServiceImpl implementa OrderService.
 type OrderServiceImpl struct {
-	repo        ports.OrderRepository
-	productRepo ports.ProductRepository
-	db          *gorm.DB
+	repo           ports.OrderRepository
+	productRepo    ports.ProductRepository
+	db             *gorm.DB
+	eventPublisher ports.EventPublisher
+	metrics        ports.OrderMetrics
 }
 
-// NewOrderService crea una nueva instancia de OrderService.
-func NewOrderService(repo ports.OrderRepository, productRepo ports.ProductRepository, db *gorm.DB) ports.OrderService {
-	return &OrderServiceImpl{repo: repo, productRepo: productRepo, db: db}
+// NewOrderService crea una nueva instancia de OrderService. eventPublisher puede ser nil si no se
+// necesitan eventos en tiempo real y metrics si no se publican métricas de negocio.
+func NewOrderService(repo ports.OrderRepository, productRepo ports.ProductRepository, db *gorm.DB, eventPublisher ports.EventPublisher, metrics ports.OrderMetrics) ports.OrderService {
+	return &OrderServiceImpl{repo: repo, productRepo: productRepo, db: db, eventPublisher: eventPublisher, metrics: metrics}
 }
 
-func (s *OrderServiceImpl) CreateOrder(order *models.Order) error {
-	var totalAmount float64
+// CreateOrder valida el stock, descuenta las cantidades y guarda la orden en una única transacción.
+// Las líneas repetidas se combinan y las filas se bloquean siempre en el mismo orden (productos y
+// luego variantes, por ID ascendente) para evitar deadlocks entre órdenes concurrentes. Si aun así
+// MySQL aborta la transacción por un deadlock o un timeout de bloqueo, se reintenta completa.
+func (s *OrderServiceImpl) CreateOrder(ctx context.Context, order *models.Order) error {
+	return s.CreateOrderIdempotent(ctx, order, nil)
+}
+
+// CreateOrderIdempotent crea la orden como CreateOrder y, si lock es una reserva transaccional,
+// asocia la clave de idempotencia con la orden en la misma transacción. Así la clave y la orden se
+// confirman juntas: un reintento que toma la clave después de una caída recibe la orden ya creada.
+func (s *OrderServiceImpl) CreateOrderIdempotent(ctx context.Context, order *models.Order, lock ports.IdempotencyLock) error {
+	orderID := order.ID
+	items, err := mergeOrderItems(order.OrderItems)
+	if err != nil {
+		recordOrderFailures(s.metrics, err)
+		return err
+	}
+	txLock := transactionalLock(lock)
+
+	ctx, span := tracing.Start(ctx, "OrderService.CreateOrder", trace.WithAttributes(attribute.Int("order.items", len(items))))
+	err = withTxRetry(ctx, func() error {
+		// Restaurar el estado original de la orden antes de cada intento
+		order.ID = orderID
+		order.OrderItems = append([]models.OrderItem(nil), items...)
+		return s.createOrderTx(ctx, order, txLock)
+	})
+	span.SetAttributes(attribute.Int64("order.id", int64(order.ID)))
+	tracing.End(span, err)
 
+	recordOrderFailures(s.metrics, err)
+	return err
+}
+
+// createOrderTx ejecuta un intento de creación de la orden dentro de una transacción
+func (s *OrderServiceImpl) createOrderTx(ctx context.Context, order *models.Order, txLock ports.TransactionalIdempotencyLock) error {
 	// Iniciar transacción
-	tx := s.db.Begin()
+	tx := s.db.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
 		}
 	}()
 
-	// Validar stock y calcular el total
-	for i, item := range order.OrderItems {
-		// Obtener el producto con la transacción activa
-		product, err := s.productRepo.GetByID(item.ProductID, tx)
+	if txLock != nil {
+		// Bloquear la clave antes que los productos: si ya tiene una orden, se devuelve esa orden
+		existingID, err := lockIdempotencyKey(txLock, tx)
 		if err != nil {
-			log.Printf("Error al buscar producto ID %d: %v", item.ProductID, err)
 			tx.Rollback()
-			return errors.New("producto no encontrado")
+			return err
+		}
+		if existingID != 0 {
+			tx.Rollback()
+			return s.loadExistingOrder(ctx, order, existingID)
 		}
+	}
+
+	products, variants, err := s.lockOrderRows(ctx, order.OrderItems, tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-		// Verificar stock disponible
-		if product.Stock < item.Quantity {
-			log.Printf("Stock insuficiente para el producto ID %d", product.ID)
+	if err := s.applyOrder(ctx, order, products, variants, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if txLock != nil {
+		if err := txLock.BindTx(tx, order.ID); err != nil {
 			tx.Rollback()
-			return errors.New("stock insuficiente para un producto")
+			return idempotencyLockError(err)
 		}
+	}
 
-		// Calcular subtotal
-		item.Subtotal = float64(item.Quantity) * product.Price
-		totalAmount += item.Subtotal
+	// Commit si todo fue exitoso
+	if err := tx.Commit().Error; err != nil {
+		slog.ErrorContext(ctx, "Error al confirmar la transacción", "error", err)
+		return apperrors.Internal(apperrors.CodeInternal, "error al confirmar la transacción", err)
+	}
 
-		// Reducir stock del producto y actualizar en la BD con la transacción activa
-		product.Stock -= item.Quantity
-		if err := s.productRepo.UpdateStock(product.ID, product.Stock, tx); err != nil {
-			log.Printf("Error al actualizar stock del producto ID %d: %v", product.ID, err)
+	fillOrderItems(order, products, variants)
+	publishOrderEvents(ctx, s.eventPublisher, order)
+	recordOrdersCreated(s.metrics, order)
+
+	slog.InfoContext(ctx, "Orden creada", "order_id", order.ID, "total_amount", order.TotalAmount)
+	return nil
+}
+
+// lockIdempotencyKey bloquea la clave de idempotencia dentro de la transacción y devuelve la orden
+// que ya tenga asociada
+func lockIdempotencyKey(txLock ports.TransactionalIdempotencyLock, tx *gorm.DB) (uint, error) {
+	existingID, err := txLock.LockTx(tx)
+	if err != nil {
+		return 0, idempotencyLockError(err)
+	}
+	return existingID, nil
+}
+
+// idempotencyLockError traduce los errores de la reserva: si otra solicitud tomó la clave, la
+// orden no se crea y el cliente debe esperar su resultado
+func idempotencyLockError(err error) error {
+	if errors.Is(err, ports.ErrIdempotencyLockLost) {
+		return apperrors.Conflict(apperrors.CodeIdempotencyInProgress, "la petición está siendo procesada")
+	}
+	if database.IsRetryableTxError(err) {
+		return err
+	}
+	return apperrors.Internal(apperrors.CodeInternal, "error al verificar la clave de idempotencia", err)
+}
+
+// loadExistingOrder reemplaza la orden recibida por la orden ya creada con la misma clave
+func (s *OrderServiceImpl) loadExistingOrder(ctx context.Context, order *models.Order, orderID uint) error {
+	existing, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error al buscar la orden asociada a la clave de idempotencia", "order_id", orderID, "error", err)
+		return apperrors.Internal(apperrors.CodeInternal, "error al buscar la orden", err)
+	}
+
+	slog.InfoContext(ctx, "La clave de idempotencia ya tenía una orden, no se crea otra", "order_id", orderID)
+	*order = *existing
+	return nil
+}
+
+// CreateOrders crea un lote de órdenes y devuelve el resultado de cada una en el mismo orden.
+// En modo independiente cada orden se crea con CreateOrderIdempotent en su propia transacción. En
+// modo todo o nada se bloquean de una vez, en el mismo orden que CreateOrder, los productos y las
+// variantes de todo el lote y se crean todas las órdenes en una única transacción: si alguna
+// falla no se aplica ninguna y las demás reciben el error BATCH_ABORTED. En ambos modos las claves
+// con reserva transaccional se asocian con su orden en la transacción que la crea.
+func (s *OrderServiceImpl) CreateOrders(ctx context.Context, orders []*models.Order, locks []ports.IdempotencyLock, allOrNothing bool) []error {
+	ctx, span := tracing.Start(ctx, "OrderService.CreateOrders", trace.WithAttributes(
+		attribute.Int("batch.orders", len(orders)),
+		attribute.Bool("batch.all_or_nothing", allOrNothing),
+	))
+
+	errs := make([]error, len(orders))
+	// El span queda con error si falló alguna orden del lote
+	defer func() { tracing.End(span, errors.Join(errs...)) }()
+
+	if !allOrNothing {
+		for i, order := range orders {
+			errs[i] = s.CreateOrderIdempotent(ctx, order, lockAt(locks, i))
+		}
+		return errs
+	}
+
+	orderIDs := make([]uint, len(orders))
+	items := make([][]models.OrderItem, len(orders))
+	failed := false
+	for i, order := range orders {
+		orderIDs[i] = order.ID
+		items[i], errs[i] = mergeOrderItems(order.OrderItems)
+		failed = failed || errs[i] != nil
+	}
+	if failed {
+		// Una orden inválida aborta el lote antes de abrir la transacción
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = apperrors.Conflict(apperrors.CodeBatchAborted, "la orden no se creó porque otra orden del lote falló")
+			}
+		}
+		recordOrderFailures(s.metrics, errs...)
+		return errs
+	}
+
+	err := withTxRetry(ctx, func() error {
+		// Restaurar el estado original de las órdenes antes de cada intento
+		for i, order := range orders {
+			order.ID = orderIDs[i]
+			order.OrderItems = append([]models.OrderItem(nil), items[i]...)
+		}
+
+		var err error
+		errs, err = s.createOrdersTx(ctx, orders, locks)
+		return err
+	})
+	if err != nil {
+		// Un error inesperado aborta el lote completo
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+	recordOrderFailures(s.metrics, errs...)
+	return errs
+}
+
+// createOrdersTx ejecuta un intento de creación de todo el lote dentro de una única transacción.
+// Los errores de dominio se informan por orden; cualquier otro error aborta el lote.
+func (s *OrderServiceImpl) createOrdersTx(ctx context.Context, orders []*models.Order, locks []ports.IdempotencyLock) ([]error, error) {
+	errs := make([]error, len(orders))
+
+	// Iniciar transacción
+	tx := s.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
 			tx.Rollback()
-			return errors.New("error al actualizar stock")
 		}
+	}()
 
-		// Actualizar el pedido con el subtotal corregido
-		order.OrderItems[i] = item
+	// Bloquear las claves antes que los productos: las que ya tienen una orden devuelven esa orden
+	// y no se crean de nuevo
+	existing := make([]bool, len(orders))
+	for i, order := range orders {
+		txLock := transactionalLock(lockAt(locks, i))
+		if txLock == nil {
+			continue
+		}
+		existingID, err := lockIdempotencyKey(txLock, tx)
+		if err != nil {
+			tx.Rollback()
+			return errs, err
+		}
+		if existingID != 0 {
+			if err := s.loadExistingOrder(ctx, order, existingID); err != nil {
+				tx.Rollback()
+				return errs, err
+			}
+			existing[i] = true
+		}
 	}
 
-	// Asignar total a la orden
-	order.TotalAmount = totalAmount
+	var allItems []models.OrderItem
+	var created []*models.Order
+	for i, order := range orders {
+		if !existing[i] {
+			allItems = append(allItems, order.OrderItems...)
+			created = append(created, order)
+		}
+	}
 
-	// Guardar la orden dentro de la transacción
-	if err := s.repo.Create(order, tx); err != nil {
-		log.Printf("Error al guardar la orden: %v", err)
+	products, variants, err := s.lockOrderRows(ctx, allItems, tx)
+	if err != nil {
+		tx.Rollback()
+		return errs, err
+	}
+
+	failed := false
+	for i, order := range orders {
+		if existing[i] {
+			continue
+		}
+		if err := s.applyOrder(ctx, order, products, variants, tx); err != nil {
+			if appErr, ok := apperrors.As(err); !ok || appErr.Kind == apperrors.KindInternal {
+				tx.Rollback()
+				return errs, err
+			}
+			errs[i] = err
+			failed = true
+		}
+	}
+
+	if failed {
 		tx.Rollback()
-		return errors.New("error al crear la orden")
+		for i, err := range errs {
+			// Las órdenes que ya existían no dependen de este lote
+			if err == nil && !existing[i] {
+				errs[i] = apperrors.Conflict(apperrors.CodeBatchAborted, "la orden no se creó porque otra orden del lote falló")
+			}
+		}
+		return errs, nil
+	}
+
+	// Asociar cada clave con su orden para que se confirmen juntas
+	for i, order := range orders {
+		if txLock := transactionalLock(lockAt(locks, i)); txLock != nil && !existing[i] {
+			if err := txLock.BindTx(tx, order.ID); err != nil {
+				tx.Rollback()
+				return errs, idempotencyLockError(err)
+			}
+		}
 	}
 
 	// Commit si todo fue exitoso
 	if err := tx.Commit().Error; err != nil {
-		log.Printf("Error al confirmar la transacción: %v", err)
-		return errors.New("error al confirmar la transacción")
+		slog.ErrorContext(ctx, "Error al confirmar la transacción", "error", err)
+		return errs, apperrors.Internal(apperrors.CodeInternal, "error al confirmar la transacción", err)
+	}
+
+	for _, order := range created {
+		fillOrderItems(order, products, variants)
+	}
+	publishOrderEvents(ctx, s.eventPublisher, created...)
+	recordOrdersCreated(s.metrics, created...)
+
+	slog.InfoContext(ctx, "Lote de órdenes creado", "orders", len(created))
+	return errs, nil
+}
+
+// lockAt devuelve la reserva de la orden i del lote, o nil si no tiene
+func lockAt(locks []ports.IdempotencyLock, i int) ports.IdempotencyLock {
+	if i < len(locks) {
+		return locks[i]
+	}
+	return nil
+}
+
+// transactionalLock devuelve la reserva si su store permite asociarla con la orden en la transacción
+func transactionalLock(lock ports.IdempotencyLock) ports.TransactionalIdempotencyLock {
+	txLock, _ := lock.(ports.TransactionalIdempotencyLock)
+	return txLock
+}
+
+// lockOrderRows bloquea los productos y luego las variantes de los items, en orden ascendente de ID.
+// Los registros inexistentes no se incluyen en los mapas; applyOrder informa el error correspondiente.
+func (s *OrderServiceImpl) lockOrderRows(ctx context.Context, items []models.OrderItem, tx *gorm.DB) (map[uint]*models.Product, map[uint]*models.ProductVariant, error) {
+	// Bloquear los productos en orden ascendente de ID
+	products := make(map[uint]*models.Product)
+	for _, productID := range sortedProductIDs(items) {
+		// Obtener el producto con la transacción activa
+		product, err := s.productRepo.GetByID(ctx, productID, tx)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.InfoContext(ctx, "Producto no encontrado", "product_id", productID)
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Error al buscar el producto", "product_id", productID, "error", err)
+			return nil, nil, apperrors.Internal(apperrors.CodeInternal, "error al buscar producto", err)
+		}
+		products[productID] = product
+	}
+
+	// Bloquear las variantes en orden ascendente de ID
+	variants := make(map[uint]*models.ProductVariant)
+	for _, variantID := range sortedVariantIDs(items) {
+		variant, err := s.productRepo.GetVariantByID(ctx, variantID, tx)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.InfoContext(ctx, "Variante no encontrada", "variant_id", variantID)
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Error al buscar la variante", "variant_id", variantID, "error", err)
+			return nil, nil, apperrors.Internal(apperrors.CodeInternal, "error al buscar variante", err)
+		}
+		variants[variantID] = variant
+	}
+
+	return products, variants, nil
+}
+
+// applyOrder valida el stock de todas las líneas, lo descuenta y guarda la orden. Las validaciones
+// se hacen antes de cualquier escritura, por lo que un error de dominio no deja cambios a medias y
+// los productos bloqueados reflejan siempre el stock restante para las siguientes órdenes del lote.
+func (s *OrderServiceImpl) applyOrder(ctx context.Context, order *models.Order, products map[uint]*models.Product, variants map[uint]*models.ProductVariant, tx *gorm.DB) error {
+	var totalAmount float64
+
+	// Validar stock y calcular el total
+	for i, item := range order.OrderItems {
+		product, ok := products[item.ProductID]
+		if !ok {
+			return apperrors.NotFound(apperrors.CodeProductNotFound, "producto no encontrado").WithDetail("product_id", item.ProductID)
+		}
+
+		if item.VariantID != nil {
+			// La línea referencia una variante: el stock y el precio salen de la variante
+			variant, ok := variants[*item.VariantID]
+			if !ok {
+				return apperrors.NotFound(apperrors.CodeVariantNotFound, "variante no encontrada").WithDetail("variant_id", *item.VariantID)
+			}
+			if variant.ProductID != product.ID {
+				slog.InfoContext(ctx, "La variante no pertenece al producto", "product_id", product.ID, "variant_id", variant.ID)
+				return apperrors.Unprocessable(apperrors.CodeVariantMismatch, "la variante no pertenece al producto").
+					WithDetail("product_id", product.ID).
+					WithDetail("variant_id", variant.ID)
+			}
+
+			// Verificar stock disponible de la variante
+			if variant.Stock < item.Quantity {
+				slog.InfoContext(ctx, "Stock insuficiente para la variante", "variant_id", variant.ID, "requested", item.Quantity, "available", variant.Stock)
+				return apperrors.InsufficientStock(product.ID, item.VariantID, item.Quantity, variant.Stock)
+			}
+
+			// Calcular subtotal con el precio de la variante
+			item.Subtotal = float64(item.Quantity) * variant.EffectivePrice(product.Price)
+		} else {
+			// Verificar stock disponible
+			if product.Stock < item.Quantity {
+				slog.InfoContext(ctx, "Stock insuficiente para el producto", "product_id", product.ID, "requested", item.Quantity, "available", product.Stock)
+				return apperrors.InsufficientStock(product.ID, nil, item.Quantity, product.Stock)
+			}
+
+			// Calcular subtotal
+			item.Subtotal = float64(item.Quantity) * product.Price
+		}
+
+		totalAmount += item.Subtotal
+
+		// Actualizar el pedido con el subtotal corregido
+		order.OrderItems[i] = item
+	}
+
+	// Reducir el stock y actualizar en la BD con la transacción activa
+	for _, item := range order.OrderItems {
+		if item.VariantID != nil {
+			variant := variants[*item.VariantID]
+			variant.Stock -= item.Quantity
+			if err := s.productRepo.UpdateVariantStock(ctx, variant.ID, variant.Stock, tx); err != nil {
+				slog.ErrorContext(ctx, "Error al actualizar el stock de la variante", "variant_id", variant.ID, "error", err)
+				return apperrors.Internal(apperrors.CodeStockUpdateFailed, "error al actualizar stock", err)
+			}
+			continue
+		}
+
+		product := products[item.ProductID]
+		product.Stock -= item.Quantity
+		if err := s.productRepo.UpdateStock(ctx, product.ID, product.Stock, tx); err != nil {
+			slog.ErrorContext(ctx, "Error al actualizar el stock del producto", "product_id", product.ID, "error", err)
+			return apperrors.Internal(apperrors.CodeStockUpdateFailed, "error al actualizar stock", err)
+		}
+	}
+
+	// Asignar total a la orden, que se crea pendiente
+	order.TotalAmount = totalAmount
+	order.Status = models.OrderStatusPending
+
+	// Guardar la orden dentro de la transacción
+	if err := s.repo.Create(ctx, order, tx); err != nil {
+		slog.ErrorContext(ctx, "Error al guardar la orden", "error", err)
+		return apperrors.Internal(apperrors.CodeOrderCreationFailed, "error al crear la orden", err)
 	}
 
-	log.Println("Orden creada con éxito")
 	return nil
 }
 
+// fillOrderItems completa los items con el producto y la variante para poder devolver la orden creada
+func fillOrderItems(order *models.Order, products map[uint]*models.Product, variants map[uint]*models.ProductVariant) {
+	for i, item := range order.OrderItems {
+		order.OrderItems[i].Product = *products[item.ProductID]
+		if item.VariantID != nil {
+			order.OrderItems[i].Variant = variants[*item.VariantID]
+		}
+	}
+}
+
+// mergeOrderItems combina las líneas que referencian el mismo producto y variante,
+// conservando el orden de la primera aparición. Devuelve un error de validación si la cantidad de
+// una línea, o la suma de las líneas combinadas, queda fuera de 1..models.MaxOrderItemQuantity,
+// antes de que la suma pueda desbordarse.
+func mergeOrderItems(items []models.OrderItem) ([]models.OrderItem, error) {
+	type itemKey struct {
+		productID uint
+		variantID uint
+	}
+
+	merged := make([]models.OrderItem, 0, len(items))
+	positions := make(map[itemKey]int, len(items))
+	for _, item := range items {
+		key := itemKey{productID: item.ProductID}
+		if item.VariantID != nil {
+			key.variantID = *item.VariantID
+		}
+
+		if item.Quantity <= 0 || item.Quantity > models.MaxOrderItemQuantity {
+			return nil, orderItemQuantityError(item)
+		}
+
+		if pos, ok := positions[key]; ok {
+			if merged[pos].Quantity > models.MaxOrderItemQuantity-item.Quantity {
+				return nil, orderItemQuantityError(item)
+			}
+			merged[pos].Quantity += item.Quantity
+			continue
+		}
+		positions[key] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged, nil
+}
+
+// orderItemQuantityError informa una cantidad fuera del rango admitido para el producto del item
+func orderItemQuantityError(item models.OrderItem) error {
+	err := apperrors.Invalidf(apperrors.CodeValidationFailed, "la cantidad del producto %d debe estar entre 1 y %d",
+		item.ProductID, models.MaxOrderItemQuantity).
+		WithDetail("product_id", item.ProductID).
+		WithDetail("max_quantity", models.MaxOrderItemQuantity)
+	if item.VariantID != nil {
+		err.WithDetail("variant_id", *item.VariantID)
+	}
+	return err
+}
+
+// sortedProductIDs devuelve los IDs de producto distintos en orden ascendente
+func sortedProductIDs(items []models.OrderItem) []uint {
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	return uniqueSorted(ids)
+}
+
+// sortedVariantIDs devuelve los IDs de variante distintos en orden ascendente
+func sortedVariantIDs(items []models.OrderItem) []uint {
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		if item.VariantID != nil {
+			ids = append(ids, *item.VariantID)
+		}
+	}
+	return uniqueSorted(ids)
+}
+
+func uniqueSorted(ids []uint) []uint {
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// SearchOrders obtiene una página de órdenes que cumplen el filtro.
+func (s *OrderServiceImpl) SearchOrders(ctx context.Context, filter ports.OrderFilter) ([]models.Order, int64, error) {
+	orders, total, err := s.repo.Search(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error al buscar órdenes", "error", err)
+		return nil, 0, apperrors.Internal(apperrors.CodeInternal, "error al buscar órdenes", err)
+	}
+	return orders, total, nil
+}
+
 // GetOrderById busca una orden por su ID.
-func (s *OrderServiceImpl) GetOrderById(id uint) (*models.Order, error) {
-	return s.repo.FindByID(id)
+func (s *OrderServiceImpl) GetOrderById(ctx context.Context, id uint) (*models.Order, error) {
+	order, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound(apperrors.CodeOrderNotFound, "orden no encontrada").WithDetail("order_id", id)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error al buscar la orden", "order_id", id, "error", err)
+		return nil, apperrors.Internal(apperrors.CodeInternal, "error al buscar la orden", err)
+	}
+	return order, nil
+}
+
+// UpdateOrderStatus avanza la orden al estado indicado. El cambio se aplica solo si la orden sigue en
+// el estado leído, por lo que si otra solicitud la cambió antes se responde con un conflicto. Tras
+// guardarlo se publica el evento con la orden actualizada.
+func (s *OrderServiceImpl) UpdateOrderStatus(ctx context.Context, id uint, status string) (*models.Order, error) {
+	order, err := s.GetOrderById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.CanTransitionTo(status) {
+		slog.InfoContext(ctx, "Cambio de estado no permitido", "order_id", id, "from", order.Status, "to", status)
+		return nil, apperrors.Conflict(apperrors.CodeInvalidStatusChange, "la orden no puede pasar a ese estado").
+			WithDetail("status", order.Status)
+	}
+
+	updated, err := s.repo.UpdateStatus(ctx, id, order.Status, status)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error al actualizar el estado de la orden", "order_id", id, "error", err)
+		return nil, apperrors.Internal(apperrors.CodeInternal, "error al actualizar el estado de la orden", err)
+	}
+	if !updated {
+		slog.InfoContext(ctx, "La orden cambió de estado durante la actualización", "order_id", id)
+		return nil, apperrors.Conflict(apperrors.CodeInvalidStatusChange, "la orden cambió de estado, intente nuevamente")
+	}
+
+	order.Status = status
+	publishEvent(ctx, s.eventPublisher, ports.Event{Type: ports.EventOrderStatusChanged, Order: order})
+
+	slog.InfoContext(ctx, "Estado de la orden actualizado", "order_id", id, "status", status)
+	return order, nil
 }
@@ -1,15 +1,30 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
+	"order_management/internal/apperrors"
+	"order_management/internal/idempotency"
+	"order_management/internal/logging"
 	"order_management/internal/models"
+	"order_management/internal/ports"
 	"order_management/test/mocks"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -25,13 +40,13 @@ func TestCreateOrder_Success(t *testing.T) {
 	if errDB != nil {
 		log.Fatalf("Error al abrir la base de datos en memoria: %v", errDB)
 	}
-	db.AutoMigrate(&models.Product{}, &models.Order{}, &models.OrderItem{})
+	db.AutoMigrate(&models.Category{}, &models.Product{}, &models.ProductVariant{}, &models.Order{}, &models.OrderItem{})
 
 	// Insertar producto de prueba en la base de datos
 	product := &models.Product{ID: 1, Name: "Laptop", Price: 500, Stock: 10}
 	db.Create(product)
 
-	service := NewOrderService(mockOrderRepo, mockProductRepo, db)
+	service := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
 
 	order := &models.Order{
 		ID:          1,
@@ -42,15 +57,16 @@ func TestCreateOrder_Success(t *testing.T) {
 	}
 
 	// Mocks esperados (se usan los métodos del ORM, no los mocks)
-	mockProductRepo.EXPECT().GetByID(uint(1), gomock.Any()).Return(product, nil).Times(1)
-	mockProductRepo.EXPECT().UpdateStock(uint(1), int(8), gomock.Any()).Return(nil).Times(1)
-	mockOrderRepo.EXPECT().Create(order, gomock.Any()).Return(nil).Times(1)
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(product, nil).Times(1)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), int(8), gomock.Any()).Return(nil).Times(1)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), order, gomock.Any()).Return(nil).Times(1)
 
-	err := service.CreateOrder(order)
+	err := service.CreateOrder(context.Background(), order)
 
 	// **Validaciones**
 	assert.NoError(t, err)
 	assert.Equal(t, float64(1000), order.TotalAmount) // 2 * 500
+	assert.Equal(t, "Laptop", order.OrderItems[0].Product.Name)
 }
 
 // Test para CreateOrder con producto no encontrado
@@ -64,19 +80,20 @@ func TestCreateOrder_ProductNotFound(t *testing.T) {
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 	mockProductRepo := mocks.NewMockProductRepository(ctrl)
 
-	service := NewOrderService(mockOrderRepo, mockProductRepo, db)
+	service := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
 
 	order := &models.Order{
 		ID:         1,
 		OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}},
 	}
 
-	mockProductRepo.EXPECT().GetByID(uint(1), gomock.Any()).Return(nil, errors.New("not found")).Times(1)
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(nil, gorm.ErrRecordNotFound).Times(1)
 
-	err := service.CreateOrder(order)
+	err := service.CreateOrder(context.Background(), order)
 
 	assert.Error(t, err)
 	assert.Equal(t, "producto no encontrado", err.Error())
+	assert.True(t, apperrors.IsCode(err, apperrors.CodeProductNotFound))
 }
 
 // Test para CreateOrder con stock insuficiente
@@ -90,7 +107,7 @@ func TestCreateOrder_InsufficientStock(t *testing.T) {
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 	mockProductRepo := mocks.NewMockProductRepository(ctrl)
 
-	service := NewOrderService(mockOrderRepo, mockProductRepo, db)
+	service := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
 
 	order := &models.Order{
 		ID:         1,
@@ -99,12 +116,18 @@ func TestCreateOrder_InsufficientStock(t *testing.T) {
 
 	product := &models.Product{ID: 1, Name: "Laptop", Price: 500, Stock: 2}
 
-	mockProductRepo.EXPECT().GetByID(uint(1), gomock.Any()).Return(product, nil).Times(1)
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(product, nil).Times(1)
 
-	err := service.CreateOrder(order)
+	err := service.CreateOrder(context.Background(), order)
 
+	// El error indica el producto y la cantidad disponible
 	assert.Error(t, err)
-	assert.Equal(t, "stock insuficiente para un producto", err.Error())
+	appErr, ok := apperrors.As(err)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.CodeInsufficientStock, appErr.Code)
+	assert.Equal(t, uint(1), appErr.Details["product_id"])
+	assert.Equal(t, 2, appErr.Details["available"])
+	assert.Equal(t, 5, appErr.Details["requested"])
 }
 
 // Test para CreateOrder con error al actualizar stock
@@ -118,7 +141,7 @@ func TestCreateOrder_UpdateStockFailure(t *testing.T) {
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 	mockProductRepo := mocks.NewMockProductRepository(ctrl)
 
-	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db)
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
 
 	// Simulación de datos
 	product := &models.Product{
@@ -135,17 +158,17 @@ func TestCreateOrder_UpdateStockFailure(t *testing.T) {
 	// Mock de la obtención del producto
 	mockProductRepo.
 		EXPECT().
-		GetByID(product.ID, gomock.Any()).
+		GetByID(gomock.Any(), product.ID, gomock.Any()).
 		Return(product, nil)
 
 	// Mock de UpdateStock que falla
 	mockProductRepo.
 		EXPECT().
-		UpdateStock(product.ID, gomock.Any(), gomock.Any()).
+		UpdateStock(gomock.Any(), product.ID, gomock.Any(), gomock.Any()).
 		Return(errors.New("error en la base de datos"))
 
 	// Ejecutar la prueba
-	err := orderService.CreateOrder(order)
+	err := orderService.CreateOrder(context.Background(), order)
 
 	// Verificar resultado esperado
 	assert.Error(t, err)
@@ -163,7 +186,7 @@ func TestCreateOrder_SaveOrderFailure(t *testing.T) {
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 	mockProductRepo := mocks.NewMockProductRepository(ctrl)
 
-	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db)
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
 
 	// Simulación de datos
 	product := &models.Product{
@@ -180,23 +203,23 @@ func TestCreateOrder_SaveOrderFailure(t *testing.T) {
 	// Mock de la obtención del producto
 	mockProductRepo.
 		EXPECT().
-		GetByID(product.ID, gomock.Any()).
+		GetByID(gomock.Any(), product.ID, gomock.Any()).
 		Return(product, nil)
 
 	// Mock de actualización de stock con éxito
 	mockProductRepo.
 		EXPECT().
-		UpdateStock(product.ID, gomock.Any(), gomock.Any()).
+		UpdateStock(gomock.Any(), product.ID, gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	// Mock de error en la creación de la orden
 	mockOrderRepo.
 		EXPECT().
-		Create(order, gomock.Any()).
+		Create(gomock.Any(), order, gomock.Any()).
 		Return(errors.New("error en la base de datos"))
 
 	// Ejecutar la prueba
-	err := orderService.CreateOrder(order)
+	err := orderService.CreateOrder(context.Background(), order)
 
 	// Verificar resultado esperado
 	assert.Error(t, err)
@@ -213,18 +236,39 @@ func TestGetOrderById_Success(t *testing.T) {
 
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 
-	service := NewOrderService(mockOrderRepo, nil, db)
+	service := NewOrderService(mockOrderRepo, nil, db, nil, nil)
 
 	expectedOrder := &models.Order{ID: 1, TotalAmount: 100}
 
-	mockOrderRepo.EXPECT().FindByID(uint(1)).Return(expectedOrder, nil).Times(1)
+	mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(expectedOrder, nil).Times(1)
 
-	order, err := service.GetOrderById(1)
+	order, err := service.GetOrderById(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOrder, order)
 }
 
+// Test para verificar que el repositorio recibe el contexto de la solicitud con su ID
+func TestGetOrderById_PropagatesRequestID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+
+	service := NewOrderService(mockOrderRepo, nil, nil, nil, nil)
+
+	var requestID string
+	mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(1)).DoAndReturn(func(ctx context.Context, id uint) (*models.Order, error) {
+		requestID = logging.RequestIDFromContext(ctx)
+		return &models.Order{ID: id}, nil
+	}).Times(1)
+
+	_, err := service.GetOrderById(logging.WithRequestID(context.Background(), "req-1"), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", requestID)
+}
+
 // Test para GetOrderById cuando la orden no existe
 func TestGetOrderById_NotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -235,12 +279,808 @@ func TestGetOrderById_NotFound(t *testing.T) {
 
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 
-	service := NewOrderService(mockOrderRepo, nil, db)
+	service := NewOrderService(mockOrderRepo, nil, db, nil, nil)
+
+	mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(nil, gorm.ErrRecordNotFound).Times(1)
+
+	order, err := service.GetOrderById(context.Background(), 1)
+
+	assert.Error(t, err)
+	assert.Nil(t, order)
+	assert.True(t, apperrors.IsCode(err, apperrors.CodeOrderNotFound))
+}
+
+// Test para GetOrderById cuando falla la base de datos
+func TestGetOrderById_DatabaseError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+
+	service := NewOrderService(mockOrderRepo, nil, db, nil, nil)
+
+	mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(nil, errors.New("connection refused")).Times(1)
+
+	order, err := service.GetOrderById(context.Background(), 1)
+
+	assert.Error(t, err)
+	assert.Nil(t, order)
+	assert.True(t, apperrors.IsCode(err, apperrors.CodeInternal))
+}
+
+// Test para CreateOrder con una línea que referencia una variante con precio propio
+func TestCreateOrder_WithVariant_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	// Simulación de datos
+	variantPrice := 120.0
+	variantID := uint(7)
+	product := &models.Product{ID: 1, Name: "Camiseta", Price: 100.0, Stock: 0}
+	variant := &models.ProductVariant{ID: variantID, ProductID: 1, SKU: "CAM-M-AZUL", Price: &variantPrice, Stock: 4}
+	order := &models.Order{
+		OrderItems: []models.OrderItem{
+			{ProductID: 1, VariantID: &variantID, Quantity: 3},
+		},
+	}
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(product, nil).Times(1)
+	mockProductRepo.EXPECT().GetVariantByID(gomock.Any(), variantID, gomock.Any()).Return(variant, nil).Times(1)
+	mockProductRepo.EXPECT().UpdateVariantStock(gomock.Any(), variantID, 1, gomock.Any()).Return(nil).Times(1)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), order, gomock.Any()).Return(nil).Times(1)
+
+	// Ejecutar la prueba
+	err := orderService.CreateOrder(context.Background(), order)
+
+	// Verificar resultado esperado: se usa el precio y el stock de la variante
+	assert.NoError(t, err)
+	assert.Equal(t, 360.0, order.TotalAmount)
+	assert.Equal(t, 360.0, order.OrderItems[0].Subtotal)
+}
+
+// Test para CreateOrder con una variante que pertenece a otro producto
+func TestCreateOrder_VariantFromOtherProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	variantID := uint(7)
+	product := &models.Product{ID: 1, Name: "Camiseta", Price: 100.0}
+	variant := &models.ProductVariant{ID: variantID, ProductID: 2, SKU: "PANT-32", Stock: 10}
+	order := &models.Order{
+		OrderItems: []models.OrderItem{
+			{ProductID: 1, VariantID: &variantID, Quantity: 1},
+		},
+	}
 
-	mockOrderRepo.EXPECT().FindByID(uint(1)).Return(nil, errors.New("not found")).Times(1)
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(product, nil).Times(1)
+	mockProductRepo.EXPECT().GetVariantByID(gomock.Any(), variantID, gomock.Any()).Return(variant, nil).Times(1)
 
-	order, err := service.GetOrderById(1)
+	err := orderService.CreateOrder(context.Background(), order)
 
 	assert.Error(t, err)
+	assert.True(t, apperrors.IsCode(err, apperrors.CodeVariantMismatch))
+}
+
+// Test para CreateOrder con líneas duplicadas: se combinan y el producto se bloquea una sola vez
+func TestCreateOrder_MergesDuplicateItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	product := &models.Product{ID: 1, Name: "Laptop", Price: 100.0, Stock: 10}
+	order := &models.Order{
+		OrderItems: []models.OrderItem{
+			{ProductID: 1, Quantity: 2},
+			{ProductID: 1, Quantity: 3},
+		},
+	}
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(product, nil).Times(1)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 5, gomock.Any()).Return(nil).Times(1)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), order, gomock.Any()).Return(nil).Times(1)
+
+	err := orderService.CreateOrder(context.Background(), order)
+
+	assert.NoError(t, err)
+	assert.Len(t, order.OrderItems, 1)
+	assert.Equal(t, 5, order.OrderItems[0].Quantity)
+	assert.Equal(t, 500.0, order.TotalAmount)
+}
+
+// Test para mergeOrderItems: una suma que desborda int se rechaza en lugar de dar una cantidad negativa
+func TestMergeOrderItems_RejectsOverflowingQuantity(t *testing.T) {
+	items := []models.OrderItem{
+		{ProductID: 1, Quantity: 4611686018427387904},
+		{ProductID: 1, Quantity: 4611686018427387904},
+		{ProductID: 1, Quantity: 9223372036854775803},
+	}
+
+	merged, err := mergeOrderItems(items)
+
+	assert.Nil(t, merged)
+	appErr, ok := apperrors.As(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.KindInvalid, appErr.Kind)
+		assert.Equal(t, apperrors.CodeValidationFailed, appErr.Code)
+		assert.Equal(t, uint(1), appErr.Details["product_id"])
+	}
+}
+
+// Test para mergeOrderItems: las líneas válidas cuya suma supera el máximo se rechazan
+func TestMergeOrderItems_RejectsSumAboveMaximum(t *testing.T) {
+	variantID := uint(3)
+	items := []models.OrderItem{
+		{ProductID: 1, VariantID: &variantID, Quantity: models.MaxOrderItemQuantity},
+		{ProductID: 1, Quantity: models.MaxOrderItemQuantity},
+		{ProductID: 1, VariantID: &variantID, Quantity: 1},
+	}
+
+	_, err := mergeOrderItems(items)
+
+	appErr, ok := apperrors.As(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.CodeValidationFailed, appErr.Code)
+		assert.Equal(t, variantID, appErr.Details["variant_id"])
+	}
+}
+
+// Test para CreateOrders: una orden con una cantidad inválida aborta el lote sin abrir la transacción
+func TestCreateOrders_AllOrNothing_RejectsInvalidQuantity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	// Los repositorios no se llaman
+	orderService := NewOrderService(mocks.NewMockOrderRepository(ctrl), mocks.NewMockProductRepository(ctrl), db, nil, nil)
+
+	first := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 6}}}
+	second := &models.Order{OrderItems: []models.OrderItem{
+		{ProductID: 2, Quantity: models.MaxOrderItemQuantity},
+		{ProductID: 2, Quantity: 1},
+	}}
+
+	errs := orderService.CreateOrders(context.Background(), []*models.Order{first, second}, nil, true)
+
+	assert.Len(t, errs, 2)
+	assert.True(t, apperrors.IsCode(errs[0], apperrors.CodeBatchAborted))
+	assert.True(t, apperrors.IsCode(errs[1], apperrors.CodeValidationFailed))
+}
+
+// Test para CreateOrder: los productos se bloquean por ID ascendente sin importar el orden de la solicitud
+func TestCreateOrder_LocksProductsInDeterministicOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	order := &models.Order{
+		OrderItems: []models.OrderItem{
+			{ProductID: 9, Quantity: 1},
+			{ProductID: 4, Quantity: 1},
+		},
+	}
+
+	gomock.InOrder(
+		mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(4), gomock.Any()).Return(&models.Product{ID: 4, Price: 10, Stock: 5}, nil),
+		mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(9), gomock.Any()).Return(&models.Product{ID: 9, Price: 20, Stock: 5}, nil),
+	)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), gomock.Any(), 4, gomock.Any()).Return(nil).Times(2)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), order, gomock.Any()).Return(nil).Times(1)
+
+	err := orderService.CreateOrder(context.Background(), order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 30.0, order.TotalAmount)
+}
+
+// Test para CreateOrder: un deadlock de MySQL provoca el reintento de toda la transacción
+func TestCreateOrder_RetriesOnDeadlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	order := &models.Order{
+		OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}},
+	}
+
+	// Cada intento vuelve a leer el producto desde la base de datos
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).DoAndReturn(func(_ context.Context, id uint, _ *gorm.DB) (*models.Product, error) {
+		return &models.Product{ID: id, Price: 100, Stock: 10}, nil
+	}).Times(2)
+	gomock.InOrder(
+		mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}),
+		mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(nil),
+	)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), order, gomock.Any()).Return(nil).Times(1)
+
+	err := orderService.CreateOrder(context.Background(), order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200.0, order.TotalAmount)
+}
+
+// Test para CreateOrder: si el cliente se desconecta mientras se espera para reintentar, la
+// transacción no se vuelve a ejecutar
+func TestCreateOrder_StopsRetryingWhenCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	mockMetrics := mocks.NewMockOrderMetrics(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, mockMetrics)
+
+	order := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Price: 100, Stock: 10}, nil).Times(1)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).DoAndReturn(func(context.Context, uint, int, *gorm.DB) error {
+		cancel()
+		return &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	}).Times(1)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockMetrics.EXPECT().OrderFailed(ports.OrderFailureCanceled).Times(1)
+
+	err := orderService.CreateOrder(ctx, order)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// Test para CreateOrder: la creación queda en un span del que cuelgan los repositorios, con los
+// reintentos como eventos
+func TestCreateOrder_RecordsSpan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	order := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}}
+
+	var repoParents []trace.SpanID
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).DoAndReturn(func(ctx context.Context, id uint, _ *gorm.DB) (*models.Product, error) {
+		repoParents = append(repoParents, trace.SpanContextFromContext(ctx).SpanID())
+		return &models.Product{ID: id, Price: 100, Stock: 10}, nil
+	}).Times(2)
+	gomock.InOrder(
+		mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}),
+		mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(nil),
+	)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), order, gomock.Any()).DoAndReturn(func(_ context.Context, order *models.Order, _ *gorm.DB) error {
+		order.ID = 7
+		return nil
+	})
+
+	assert.NoError(t, orderService.CreateOrder(context.Background(), order))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "OrderService.CreateOrder", span.Name())
+		assert.Contains(t, span.Attributes(), attribute.Int64("order.id", 7))
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		if assert.Len(t, span.Events(), 1) {
+			assert.Equal(t, "Transacción reintentada", span.Events()[0].Name)
+		}
+		assert.Equal(t, []trace.SpanID{span.SpanContext().SpanID(), span.SpanContext().SpanID()}, repoParents)
+	}
+}
+
+// Test para CreateOrder: tras confirmar la orden se publican su creación y el stock restante
+func TestCreateOrder_PublishesEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	mockEventPublisher := mocks.NewMockEventPublisher(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, mockEventPublisher, nil)
+
+	variantID := uint(3)
+	order := &models.Order{
+		CustomerName: "Customer 1",
+		OrderItems: []models.OrderItem{
+			{ProductID: 1, Quantity: 2},
+			{ProductID: 2, VariantID: &variantID, Quantity: 1},
+		},
+	}
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Price: 100, Stock: 10}, nil)
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(2), gomock.Any()).Return(&models.Product{ID: 2, Price: 50, Stock: 0}, nil)
+	mockProductRepo.EXPECT().GetVariantByID(gomock.Any(), uint(3), gomock.Any()).Return(&models.ProductVariant{ID: 3, ProductID: 2, Stock: 4}, nil)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(nil)
+	mockProductRepo.EXPECT().UpdateVariantStock(gomock.Any(), uint(3), 3, gomock.Any()).Return(nil)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), order, gomock.Any()).Return(nil)
+
+	var published []ports.Event
+	mockEventPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event ports.Event) error {
+		published = append(published, event)
+		return nil
+	}).Times(3)
+
+	err := orderService.CreateOrder(context.Background(), order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ports.EventOrderCreated, published[0].Type)
+	assert.Same(t, order, published[0].Order)
+	assert.Equal(t, ports.StockChange{ProductID: 1, Stock: 8}, *published[1].Stock)
+	assert.Equal(t, ports.StockChange{ProductID: 2, VariantID: &variantID, Stock: 3}, *published[2].Stock)
+}
+
+// Test para UpdateOrderStatus: el estado avanza solo si la orden sigue en el estado leído y se publica el cambio
+func TestUpdateOrderStatus_PublishesEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockEventPublisher := mocks.NewMockEventPublisher(ctrl)
+
+	service := NewOrderService(mockOrderRepo, nil, nil, mockEventPublisher, nil)
+
+	mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&models.Order{ID: 1, Status: models.OrderStatusPending}, nil)
+	mockOrderRepo.EXPECT().UpdateStatus(gomock.Any(), uint(1), models.OrderStatusPending, models.OrderStatusConfirmed).Return(true, nil)
+
+	var published ports.Event
+	mockEventPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event ports.Event) error {
+		published = event
+		return nil
+	})
+
+	order, err := service.UpdateOrderStatus(context.Background(), 1, models.OrderStatusConfirmed)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusConfirmed, order.Status)
+	assert.Equal(t, ports.EventOrderStatusChanged, published.Type)
+	assert.Same(t, order, published.Order)
+}
+
+// Test para UpdateOrderStatus: los estados no se saltean ni retroceden
+func TestUpdateOrderStatus_InvalidTransition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+
+	service := NewOrderService(mockOrderRepo, nil, nil, nil, nil)
+
+	for _, tc := range []struct{ from, to string }{
+		{models.OrderStatusPending, models.OrderStatusShipped},
+		{models.OrderStatusShipped, models.OrderStatusConfirmed},
+		{models.OrderStatusDelivered, models.OrderStatusDelivered},
+	} {
+		mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&models.Order{ID: 1, Status: tc.from}, nil)
+
+		order, err := service.UpdateOrderStatus(context.Background(), 1, tc.to)
+
+		assert.Nil(t, order)
+		assert.True(t, apperrors.IsCode(err, apperrors.CodeInvalidStatusChange), "%s -> %s", tc.from, tc.to)
+	}
+}
+
+// Test para UpdateOrderStatus: si otra solicitud cambió el estado antes, se responde con un conflicto sin publicar
+func TestUpdateOrderStatus_ConcurrentChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockEventPublisher := mocks.NewMockEventPublisher(ctrl)
+
+	service := NewOrderService(mockOrderRepo, nil, nil, mockEventPublisher, nil)
+
+	mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&models.Order{ID: 1, Status: models.OrderStatusConfirmed}, nil)
+	mockOrderRepo.EXPECT().UpdateStatus(gomock.Any(), uint(1), models.OrderStatusConfirmed, models.OrderStatusShipped).Return(false, nil)
+
+	order, err := service.UpdateOrderStatus(context.Background(), 1, models.OrderStatusShipped)
+
+	assert.Nil(t, order)
+	assert.True(t, apperrors.IsCode(err, apperrors.CodeInvalidStatusChange))
+}
+
+// Test para UpdateOrderStatus cuando la orden no existe
+func TestUpdateOrderStatus_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+
+	service := NewOrderService(mockOrderRepo, nil, nil, nil, nil)
+
+	mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	order, err := service.UpdateOrderStatus(context.Background(), 1, models.OrderStatusConfirmed)
+
 	assert.Nil(t, order)
+	assert.True(t, apperrors.IsCode(err, apperrors.CodeOrderNotFound))
+}
+
+func TestSearchOrders_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	service := NewOrderService(mockOrderRepo, mockProductRepo, nil, nil, nil)
+
+	filter := ports.OrderFilter{CustomerName: "Ana", Page: 1, PageSize: 20}
+	expectedOrders := []models.Order{{ID: 2, CustomerName: "Ana"}, {ID: 1, CustomerName: "Ana María"}}
+	mockOrderRepo.EXPECT().Search(gomock.Any(), filter).Return(expectedOrders, int64(2), nil).Times(1)
+
+	orders, total, err := service.SearchOrders(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, expectedOrders, orders)
+}
+
+// Test para CreateOrders en modo independiente: cada orden se crea o falla por separado
+func TestCreateOrders_Independent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	first := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}}
+	second := &models.Order{OrderItems: []models.OrderItem{{ProductID: 2, Quantity: 1}}}
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Price: 100, Stock: 10}, nil)
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(2), gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(nil)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), first, gomock.Any()).Return(nil).Times(1)
+
+	errs := orderService.CreateOrders(context.Background(), []*models.Order{first, second}, nil, false)
+
+	assert.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.Equal(t, 200.0, first.TotalAmount)
+
+	appErr, ok := apperrors.As(errs[1])
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.CodeProductNotFound, appErr.Code)
+}
+
+// Test para CreateOrders: el span del lote registra el error de las órdenes que fallaron
+func TestCreateOrders_RecordsSpanError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	order := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}}
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+
+	errs := orderService.CreateOrders(context.Background(), []*models.Order{order}, nil, true)
+	assert.Error(t, errs[0])
+
+	var batchSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "OrderService.CreateOrders" {
+			batchSpan = span
+		}
+	}
+	if assert.NotNil(t, batchSpan) {
+		assert.Equal(t, codes.Error, batchSpan.Status().Code)
+		assert.Equal(t, "producto no encontrado", batchSpan.Status().Description)
+	}
+}
+
+// Test para CreateOrders: se registran las órdenes creadas con su importe y las fallidas con su motivo
+func TestCreateOrders_RecordsMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	mockMetrics := mocks.NewMockOrderMetrics(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, mockMetrics)
+
+	first := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}}
+	second := &models.Order{OrderItems: []models.OrderItem{{ProductID: 2, Quantity: 1}}}
+	third := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 50}}}
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Price: 100, Stock: 10}, nil).Times(2)
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(2), gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(nil)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), first, gomock.Any()).Return(nil).Times(1)
+
+	mockMetrics.EXPECT().OrderCreated(200.0).Times(1)
+	mockMetrics.EXPECT().OrderFailed(ports.OrderFailureProductNotFound).Times(1)
+	mockMetrics.EXPECT().OrderFailed(ports.OrderFailureStock).Times(1)
+
+	errs := orderService.CreateOrders(context.Background(), []*models.Order{first, second, third}, nil, false)
+
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.Error(t, errs[2])
+}
+
+func TestOrderFailureReason(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{apperrors.InsufficientStock(1, nil, 5, 2), ports.OrderFailureStock},
+		{apperrors.NotFound(apperrors.CodeProductNotFound, "producto no encontrado"), ports.OrderFailureProductNotFound},
+		{apperrors.NotFound(apperrors.CodeVariantNotFound, "variante no encontrada"), ports.OrderFailureProductNotFound},
+		{apperrors.Conflict(apperrors.CodeBatchAborted, "lote abortado"), ports.OrderFailureBatchAborted},
+		{apperrors.Internal(apperrors.CodeInternal, "error interno", errors.New("conexión perdida")), ports.OrderFailureDatabase},
+		{apperrors.Conflict(apperrors.CodeTransactionConflict, "contención"), ports.OrderFailureDatabase},
+		{errors.New("conexión perdida"), ports.OrderFailureDatabase},
+		{apperrors.Conflict(apperrors.CodeIdempotencyInProgress, "en curso"), ports.OrderFailureOther},
+		{context.DeadlineExceeded, ports.OrderFailureCanceled},
+		{apperrors.Internal(apperrors.CodeInternal, "error interno", context.Canceled), ports.OrderFailureCanceled},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, orderFailureReason(tt.err), tt.err.Error())
+	}
+}
+
+// Test para CreateOrders en modo todo o nada: las órdenes descuentan el stock acumulado del lote
+func TestCreateOrders_AllOrNothing_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	first := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}}
+	second := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 3}}}
+
+	// El producto se bloquea una sola vez para todo el lote
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Name: "Laptop", Price: 100, Stock: 10}, nil).Times(1)
+	gomock.InOrder(
+		mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(nil),
+		mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 5, gomock.Any()).Return(nil),
+	)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	errs := orderService.CreateOrders(context.Background(), []*models.Order{first, second}, nil, true)
+
+	assert.Equal(t, []error{nil, nil}, errs)
+	assert.Equal(t, 200.0, first.TotalAmount)
+	assert.Equal(t, 300.0, second.TotalAmount)
+	assert.Equal(t, "Laptop", second.OrderItems[0].Product.Name)
+}
+
+// Test para CreateOrders en modo todo o nada: si una orden falla no se crea ninguna
+func TestCreateOrders_AllOrNothing_AbortsWhenOneFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+	first := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 6}}}
+	second := &models.Order{OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 6}}}
+
+	// La segunda orden ya no tiene stock suficiente tras descontar la primera
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Price: 100, Stock: 10}, nil).Times(1)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 4, gomock.Any()).Return(nil).Times(1)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), first, gomock.Any()).Return(nil).Times(1)
+
+	errs := orderService.CreateOrders(context.Background(), []*models.Order{first, second}, nil, true)
+
+	assert.Len(t, errs, 2)
+
+	aborted, ok := apperrors.As(errs[0])
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.CodeBatchAborted, aborted.Code)
+
+	failed, ok := apperrors.As(errs[1])
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.CodeInsufficientStock, failed.Code)
+	assert.Equal(t, 4, failed.Details["available"])
+}
+
+// TestCreateOrderIdempotent_ReturnsBoundOrder verifica que la clave se asocie con la orden en la misma
+// transacción y que un reintento que toma la clave reciba esa orden sin crear otra
+func TestCreateOrderIdempotent_ReturnsBoundOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	db.AutoMigrate(&models.IdempotencyKey{})
+	store := idempotency.NewSQLStore(db, time.Minute)
+	ctx := context.Background()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	mockMetrics := mocks.NewMockOrderMetrics(ctrl)
+
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, mockMetrics)
+
+	order := &models.Order{CustomerName: "Customer 1", OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}}
+
+	// El reintento devuelve la orden ya creada sin contarla de nuevo
+	mockMetrics.EXPECT().OrderCreated(200.0).Times(1)
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Price: 100, Stock: 10}, nil).Times(1)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 8, gomock.Any()).Return(nil).Times(1)
+	mockOrderRepo.EXPECT().Create(gomock.Any(), order, gomock.Any()).DoAndReturn(func(_ context.Context, order *models.Order, tx *gorm.DB) error {
+		order.ID = 7
+		return nil
+	}).Times(1)
+
+	lock, _, err := store.Acquire(ctx, "client:key-1", "fp", ports.IdempotencyOptions{Lease: time.Minute})
+	assert.NoError(t, err)
+	assert.NoError(t, orderService.CreateOrderIdempotent(context.Background(), order, lock))
+
+	var key models.IdempotencyKey
+	assert.NoError(t, db.First(&key, "idempotency_key = ?", "client:key-1").Error)
+	assert.Equal(t, uint(7), *key.OrderID)
+
+	// La respuesta no llegó a guardarse: el reintento toma la clave y recibe la orden ya creada
+	assert.NoError(t, lock.Release(ctx))
+	retry, _, err := store.Acquire(ctx, "client:key-1", "fp", ports.IdempotencyOptions{Lease: time.Minute})
+	assert.NoError(t, err)
+
+	mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(7)).Return(&models.Order{ID: 7, CustomerName: "Customer 1", TotalAmount: 200}, nil).Times(1)
+
+	retried := &models.Order{CustomerName: "Customer 1", OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}}
+	assert.NoError(t, orderService.CreateOrderIdempotent(context.Background(), retried, retry))
+	assert.Equal(t, uint(7), retried.ID)
+	assert.Equal(t, float64(200), retried.TotalAmount)
+}
+
+// TestCreateOrders_BindsIdempotencyKeys verifica que en ambos modos cada clave se asocie con su orden
+// en la transacción que la crea y que un reintento del lote reciba las órdenes ya creadas
+func TestCreateOrders_BindsIdempotencyKeys(t *testing.T) {
+	for _, allOrNothing := range []bool{false, true} {
+		t.Run(fmt.Sprintf("allOrNothing=%v", allOrNothing), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			db, _ := gorm.Open(sqlite.Open("file:"+strings.ReplaceAll(t.Name(), "/", "_")+"?mode=memory&cache=shared"), &gorm.Config{})
+			db.AutoMigrate(&models.IdempotencyKey{})
+			store := idempotency.NewSQLStore(db, time.Minute)
+			ctx := context.Background()
+			options := ports.IdempotencyOptions{Lease: time.Minute}
+
+			mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+			mockProductRepo := mocks.NewMockProductRepository(ctrl)
+
+			orderService := NewOrderService(mockOrderRepo, mockProductRepo, db, nil, nil)
+
+			newOrders := func() []*models.Order {
+				return []*models.Order{
+					{CustomerName: "Customer 1", OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 2}}},
+					{CustomerName: "Customer 2", OrderItems: []models.OrderItem{{ProductID: 1, Quantity: 1}}},
+				}
+			}
+			acquire := func(keys ...string) []ports.IdempotencyLock {
+				locks := make([]ports.IdempotencyLock, len(keys))
+				for i, key := range keys {
+					if key == "" {
+						continue
+					}
+					lock, _, err := store.Acquire(ctx, key, "fp", options)
+					assert.NoError(t, err)
+					locks[i] = lock
+				}
+				return locks
+			}
+
+			mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Price: 100, Stock: 10}, nil).AnyTimes()
+			mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			nextID := uint(6)
+			mockOrderRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order *models.Order, tx *gorm.DB) error {
+				nextID++
+				order.ID = nextID
+				return nil
+			}).Times(3)
+
+			// Solo la primera orden tiene clave de idempotencia
+			locks := acquire("client:key-1", "")
+			errs := orderService.CreateOrders(ctx, newOrders(), locks, allOrNothing)
+			assert.Equal(t, []error{nil, nil}, errs)
+
+			var key models.IdempotencyKey
+			assert.NoError(t, db.First(&key, "idempotency_key = ?", "client:key-1").Error)
+			if assert.NotNil(t, key.OrderID) {
+				assert.Equal(t, uint(7), *key.OrderID)
+			}
+
+			// La respuesta no llegó a guardarse: el reintento recibe la orden ya creada y solo crea la otra
+			assert.NoError(t, locks[0].Release(ctx))
+			mockOrderRepo.EXPECT().FindByID(gomock.Any(), uint(7)).Return(&models.Order{ID: 7, CustomerName: "Customer 1", TotalAmount: 200}, nil).Times(1)
+
+			retried := newOrders()
+			errs = orderService.CreateOrders(ctx, retried, acquire("client:key-1", ""), allOrNothing)
+			assert.Equal(t, []error{nil, nil}, errs)
+			assert.Equal(t, uint(7), retried[0].ID)
+			assert.Equal(t, float64(200), retried[0].TotalAmount)
+			assert.Equal(t, uint(9), retried[1].ID)
+		})
+	}
 }
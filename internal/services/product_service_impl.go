@@ -1,44 +1,354 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"log"
+	"log/slog"
+	"order_management/internal/apperrors"
 	"order_management/internal/models"
 	"order_management/internal/ports"
+	"order_management/pkg/database"
 
 	"gorm.io/gorm"
 )
 
+const (
+	ExportBatchSize = 500
+)
+
 type ProductServiceImpl struct {
-	productRepo ports.ProductRepository
-	db          *gorm.DB
+	productRepo    ports.ProductRepository
+	db             *gorm.DB
+	eventPublisher ports.EventPublisher
+}
+
+// NewProductService crea una nueva instancia de ProductService. eventPublisher puede ser nil si no
+// se necesitan eventos en tiempo real.
+func NewProductService(productRepo ports.ProductRepository, db *gorm.DB, eventPublisher ports.EventPublisher) ports.ProductService {
+	return &ProductServiceImpl{productRepo: productRepo, db: db, eventPublisher: eventPublisher}
 }
 
-func NewProductService(productRepo ports.ProductRepository, db *gorm.DB) ports.ProductService {
-	return &ProductServiceImpl{productRepo: productRepo, db: db}
+func (s *ProductServiceImpl) SearchProducts(ctx context.Context, filter ports.ProductFilter) ([]models.Product, int64, error) {
+	return s.productRepo.Search(ctx, filter)
 }
 
-func (s *ProductServiceImpl) GetAllProducts() ([]models.Product, error) {
-	return s.productRepo.GetAll()
+// GetProductsByIDs obtiene en una sola consulta los productos indicados que existan
+func (s *ProductServiceImpl) GetProductsByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
+	if len(ids) == 0 {
+		return []models.Product{}, nil
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error al buscar productos", "error", err)
+		return nil, apperrors.Internal(apperrors.CodeInternal, "error al buscar productos", err)
+	}
+	return products, nil
 }
 
-func (s *ProductServiceImpl) UpdateStock(id uint, stock int) error {
+func (s *ProductServiceImpl) UpdateStock(ctx context.Context, id uint, stock int) error {
 	// Iniciar transacción
-	tx := s.db.Begin()
+	tx := s.db.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
 		}
 	}()
 
-	if err := s.productRepo.UpdateStock(id, stock, tx); err != nil {
-		return err
+	// Verificar que el producto exista antes de actualizarlo y de publicar el evento
+	if _, err := s.productRepo.GetByID(ctx, id, tx); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeProductNotFound, "producto no encontrado").WithDetail("product_id", id)
+		}
+		return apperrors.Internal(apperrors.CodeInternal, "error al buscar producto", err)
+	}
+
+	if err := s.productRepo.UpdateStock(ctx, id, stock, tx); err != nil {
+		slog.ErrorContext(ctx, "Error al actualizar el stock del producto", "product_id", id, "error", err)
+		tx.Rollback()
+		return apperrors.Internal(apperrors.CodeStockUpdateFailed, "error al actualizar stock", err)
 	}
 
 	// Commit si todo fue exitoso
 	if err := tx.Commit().Error; err != nil {
-		log.Printf("Error al confirmar la transacción: %v", err)
-		return errors.New("error al confirmar la transacción")
+		slog.ErrorContext(ctx, "Error al confirmar la transacción", "error", err)
+		return apperrors.Internal(apperrors.CodeInternal, "error al confirmar la transacción", err)
 	}
+
+	publishEvent(ctx, s.eventPublisher, ports.Event{
+		Type:  ports.EventStockChanged,
+		Stock: &ports.StockChange{ProductID: id, Stock: stock},
+	})
 	return nil
 }
+
+func (s *ProductServiceImpl) UpdateVariantStock(ctx context.Context, productID uint, variantID uint, stock int) error {
+	// Iniciar transacción
+	tx := s.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Verificar que la variante pertenezca al producto indicado
+	variant, err := s.productRepo.GetVariantByID(ctx, variantID, tx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return apperrors.Internal(apperrors.CodeInternal, "error al buscar variante", err)
+	}
+	if err != nil || variant.ProductID != productID {
+		tx.Rollback()
+		return apperrors.NotFound(apperrors.CodeVariantNotFound, "variante no encontrada").
+			WithDetail("product_id", productID).
+			WithDetail("variant_id", variantID)
+	}
+
+	if err := s.productRepo.UpdateVariantStock(ctx, variantID, stock, tx); err != nil {
+		slog.ErrorContext(ctx, "Error al actualizar el stock de la variante", "variant_id", variantID, "error", err)
+		tx.Rollback()
+		return apperrors.Internal(apperrors.CodeStockUpdateFailed, "error al actualizar stock", err)
+	}
+
+	// Commit si todo fue exitoso
+	if err := tx.Commit().Error; err != nil {
+		slog.ErrorContext(ctx, "Error al confirmar la transacción", "error", err)
+		return apperrors.Internal(apperrors.CodeInternal, "error al confirmar la transacción", err)
+	}
+
+	publishEvent(ctx, s.eventPublisher, ports.Event{
+		Type:  ports.EventStockChanged,
+		Stock: &ports.StockChange{ProductID: productID, VariantID: &variantID, Stock: stock},
+	})
+	return nil
+}
+
+// ImportProducts aplica la importación masiva dentro de una única transacción. Si alguna fila
+// falla, o si se trata de una simulación (dryRun), la transacción se revierte y nada se aplica.
+// Los errores de la base de datos, a diferencia de los de cada fila, cancelan la importación.
+func (s *ProductServiceImpl) ImportProducts(ctx context.Context, rows []ports.ProductImportRow, dryRun bool) (*ports.ProductImportReport, error) {
+	var report *ports.ProductImportReport
+	err := withTxRetry(ctx, func() error {
+		var err error
+		report, err = s.importProductsTx(ctx, rows, dryRun)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Applied {
+		s.publishImportEvents(ctx, rows, report.Results)
+	}
+	return report, nil
+}
+
+// importProductsTx ejecuta un intento de la importación dentro de una transacción
+func (s *ProductServiceImpl) importProductsTx(ctx context.Context, rows []ports.ProductImportRow, dryRun bool) (*ports.ProductImportReport, error) {
+	// Iniciar transacción
+	tx := s.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	products, err := s.lockImportProducts(ctx, rows, tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	report := &ports.ProductImportReport{Results: make([]ports.ProductImportResult, len(rows))}
+	failed := false
+
+	for i, row := range rows {
+		var result ports.ProductImportResult
+		var err error
+		if len(row.Errors) > 0 {
+			result = ports.ProductImportResult{Line: row.Line, ProductID: row.ProductID, SKU: row.SKU, Errors: row.Errors}
+		} else if row.SKU == "" {
+			result, err = s.importProductRow(ctx, row, products, tx)
+		} else {
+			result, err = s.importVariantRow(ctx, row, products, tx)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if len(result.Errors) > 0 {
+			result.Action = ports.ImportActionError
+			failed = true
+		}
+		report.Results[i] = result
+	}
+
+	if failed || dryRun {
+		tx.Rollback()
+		return report, nil
+	}
+
+	// Commit si todas las filas fueron válidas
+	if err := tx.Commit().Error; err != nil {
+		slog.ErrorContext(ctx, "Error al confirmar la transacción", "error", err)
+		return nil, apperrors.Internal(apperrors.CodeInternal, "error al confirmar la transacción", err)
+	}
+
+	report.Applied = true
+	return report, nil
+}
+
+// lockImportProducts bloquea en orden ascendente de ID, como el checkout, los productos existentes que
+// referencian las filas, para que la importación y las órdenes no se bloqueen mutuamente. Los productos
+// que no existen no figuran en el resultado.
+func (s *ProductServiceImpl) lockImportProducts(ctx context.Context, rows []ports.ProductImportRow, tx *gorm.DB) (map[uint]*models.Product, error) {
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		if len(row.Errors) == 0 && row.ProductID != 0 {
+			ids = append(ids, row.ProductID)
+		}
+	}
+
+	products := make(map[uint]*models.Product)
+	for _, productID := range uniqueSorted(ids) {
+		product, err := s.productRepo.GetByID(ctx, productID, tx)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Error al buscar el producto importado", "product_id", productID, "error", err)
+			return nil, apperrors.Internal(apperrors.CodeInternal, "error al buscar producto", err)
+		}
+		products[productID] = product
+	}
+	return products, nil
+}
+
+// publishImportEvents publica el nuevo stock de los productos y variantes cuyas filas lo informaron
+func (s *ProductServiceImpl) publishImportEvents(ctx context.Context, rows []ports.ProductImportRow, results []ports.ProductImportResult) {
+	for i, row := range rows {
+		if row.Stock == nil {
+			continue
+		}
+
+		change := ports.StockChange{ProductID: results[i].ProductID, Stock: *row.Stock}
+		if row.SKU != "" {
+			variantID := results[i].VariantID
+			change.VariantID = &variantID
+		}
+		publishEvent(ctx, s.eventPublisher, ports.Event{Type: ports.EventStockChanged, Stock: &change})
+	}
+}
+
+// importProductRow crea un producto nuevo o actualiza los campos informados de uno existente, ya
+// bloqueado en products
+func (s *ProductServiceImpl) importProductRow(ctx context.Context, row ports.ProductImportRow, products map[uint]*models.Product, tx *gorm.DB) (ports.ProductImportResult, error) {
+	result := ports.ProductImportResult{Line: row.Line, ProductID: row.ProductID, Action: ports.ImportActionCreate}
+
+	product := &models.Product{}
+	if row.ProductID != 0 {
+		existing, ok := products[row.ProductID]
+		if !ok {
+			result.Errors = append(result.Errors, "producto no encontrado")
+			return result, nil
+		}
+		product = existing
+		result.Action = ports.ImportActionUpdate
+	} else {
+		if row.Name == "" {
+			result.Errors = append(result.Errors, "name es obligatorio para crear un producto")
+		}
+		if row.Price == nil {
+			result.Errors = append(result.Errors, "price es obligatorio para crear un producto")
+		}
+		if len(result.Errors) > 0 {
+			return result, nil
+		}
+	}
+
+	if row.Name != "" {
+		product.Name = row.Name
+	}
+	if row.Price != nil {
+		product.Price = *row.Price
+	}
+	if row.Stock != nil {
+		product.Stock = *row.Stock
+	}
+	if row.CategoryID != nil {
+		product.CategoryID = row.CategoryID
+	}
+
+	if err := s.productRepo.Save(ctx, product, tx); err != nil {
+		if database.IsRetryableTxError(err) {
+			return result, err
+		}
+		slog.ErrorContext(ctx, "Error al guardar el producto importado", "line", row.Line, "error", err)
+		result.Errors = append(result.Errors, "error al guardar el producto")
+		return result, nil
+	}
+
+	result.ProductID = product.ID
+	return result, nil
+}
+
+// importVariantRow crea o actualiza, identificada por su SKU, una variante del producto indicado, ya
+// bloqueado en products
+func (s *ProductServiceImpl) importVariantRow(ctx context.Context, row ports.ProductImportRow, products map[uint]*models.Product, tx *gorm.DB) (ports.ProductImportResult, error) {
+	result := ports.ProductImportResult{Line: row.Line, ProductID: row.ProductID, SKU: row.SKU, Action: ports.ImportActionCreate}
+
+	if row.ProductID == 0 {
+		result.Errors = append(result.Errors, "product_id es obligatorio para una variante")
+		return result, nil
+	}
+	if _, ok := products[row.ProductID]; !ok {
+		result.Errors = append(result.Errors, "producto no encontrado")
+		return result, nil
+	}
+
+	variant, err := s.productRepo.GetVariantBySKU(ctx, row.SKU, tx)
+	switch {
+	case err == nil:
+		if variant.ProductID != row.ProductID {
+			result.Errors = append(result.Errors, "el SKU pertenece a otro producto")
+			return result, nil
+		}
+		result.Action = ports.ImportActionUpdate
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		variant = &models.ProductVariant{ProductID: row.ProductID, SKU: row.SKU}
+	case database.IsRetryableTxError(err):
+		return result, err
+	default:
+		slog.ErrorContext(ctx, "Error al buscar la variante importada", "line", row.Line, "sku", row.SKU, "error", err)
+		return result, apperrors.Internal(apperrors.CodeInternal, "error al buscar variante", err)
+	}
+
+	if row.Price != nil {
+		variant.Price = row.Price
+	}
+	if row.Stock != nil {
+		variant.Stock = *row.Stock
+	}
+	if row.Attributes != nil {
+		variant.Attributes = row.Attributes
+	}
+
+	if err := s.productRepo.SaveVariant(ctx, variant, tx); err != nil {
+		if database.IsRetryableTxError(err) {
+			return result, err
+		}
+		slog.ErrorContext(ctx, "Error al guardar la variante importada", "line", row.Line, "error", err)
+		result.Errors = append(result.Errors, "error al guardar la variante")
+		return result, nil
+	}
+
+	result.VariantID = variant.ID
+	return result, nil
+}
+
+// ExportProducts recorre el catálogo completo en lotes entregando cada lote a fn
+func (s *ProductServiceImpl) ExportProducts(ctx context.Context, fn func(products []models.Product) error) error {
+	return s.productRepo.FindInBatches(ctx, ExportBatchSize, fn)
+}
@@ -1,8 +1,11 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"order_management/internal/apperrors"
 	"order_management/internal/models"
+	"order_management/internal/ports"
 	"order_management/test/mocks"
 	"testing"
 
@@ -12,8 +15,8 @@ import (
 	"gorm.io/gorm"
 )
 
-// TestGetAllProducts_Success verifica que GetAllProducts() retorne correctamente la lista de productos.
-func TestGetAllProducts_Success(t *testing.T) {
+// TestSearchProducts_Success verifica que SearchProducts() retorne correctamente la página de productos.
+func TestSearchProducts_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -21,7 +24,7 @@ func TestGetAllProducts_Success(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 
 	mockProductRepo := mocks.NewMockProductRepository(ctrl)
-	productService := NewProductService(mockProductRepo, db)
+	productService := NewProductService(mockProductRepo, db, nil)
 
 	// Datos simulados
 	expectedProducts := []models.Product{
@@ -29,22 +32,25 @@ func TestGetAllProducts_Success(t *testing.T) {
 		{ID: 2, Name: "Producto 2", Stock: 5, Price: 50.0},
 	}
 
+	filter := ports.ProductFilter{Query: "Producto", InStock: true, Page: 1, PageSize: 20}
+
 	// Simula la respuesta exitosa del repositorio
 	mockProductRepo.
 		EXPECT().
-		GetAll().
-		Return(expectedProducts, nil)
+		Search(gomock.Any(), filter).
+		Return(expectedProducts, int64(2), nil)
 
 	// Ejecutar
-	products, err := productService.GetAllProducts()
+	products, total, err := productService.SearchProducts(context.Background(), filter)
 
 	// Verificar
 	assert.NoError(t, err)
 	assert.Equal(t, expectedProducts, products)
+	assert.Equal(t, int64(2), total)
 }
 
-// TestGetAllProducts_Failure verifica que GetAllProducts() retorne un error si falla la consulta a la base de datos.
-func TestGetAllProducts_Failure(t *testing.T) {
+// TestSearchProducts_Failure verifica que SearchProducts() retorne un error si falla la consulta a la base de datos.
+func TestSearchProducts_Failure(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -52,16 +58,16 @@ func TestGetAllProducts_Failure(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 
 	mockProductRepo := mocks.NewMockProductRepository(ctrl)
-	productService := NewProductService(mockProductRepo, db)
+	productService := NewProductService(mockProductRepo, db, nil)
 
 	// Simula un error en la base de datos
 	mockProductRepo.
 		EXPECT().
-		GetAll().
-		Return(nil, errors.New("error en base de datos"))
+		Search(gomock.Any(), gomock.Any()).
+		Return(nil, int64(0), errors.New("error en base de datos"))
 
 	// Ejecutar
-	products, err := productService.GetAllProducts()
+	products, _, err := productService.SearchProducts(context.Background(), ports.ProductFilter{Page: 1, PageSize: 20})
 
 	// Verificar
 	assert.Error(t, err)
@@ -77,7 +83,7 @@ func TestUpdateStock_Success(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 
 	mockProductRepo := mocks.NewMockProductRepository(ctrl)
-	productService := NewProductService(mockProductRepo, db)
+	productService := NewProductService(mockProductRepo, db, nil)
 
 	// Datos simulados
 	productId := uint(1)
@@ -86,17 +92,47 @@ func TestUpdateStock_Success(t *testing.T) {
 	// Simula la actualización exitosa del stock
 	mockProductRepo.
 		EXPECT().
-		UpdateStock(uint(1), 5, gomock.Any()).
+		GetByID(gomock.Any(), productId, gomock.Any()).
+		Return(&models.Product{ID: productId, Stock: 10}, nil)
+	mockProductRepo.
+		EXPECT().
+		UpdateStock(gomock.Any(), uint(1), 5, gomock.Any()).
 		Return(nil)
 
 	// Ejecutar
-	err := productService.UpdateStock(productId, newStock)
+	err := productService.UpdateStock(context.Background(), productId, newStock)
 
 	// Verificar
 	assert.NoError(t, err)
 	assert.Equal(t, newStock, 5)
 }
 
+// TestUpdateStock_PublishesEvent verifica que UpdateStock() publique el nuevo stock una vez confirmado.
+func TestUpdateStock_PublishesEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	mockEventPublisher := mocks.NewMockEventPublisher(ctrl)
+	productService := NewProductService(mockProductRepo, db, mockEventPublisher)
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(&models.Product{ID: 1, Stock: 10}, nil)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), uint(1), 5, gomock.Any()).Return(nil)
+	mockEventPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event ports.Event) error {
+		assert.Equal(t, ports.EventStockChanged, event.Type)
+		assert.Equal(t, ports.StockChange{ProductID: 1, Stock: 5}, *event.Stock)
+		assert.False(t, event.OccurredAt.IsZero())
+		return nil
+	}).Times(1)
+
+	err := productService.UpdateStock(context.Background(), 1, 5)
+
+	assert.NoError(t, err)
+}
+
 // TestUpdateStock_UpdateFailure verifica que UpdateStock() retorne un error si la actualización del stock falla.
 func TestUpdateStock_UpdateFailure(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -106,7 +142,7 @@ func TestUpdateStock_UpdateFailure(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 
 	mockProductRepo := mocks.NewMockProductRepository(ctrl)
-	productService := NewProductService(mockProductRepo, db)
+	productService := NewProductService(mockProductRepo, db, nil)
 
 	// Datos simulados
 	product := &models.Product{ID: 1, Name: "Producto 1", Stock: 10, Price: 100.0}
@@ -115,13 +151,308 @@ func TestUpdateStock_UpdateFailure(t *testing.T) {
 	// Simula un error en la actualización del stock
 	mockProductRepo.
 		EXPECT().
-		UpdateStock(product.ID, newStock, gomock.Any()).
+		GetByID(gomock.Any(), product.ID, gomock.Any()).
+		Return(product, nil)
+	mockProductRepo.
+		EXPECT().
+		UpdateStock(gomock.Any(), product.ID, newStock, gomock.Any()).
 		Return(errors.New("error al actualizar stock"))
 
 	// Ejecutar
-	err := productService.UpdateStock(product.ID, newStock)
+	err := productService.UpdateStock(context.Background(), product.ID, newStock)
 
 	// Verificar
 	assert.Error(t, err)
 	assert.Equal(t, "error al actualizar stock", err.Error())
 }
+
+// TestUpdateStock_ProductNotFound verifica que UpdateStock() retorne PRODUCT_NOT_FOUND sin actualizar
+// ni publicar eventos si el producto no existe.
+func TestUpdateStock_ProductNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	mockEventPublisher := mocks.NewMockEventPublisher(ctrl)
+	productService := NewProductService(mockProductRepo, db, mockEventPublisher)
+
+	mockProductRepo.
+		EXPECT().
+		GetByID(gomock.Any(), uint(999999), gomock.Any()).
+		Return(nil, gorm.ErrRecordNotFound)
+	mockProductRepo.EXPECT().UpdateStock(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockEventPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Times(0)
+
+	// Ejecutar
+	err := productService.UpdateStock(context.Background(), 999999, 5)
+
+	// Verificar
+	var appErr *apperrors.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.CodeProductNotFound, appErr.Code)
+	assert.Equal(t, apperrors.KindNotFound, appErr.Kind)
+}
+
+// TestUpdateVariantStock_Success verifica que UpdateVariantStock() actualice el stock de la variante.
+func TestUpdateVariantStock_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	variant := &models.ProductVariant{ID: 3, ProductID: 1, SKU: "CAM-L-ROJO", Stock: 2}
+
+	mockProductRepo.
+		EXPECT().
+		GetVariantByID(gomock.Any(), uint(3), gomock.Any()).
+		Return(variant, nil)
+	mockProductRepo.
+		EXPECT().
+		UpdateVariantStock(gomock.Any(), uint(3), 15, gomock.Any()).
+		Return(nil)
+
+	// Ejecutar
+	err := productService.UpdateVariantStock(context.Background(), 1, 3, 15)
+
+	// Verificar
+	assert.NoError(t, err)
+}
+
+// TestUpdateVariantStock_WrongProduct verifica que no se actualice una variante de otro producto.
+func TestUpdateVariantStock_WrongProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	variant := &models.ProductVariant{ID: 3, ProductID: 2, SKU: "PANT-32", Stock: 2}
+
+	mockProductRepo.
+		EXPECT().
+		GetVariantByID(gomock.Any(), uint(3), gomock.Any()).
+		Return(variant, nil)
+
+	// Ejecutar
+	err := productService.UpdateVariantStock(context.Background(), 1, 3, 15)
+
+	// Verificar
+	assert.Error(t, err)
+	assert.Equal(t, "variante no encontrada", err.Error())
+}
+
+// TestImportProducts_Success verifica que la importación cree y actualice productos y variantes.
+func TestImportProducts_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	price, stock := 25.0, 8
+	existing := &models.Product{ID: 1, Name: "Camiseta", Price: 20, Stock: 3}
+	rows := []ports.ProductImportRow{
+		{Line: 2, ProductID: 1, Stock: &stock},
+		{Line: 3, Name: "Gorra", Price: &price},
+		{Line: 4, ProductID: 1, SKU: "CAM-S", Stock: &stock, Attributes: map[string]string{"talla": "S"}},
+	}
+
+	// El producto se bloquea una sola vez aunque lo referencien varias filas
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(existing, nil).Times(1)
+	mockProductRepo.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, product *models.Product, _ *gorm.DB) error {
+		if product.ID == 0 {
+			product.ID = 2
+		}
+		return nil
+	}).Times(2)
+	mockProductRepo.EXPECT().GetVariantBySKU(gomock.Any(), "CAM-S", gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+	mockProductRepo.EXPECT().SaveVariant(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, variant *models.ProductVariant, _ *gorm.DB) error {
+		variant.ID = 10
+		return nil
+	})
+
+	// Ejecutar
+	report, err := productService.ImportProducts(context.Background(), rows, false)
+
+	// Verificar
+	assert.NoError(t, err)
+	assert.True(t, report.Applied)
+	assert.Equal(t, ports.ImportActionUpdate, report.Results[0].Action)
+	assert.Equal(t, 8, existing.Stock)
+	assert.Equal(t, ports.ImportActionCreate, report.Results[1].Action)
+	assert.Equal(t, uint(2), report.Results[1].ProductID)
+	assert.Equal(t, ports.ImportActionCreate, report.Results[2].Action)
+	assert.Equal(t, uint(10), report.Results[2].VariantID)
+}
+
+// TestImportProducts_LocksProductsInAscendingOrder verifica que la importación bloquee los productos en
+// orden ascendente de ID, como el checkout, sin importar el orden de las filas del CSV.
+func TestImportProducts_LocksProductsInAscendingOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	stock := 4
+	rows := []ports.ProductImportRow{
+		{Line: 2, ProductID: 5, Stock: &stock},
+		{Line: 3, ProductID: 2, Stock: &stock},
+		{Line: 4, ProductID: 9, Stock: &stock},
+		{Line: 5, ProductID: 5, SKU: "FUNDA-1", Stock: &stock},
+	}
+
+	gomock.InOrder(
+		mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(2), gomock.Any()).Return(&models.Product{ID: 2}, nil),
+		mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(5), gomock.Any()).Return(&models.Product{ID: 5}, nil),
+		mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(9), gomock.Any()).Return(nil, gorm.ErrRecordNotFound),
+	)
+	mockProductRepo.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockProductRepo.EXPECT().GetVariantBySKU(gomock.Any(), "FUNDA-1", gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+	mockProductRepo.EXPECT().SaveVariant(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	// Ejecutar
+	report, err := productService.ImportProducts(context.Background(), rows, false)
+
+	// Verificar: el producto inexistente es un error de su fila
+	assert.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Equal(t, []string{"producto no encontrado"}, report.Results[2].Errors)
+}
+
+// TestImportProducts_DatabaseErrorAborts verifica que un error de la base de datos al buscar un producto
+// cancele la importación con un error interno en lugar de informarse como un error de la fila.
+func TestImportProducts_DatabaseErrorAborts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	stock := 4
+	rows := []ports.ProductImportRow{{Line: 2, ProductID: 1, Stock: &stock}}
+
+	mockProductRepo.EXPECT().GetByID(gomock.Any(), uint(1), gomock.Any()).Return(nil, errors.New("conexión perdida"))
+
+	// Ejecutar
+	report, err := productService.ImportProducts(context.Background(), rows, false)
+
+	// Verificar
+	assert.Nil(t, report)
+	var appErr *apperrors.Error
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, apperrors.KindInternal, appErr.Kind)
+	}
+}
+
+// TestImportProducts_RowErrors verifica que una fila inválida impida aplicar toda la importación.
+func TestImportProducts_RowErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	price := 10.0
+	rows := []ports.ProductImportRow{
+		{Line: 2, Name: "Gorra", Price: &price},
+		{Line: 3, Name: "Bufanda"},
+		{Line: 4, SKU: "BUF-1", Errors: []string{"stock debe ser un entero mayor o igual a 0"}},
+	}
+
+	mockProductRepo.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	// Ejecutar
+	report, err := productService.ImportProducts(context.Background(), rows, false)
+
+	// Verificar
+	assert.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Equal(t, ports.ImportActionCreate, report.Results[0].Action)
+	assert.Equal(t, ports.ImportActionError, report.Results[1].Action)
+	assert.Equal(t, []string{"price es obligatorio para crear un producto"}, report.Results[1].Errors)
+	assert.Equal(t, ports.ImportActionError, report.Results[2].Action)
+}
+
+// TestImportProducts_DryRun verifica que la simulación valide las filas sin confirmar los cambios.
+func TestImportProducts_DryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Crear base de datos en memoria para pruebas
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	price := 10.0
+	rows := []ports.ProductImportRow{{Line: 2, Name: "Gorra", Price: &price}}
+
+	mockProductRepo.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	// Ejecutar
+	report, err := productService.ImportProducts(context.Background(), rows, true)
+
+	// Verificar
+	assert.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Equal(t, ports.ImportActionCreate, report.Results[0].Action)
+}
+
+func TestGetProductsByIDs_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	expectedProducts := []models.Product{{ID: 1, Name: "Producto 1"}, {ID: 3, Name: "Producto 3"}}
+	mockProductRepo.EXPECT().FindByIDs(gomock.Any(), []uint{1, 3}).Return(expectedProducts, nil).Times(1)
+
+	// Ejecutar
+	products, err := productService.GetProductsByIDs(context.Background(), []uint{1, 3})
+
+	// Verificar
+	assert.NoError(t, err)
+	assert.Equal(t, expectedProducts, products)
+}
+
+func TestGetProductsByIDs_EmptyDoesNotQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	// Sin IDs no se consulta el repositorio
+	mockProductRepo := mocks.NewMockProductRepository(ctrl)
+	productService := NewProductService(mockProductRepo, db, nil)
+
+	products, err := productService.GetProductsByIDs(context.Background(), nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, products)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"order_management/internal/apperrors"
+	"order_management/pkg/database"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	MaxTxAttempts      = 4
+	TxRetryBaseBackoff = 50 * time.Millisecond
+)
+
+// withTxRetry ejecuta fn y la reintenta con backoff exponencial y jitter mientras
+// la transacción sea abortada por un deadlock o un timeout de bloqueo. Si se agotan
+// los intentos se devuelve un error de conflicto para que el cliente reintente. Si ctx
+// se cancela durante la espera no se vuelve a intentar y se devuelve el error de ctx.
+func withTxRetry(ctx context.Context, fn func() error) error {
+	backoff := TxRetryBaseBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !database.IsRetryableTxError(err) {
+			return err
+		}
+		if attempt >= MaxTxAttempts {
+			return &apperrors.Error{
+				Kind:    apperrors.KindConflict,
+				Code:    apperrors.CodeTransactionConflict,
+				Message: "la operación no pudo completarse por contención, intente nuevamente",
+				Err:     err,
+			}
+		}
+
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)))
+		slog.WarnContext(ctx, "Transacción abortada por bloqueo, se reintenta",
+			"attempt", attempt, "max_attempts", MaxTxAttempts, "wait", wait.String(), "error", err)
+		// Los reintentos quedan en la traza para distinguir la espera por bloqueos del trabajo de cada intento
+		trace.SpanFromContext(ctx).AddEvent("Transacción reintentada", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.String("error", err.Error()),
+		))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
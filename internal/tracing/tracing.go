@@ -0,0 +1,95 @@
+// Package tracing configura las trazas de OpenTelemetry de la aplicación. Las trazas empiezan en la
+// solicitud HTTP o gRPC, o continúan la del cliente si envía la cabecera traceparent de W3C, y
+// bajan por los servicios y los repositorios hasta las consultas SQL y los comandos de Redis. Se
+// exportan a un collector por OTLP o a la salida estándar.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	// ServiceName identifica a la aplicación en las trazas
+	ServiceName = "order_management"
+)
+
+// Config define a dónde se exportan las trazas
+type Config struct {
+	// Exporter es none, stdout u otlp
+	Exporter string `yaml:"exporter"`
+	// Endpoint es la dirección host:puerto del collector OTLP por gRPC
+	Endpoint string `yaml:"endpoint"`
+	// SampleRatio es la fracción de las trazas nuevas que se registran; las que continúan la traza
+	// de un cliente respetan su decisión
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Propagator lee y escribe el contexto de la traza con las cabeceras traceparent y baggage de W3C
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Setup registra el proveedor de trazas y el propagador globales. Devuelve la función que exporta
+// las trazas pendientes y cierra el exportador, que debe llamarse al apagar la aplicación. Con el
+// exportador none no se registra ninguna traza, pero el contexto de los clientes se sigue propagando
+// para correlacionar los logs. El exportador stdout escribe en w.
+func Setup(ctx context.Context, config Config, w io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(Propagator)
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterOTLP:
+		exporter, err = otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(config.Endpoint), otlptracegrpc.WithInsecure())
+	default:
+		return nil, fmt.Errorf("exportador de trazas desconocido: %q", config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al crear el exportador de trazas: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("error al crear el recurso de las trazas: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start inicia un span hijo del que tenga ctx con el proveedor global
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(ServiceName).Start(ctx, name, opts...)
+}
+
+// End marca el span como fallido si err no es nil y lo termina
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans reemplaza el proveedor global durante el test y devuelve los spans terminados
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestSetup_Stdout(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterStdout, SampleRatio: 1}, &buf)
+	assert.NoError(t, err)
+
+	_, span := Start(context.Background(), "prueba")
+	span.End()
+
+	// Shutdown exporta los spans pendientes
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Contains(t, buf.String(), `"Name":"prueba"`)
+	assert.Contains(t, buf.String(), ServiceName)
+}
+
+func TestSetup_None(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, Propagator, otel.GetTextMapPropagator())
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "jaeger"}, nil)
+	assert.ErrorContains(t, err, "jaeger")
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := Start(context.Background(), "falla")
+	End(span, assert.AnError)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Len(t, spans[0].Events(), 1)
+	}
+}
+
+func TestRedisHook(t *testing.T) {
+	recorder := recordSpans(t)
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+	redisClient.AddHook(RedisHook{})
+
+	// Sin una traza en curso no se crean spans
+	assert.NoError(t, redisClient.Set(context.Background(), "fuera", "1", 0).Err())
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := Start(context.Background(), "solicitud")
+	assert.NoError(t, redisClient.Set(ctx, "clave", "1", 0).Err())
+	assert.ErrorIs(t, redisClient.Get(ctx, "no-existe").Err(), redis.Nil)
+	_, err := redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, "contador")
+		pipe.Expire(ctx, "contador", 0)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, parent.IsRecording())
+	parent.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 4) {
+		assert.Equal(t, "redis.set", spans[0].Name())
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		// Una clave inexistente no es un error
+		assert.Equal(t, "redis.get", spans[1].Name())
+		assert.Equal(t, codes.Unset, spans[1].Status().Code)
+		assert.Equal(t, "redis.pipeline", spans[2].Name())
+		assert.Equal(t, "solicitud", spans[3].Name())
+	}
+}
@@ -1,20 +1,118 @@
 package validators
 
 import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"order_management/internal/dtos"
+	"order_management/internal/i18n"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/pt"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	pt_translations "github.com/go-playground/validator/v10/translations/pt"
 )
 
 // CustomValidator implementa el validador de Echo con go-playground/validator
 type CustomValidator struct {
-	Validator *validator.Validate
+	Validator   *validator.Validate
+	translators *ut.UniversalTranslator
+}
+
+// ValidationError agrupa los errores de validación de una estructura y permite traducirlos
+type ValidationError struct {
+	Errors      validator.ValidationErrors
+	translators *ut.UniversalTranslator
+}
+
+func (e *ValidationError) Error() string {
+	return e.Errors.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Errors
+}
+
+// Fields devuelve un error por campo con el mensaje traducido al idioma indicado. Los campos se
+// identifican con la ruta que usa el cliente, por ejemplo items[0].quantity.
+func (e *ValidationError) Fields(lang string) []dtos.FieldErrorDTO {
+	translator, _ := e.translators.FindTranslator(lang, i18n.DefaultLanguage)
+
+	fields := make([]dtos.FieldErrorDTO, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		fields[i] = dtos.FieldErrorDTO{
+			Field:   fieldPath(fieldErr.Namespace()),
+			Rule:    fieldErr.Tag(),
+			Message: fieldErr.Translate(translator),
+		}
+	}
+	return fields
 }
 
 // Validate ejecuta la validación en la estructura recibida
 func (cv *CustomValidator) Validate(i interface{}) error {
-	return cv.Validator.Struct(i)
+	err := cv.Validator.Struct(i)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return &ValidationError{Errors: validationErrors, translators: cv.translators}
+	}
+	return err
 }
 
-// NewValidator crea una nueva instancia de CustomValidator
+// NewValidator crea una nueva instancia de CustomValidator con los mensajes de error traducidos
+// a los idiomas soportados por la API
 func NewValidator() *CustomValidator {
-	return &CustomValidator{Validator: validator.New()}
+	validate := validator.New()
+	validate.RegisterTagNameFunc(fieldName)
+
+	translators := ut.New(es.New(), es.New(), en.New(), pt.New())
+	registrations := map[string]func(*validator.Validate, ut.Translator) error{
+		"es": es_translations.RegisterDefaultTranslations,
+		"en": en_translations.RegisterDefaultTranslations,
+		"pt": pt_translations.RegisterDefaultTranslations,
+	}
+	for lang, register := range registrations {
+		translator, _ := translators.GetTranslator(lang)
+		if err := register(validate, translator); err != nil {
+			// Las traducciones son estáticas: un error aquí es un error de programación
+			panic(err)
+		}
+	}
+
+	return &CustomValidator{Validator: validate, translators: translators}
+}
+
+// fieldName devuelve el nombre con que el cliente envía el campo, en el JSON o en la consulta
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// fieldPath quita de la ruta del campo el nombre del tipo validado y los structs embebidos,
+// que no forman parte del JSON
+func fieldPath(namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+
+	path := segments[:0]
+	for _, segment := range segments {
+		if strings.HasSuffix(segment, "DTO") {
+			continue
+		}
+		path = append(path, segment)
+	}
+	return strings.Join(path, ".")
 }
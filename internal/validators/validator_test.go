@@ -0,0 +1,43 @@
+package validators
+
+import (
+	"testing"
+
+	"order_management/internal/dtos"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidate_FieldErrorsAreLocalized verifica que cada campo inválido se informe con la ruta JSON y el mensaje traducido
+func TestValidate_FieldErrorsAreLocalized(t *testing.T) {
+	validator := NewValidator()
+
+	err := validator.Validate(dtos.BatchOrderRequestDTO{
+		Mode: dtos.BatchModeIndependent,
+		Orders: []dtos.BatchOrderEntryDTO{
+			{OrderRequestDTO: dtos.OrderRequestDTO{Items: []dtos.OrderItemRequestDTO{{ProductID: 1, Quantity: 1}}}},
+		},
+	})
+
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+
+	assert.Equal(t, []dtos.FieldErrorDTO{
+		{Field: "orders[0].customer_name", Rule: "required", Message: "customer_name es un campo requerido"},
+	}, validationErr.Fields("es"))
+	assert.Equal(t, "customer_name is a required field", validationErr.Fields("en")[0].Message)
+	assert.Equal(t, "customer_name é obrigatório", validationErr.Fields("pt")[0].Message)
+	// Un idioma sin traducciones usa el idioma por defecto
+	assert.Equal(t, "customer_name es un campo requerido", validationErr.Fields("ja")[0].Message)
+}
+
+func TestValidate_ValidStruct(t *testing.T) {
+	validator := NewValidator()
+
+	err := validator.Validate(dtos.OrderRequestDTO{
+		CustomerName: "Customer 1",
+		Items:        []dtos.OrderItemRequestDTO{{ProductID: 1, Quantity: 1}},
+	})
+
+	assert.NoError(t, err)
+}
@@ -2,45 +2,83 @@ package database
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// InitDB inicializa y devuelve una conexión a la base de datos MySQL
-func InitDB() *gorm.DB {
-	// Obtener las variables de entorno para la conexión a MySQL
-	dbUser := getEnv("DB_USER", "order")
-	dbPassword := getEnv("DB_PASSWORD", "password")
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "3306")
-	dbName := getEnv("DB_NAME", "order_management")
+// LogLevels asocia los niveles de log aceptados en la configuración con los de GORM
+var LogLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
 
-	// Construir la cadena de conexión
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		dbUser, dbPassword, dbHost, dbPort, dbName,
-	)
+// Config define la conexión a MySQL y el tamaño del pool de conexiones
+type Config struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	// LogLevel es el nivel de log de GORM: silent, error, warn o info
+	LogLevel string `yaml:"log_level"`
+	// SlowQueryThreshold es la duración a partir de la cual una consulta se registra como lenta; 0
+	// desactiva el registro de consultas lentas
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"`
+	MaxOpenConns       int           `yaml:"max_open_conns"`
+	MaxIdleConns       int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime    time.Duration `yaml:"conn_max_lifetime"`
+	// RequireMigrations impide iniciar el servidor si hay migraciones del esquema pendientes; si es
+	// false solo se registra una advertencia
+	RequireMigrations bool `yaml:"require_migrations"`
+}
 
+// DSN devuelve la cadena de conexión a MySQL. La arma el driver, que escapa los caracteres
+// especiales de la contraseña y del nombre de la base y conserva sus valores por defecto.
+func (c Config) DSN() string {
+	dsn := mysqldriver.NewConfig()
+	dsn.User = c.User
+	dsn.Passwd = c.Password
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	dsn.DBName = c.Name
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+	dsn.ParseTime = true
+	dsn.Loc = time.Local
+	return dsn.FormatDSN()
+}
+
+// InitDB inicializa y devuelve una conexión a la base de datos MySQL. Las consultas se registran con
+// el logger por defecto de slog y en la traza de la solicitud que las ejecuta.
+func InitDB(config Config) (*gorm.DB, error) {
 	// Conectar a MySQL usando GORM
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	db, err := gorm.Open(mysql.Open(config.DSN()), &gorm.Config{
+		Logger: NewLogger(slog.Default(), LogLevels[config.LogLevel], config.SlowQueryThreshold),
 	})
 	if err != nil {
-		log.Fatalf("Error al conectar a la base de datos: %v", err)
+		return nil, fmt.Errorf("error al conectar a la base de datos: %w", err)
+	}
+	if err := db.Use(TracingPlugin{}); err != nil {
+		return nil, fmt.Errorf("error al registrar las trazas de las consultas: %w", err)
 	}
 
-	log.Println("Conexión a la base de datos MySQL establecida exitosamente")
-	return db
-}
-
-// getEnv obtiene una variable de entorno o un valor por defecto si no está definida
-func getEnv(key, defaultValue string) string {
-	value, exists := os.LookupEnv(key)
-	if !exists {
-		return defaultValue
+	// Limitar el pool para no agotar las conexiones de MySQL con varias instancias
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el pool de conexiones: %w", err)
 	}
-	return value
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+
+	slog.Info("Conexión a la base de datos MySQL establecida", "host", config.Host, "database", config.Name)
+	return db, nil
 }
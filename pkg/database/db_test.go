@@ -0,0 +1,28 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfig_DSN verifica que la contraseña y el nombre de la base lleguen al driver aunque tengan
+// caracteres con significado en la cadena de conexión
+func TestConfig_DSN(t *testing.T) {
+	config := Config{Host: "db.internal", Port: 3307, User: "app", Password: "p@ss:w/rd?x=1", Name: "orders"}
+
+	parsed, err := mysql.ParseDSN(config.DSN())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "app", parsed.User)
+	assert.Equal(t, "p@ss:w/rd?x=1", parsed.Passwd)
+	assert.Equal(t, "tcp", parsed.Net)
+	assert.Equal(t, "db.internal:3307", parsed.Addr)
+	assert.Equal(t, "orders", parsed.DBName)
+	assert.Equal(t, map[string]string{"charset": "utf8mb4"}, parsed.Params)
+	assert.True(t, parsed.ParseTime)
+	assert.Equal(t, time.Local, parsed.Loc)
+	assert.True(t, parsed.AllowNativePasswords)
+}
@@ -0,0 +1,24 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// MySQLErrLockWaitTimeout corresponde a ER_LOCK_WAIT_TIMEOUT
+	MySQLErrLockWaitTimeout = 1205
+	// MySQLErrDeadlock corresponde a ER_LOCK_DEADLOCK
+	MySQLErrDeadlock = 1213
+)
+
+// IsRetryableTxError indica si el error se debe a un deadlock o a un timeout de bloqueo de MySQL.
+// En ambos casos la transacción fue abortada y puede reintentarse completa.
+func IsRetryableTxError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == MySQLErrDeadlock || mysqlErr.Number == MySQLErrLockWaitTimeout
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Logger registra las consultas de GORM con slog, usando el contexto de cada consulta para que el
+// log incluya los datos de la solicitud que la originó. Las consultas se registran con los
+// marcadores ? en lugar de los valores, que pueden contener datos de los clientes.
+type Logger struct {
+	logger        *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+var _ gorm.ParamsFilter = (*Logger)(nil)
+
+// NewLogger crea el logger de GORM. Con el nivel error se registran las consultas fallidas, con warn
+// también las que superan slowThreshold (0 lo desactiva) y con info todas las consultas en debug.
+func NewLogger(l *slog.Logger, level logger.LogLevel, slowThreshold time.Duration) *Logger {
+	return &Logger{logger: l, level: level, slowThreshold: slowThreshold}
+}
+
+// LogMode devuelve una copia del logger con el nivel indicado
+func (l *Logger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *Logger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *Logger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *Logger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// ParamsFilter descarta los valores de la consulta para que GORM la registre parametrizada
+func (l *Logger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// Trace registra una consulta terminada. Los registros no encontrados no se consideran errores
+// porque los servicios los traducen a respuestas 404.
+func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		sql, rows := fc()
+		l.logger.ErrorContext(ctx, "Error en la consulta SQL",
+			"sql", sql, "rows", rows, "elapsed_ms", milliseconds(elapsed), "error", err)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.logger.WarnContext(ctx, "Consulta SQL lenta",
+			"sql", sql, "rows", rows, "elapsed_ms", milliseconds(elapsed), "threshold_ms", milliseconds(l.slowThreshold))
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.logger.DebugContext(ctx, "Consulta SQL", "sql", sql, "rows", rows, "elapsed_ms", milliseconds(elapsed))
+	}
+}
+
+// milliseconds expresa d en milisegundos con decimales, más legible en el log que los nanosegundos
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// traceLines ejecuta Trace con el nivel y la duración indicados y devuelve las líneas registradas
+func traceLines(t *testing.T, level logger.LogLevel, elapsed time.Duration, err error) []map[string]interface{} {
+	var buf bytes.Buffer
+	l := NewLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), level, 100*time.Millisecond)
+
+	l.Trace(context.Background(), time.Now().Add(-elapsed), func() (string, int64) {
+		return "SELECT 1", 1
+	}, err)
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestLogger_Trace(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   logger.LogLevel
+		elapsed time.Duration
+		err     error
+		want    string
+		logged  string
+	}{
+		{name: "error", level: logger.Error, err: errors.New("boom"), want: "Error en la consulta SQL", logged: "ERROR"},
+		{name: "registro no encontrado", level: logger.Info, err: gorm.ErrRecordNotFound, want: "Consulta SQL", logged: "DEBUG"},
+		{name: "consulta lenta", level: logger.Warn, elapsed: time.Second, want: "Consulta SQL lenta", logged: "WARN"},
+		{name: "consulta lenta con nivel error", level: logger.Error, elapsed: time.Second},
+		{name: "consulta rápida con nivel warn", level: logger.Warn},
+		{name: "consulta rápida con nivel info", level: logger.Info, want: "Consulta SQL", logged: "DEBUG"},
+		{name: "silencioso", level: logger.Silent, err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := traceLines(t, tt.level, tt.elapsed, tt.err)
+			if tt.want == "" {
+				assert.Empty(t, lines)
+				return
+			}
+			if assert.Len(t, lines, 1) {
+				assert.Equal(t, tt.want, lines[0]["msg"])
+				assert.Equal(t, tt.logged, lines[0]["level"])
+				assert.Equal(t, "SELECT 1", lines[0]["sql"])
+			}
+		})
+	}
+}
+
+// TestLogger_LogsParameterizedSQL verifica que las consultas se registren sin los valores de sus parámetros
+func TestLogger_LogsParameterizedSQL(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), logger.Info, 0)
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: l})
+	assert.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	type customer struct {
+		ID    uint
+		Email string
+	}
+	assert.NoError(t, db.AutoMigrate(&customer{}))
+	buf.Reset()
+
+	assert.NoError(t, db.Create(&customer{Email: "ana@example.com"}).Error)
+	assert.Error(t, db.Exec("INSERT INTO missing_table (email) VALUES (?)", "luis@example.com").Error)
+
+	logged := buf.String()
+	assert.Contains(t, logged, "INSERT INTO `customers` (`email`) VALUES (?)")
+	assert.Contains(t, logged, "INSERT INTO missing_table (email) VALUES (?)")
+	assert.NotContains(t, logged, "@example.com")
+}
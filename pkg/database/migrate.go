@@ -0,0 +1,440 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles contiene las migraciones del esquema que se distribuyen con el binario
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationLockTimeout es el tiempo que Up y Down esperan a que otro proceso termine de migrar
+const MigrationLockTimeout = time.Minute
+
+// migrationLockName es el nombre del bloqueo de MySQL que comparten todos los procesos que migran
+const migrationLockName = "schema_migrations"
+
+// migrationFileName reconoce los archivos <versión>_<nombre>.up.sql y <versión>_<nombre>.down.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration es una versión del esquema con las sentencias para aplicarla y para revertirla
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus indica si una migración está aplicada y desde cuándo
+type MigrationStatus struct {
+	Migration
+	// AppliedAt es nil si la migración está pendiente
+	AppliedAt *time.Time
+	// Dirty indica que la migración empezó a aplicarse o revertirse y no terminó
+	Dirty bool
+}
+
+// schemaMigration es la fila de una migración aplicada
+type schemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+	// Dirty se registra antes de ejecutar las sentencias y se borra al terminar, de modo que una
+	// migración que falla a mitad queda marcada
+	Dirty bool `gorm:"not null;default:false"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// tableSchema son las columnas e índices que una migración espera encontrar en una tabla que ya existe
+type tableSchema struct {
+	table   string
+	columns []string
+	indexes []string
+}
+
+// initialSchema es el esquema que crea 0001_initial_schema. Las bases creadas con versiones anteriores
+// de mysql-init/init.sql, previas a las variantes y las categorías, tienen products, orders y
+// order_items sin category_id, variant_id ni los índices de búsqueda.
+var initialSchema = []tableSchema{
+	{
+		table:   "categories",
+		columns: []string{"id", "name", "parent_id", "created_at", "updated_at"},
+		indexes: []string{"idx_categories_parent_id"},
+	},
+	{
+		table:   "products",
+		columns: []string{"id", "name", "price", "stock", "category_id", "created_at", "updated_at"},
+		indexes: []string{"idx_products_name", "idx_products_price", "idx_products_stock", "idx_products_category_id"},
+	},
+	{
+		table:   "product_variants",
+		columns: []string{"id", "product_id", "sku", "attributes", "price", "stock", "created_at", "updated_at"},
+		indexes: []string{"idx_product_variants_sku", "idx_product_variants_product_id", "idx_product_variants_stock"},
+	},
+	{
+		table:   "orders",
+		columns: []string{"id", "customer_name", "total_amount", "created_at", "updated_at"},
+	},
+	{
+		table:   "order_items",
+		columns: []string{"id", "order_id", "product_id", "variant_id", "quantity", "subtotal", "created_at", "updated_at"},
+	},
+	{
+		table: "idempotency_keys",
+		columns: []string{"idempotency_key", "status", "fingerprint", "lock_token", "locked_until", "order_id",
+			"status_code", "headers", "response", "expires_at", "created_at", "updated_at"},
+		indexes: []string{"idx_idempotency_keys_expires_at"},
+	},
+}
+
+// Migrator aplica y revierte las migraciones versionadas del esquema y registra las aplicadas en la
+// tabla schema_migrations. En MySQL, Up y Down toman un bloqueo con GET_LOCK para que dos procesos
+// no migren a la vez. MySQL confirma cada sentencia DDL por separado, por lo que una migración que
+// falla a mitad queda marcada como dirty y debe corregirse a mano, eliminando después su fila de
+// schema_migrations, antes de volver a migrar.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	// existingTables es, por versión, el esquema que deben tener las tablas que ya existen para que la
+	// migración pueda aplicarse sobre ellas
+	existingTables map[uint][]tableSchema
+}
+
+// NewMigrator crea un Migrator con las migraciones embebidas en el binario
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := newMigrator(db, files)
+	if err != nil {
+		return nil, err
+	}
+	migrator.existingTables = map[uint][]tableSchema{1: initialSchema}
+	return migrator, nil
+}
+
+// newMigrator crea un Migrator con las migraciones de files
+func newMigrator(db *gorm.DB, files fs.FS) (*Migrator, error) {
+	migrations, err := loadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations lee las migraciones de files ordenadas por versión. Cada versión debe tener su
+// archivo up y su archivo down.
+func loadMigrations(files fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, name := range names {
+		match := migrationFileName.FindStringSubmatch(path.Base(name))
+		if match == nil {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", name)
+		}
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("versión de migración inválida: %s", name)
+		}
+		content, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("la migración %d tiene dos nombres: %s y %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("la migración %04d_%s debe tener sus archivos up y down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Status devuelve todas las migraciones conocidas, en orden, indicando cuáles están aplicadas
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = MigrationStatus{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			statuses[i].AppliedAt = &appliedAt
+			statuses[i].Dirty = row.Dirty
+		}
+	}
+	return statuses, nil
+}
+
+// Pending devuelve las migraciones que faltan aplicar, en orden. Devuelve un error si una migración
+// quedó a medias.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDirty(applied); err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up aplica en orden las migraciones pendientes y devuelve las aplicadas. Se detiene en la primera
+// que falla.
+func (m *Migrator) Up(ctx context.Context) (done []Migration, err error) {
+	err = m.withLock(ctx, func() error {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+		// Las pendientes se leen con el bloqueo tomado por si otro proceso acaba de migrar
+		pending, err := m.Pending(ctx)
+		if err != nil {
+			return err
+		}
+
+		db := m.db.WithContext(ctx)
+		for _, migration := range pending {
+			if err := checkExistingTables(db, m.existingTables[migration.Version]); err != nil {
+				return fmt.Errorf("no se puede aplicar la migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			row := schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now(), Dirty: true}
+			if err := db.Create(&row).Error; err != nil {
+				return fmt.Errorf("error al registrar la migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if err := m.exec(ctx, migration.Up); err != nil {
+				return fmt.Errorf("error al aplicar la migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if err := db.Model(&row).Updates(map[string]interface{}{"dirty": false, "applied_at": time.Now()}).Error; err != nil {
+				return fmt.Errorf("error al registrar la migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down revierte las últimas steps migraciones aplicadas, de la más reciente a la más antigua, y
+// devuelve las revertidas
+func (m *Migrator) Down(ctx context.Context, steps int) (done []Migration, err error) {
+	err = m.withLock(ctx, func() error {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+		known := make(map[uint]Migration, len(m.migrations))
+		for _, migration := range m.migrations {
+			known[migration.Version] = migration
+		}
+
+		versions := make([]uint, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		db := m.db.WithContext(ctx)
+		for _, version := range versions {
+			if len(done) == steps {
+				break
+			}
+			migration, ok := known[version]
+			if !ok {
+				return fmt.Errorf("la migración %04d_%s está aplicada pero este binario no la conoce", version, applied[version].Name)
+			}
+			if err := db.Model(&schemaMigration{Version: version}).Update("dirty", true).Error; err != nil {
+				return fmt.Errorf("error al registrar la reversión de la migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if err := m.exec(ctx, migration.Down); err != nil {
+				return fmt.Errorf("error al revertir la migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if err := db.Delete(&schemaMigration{}, version).Error; err != nil {
+				return fmt.Errorf("error al registrar la reversión de la migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// withLock ejecuta fn con el bloqueo de migraciones tomado. GET_LOCK pertenece a la sesión, por lo
+// que se toma y se libera en una conexión reservada para ello. Las demás bases, como SQLite en los
+// tests, no admiten varios procesos migrando y se ejecutan sin bloqueo.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if m.db.Dialector.Name() != "mysql" {
+		return fn()
+	}
+
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error al obtener una conexión para el bloqueo de migraciones: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(MigrationLockTimeout.Seconds())).Scan(&acquired); err != nil {
+		return fmt.Errorf("error al tomar el bloqueo de migraciones: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("otro proceso está aplicando migraciones; no se obtuvo el bloqueo en %s", MigrationLockTimeout)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT RELEASE_LOCK(?)", migrationLockName)
+
+	return fn()
+}
+
+// ensureTable crea la tabla schema_migrations si no existe o le agrega las columnas que le falten
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	if err := m.db.WithContext(ctx).AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("error al crear la tabla schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// checkExistingTables devuelve un error con las columnas e índices que les faltan a las tablas de
+// tables que ya existen. Las tablas que no existen las crea la migración.
+func checkExistingTables(db *gorm.DB, tables []tableSchema) error {
+	var missing []string
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table.table) {
+			continue
+		}
+		for _, column := range table.columns {
+			if !db.Migrator().HasColumn(table.table, column) {
+				missing = append(missing, "columna "+table.table+"."+column)
+			}
+		}
+		for _, index := range table.indexes {
+			if !db.Migrator().HasIndex(table.table, index) {
+				missing = append(missing, "índice "+table.table+"."+index)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("la base se creó con una versión anterior del esquema y le faltan: %s; agréguelos a mano antes de migrar", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkDirty devuelve un error si alguna migración quedó a medias
+func checkDirty(applied map[uint]schemaMigration) error {
+	for _, row := range applied {
+		if row.Dirty {
+			return fmt.Errorf("la migración %04d_%s quedó a medias; corrija el esquema a mano y elimine su fila de schema_migrations", row.Version, row.Name)
+		}
+	}
+	return nil
+}
+
+// applied devuelve las migraciones aplicadas por versión. No modifica el esquema: si la tabla
+// schema_migrations no existe, ninguna migración está aplicada.
+func (m *Migrator) applied(ctx context.Context) (map[uint]schemaMigration, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return map[uint]schemaMigration{}, nil
+	}
+
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("error al leer las migraciones aplicadas: %w", err)
+	}
+	applied := make(map[uint]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// exec ejecuta las sentencias de un script una por una, porque el driver de MySQL no acepta varias
+// sentencias en una sola llamada
+func (m *Migrator) exec(ctx context.Context, script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := m.db.WithContext(ctx).Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements separa un script en sentencias. Cada sentencia termina con ";" al final de una
+// línea; las líneas vacías y los comentarios "--" se descartan.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// testMigrations son migraciones compatibles con SQLite para probar el Migrator sin MySQL
+var testMigrations = fstest.MapFS{
+	"0001_create_items.up.sql": {Data: []byte(`
+-- Tabla de prueba
+CREATE TABLE items (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
+CREATE INDEX idx_items_name ON items (name);
+`)},
+	"0001_create_items.down.sql":   {Data: []byte("DROP TABLE items;\n")},
+	"0002_add_stock.up.sql":        {Data: []byte("ALTER TABLE items ADD COLUMN stock INTEGER NOT NULL DEFAULT 0;\n")},
+	"0002_add_stock.down.sql":      {Data: []byte("ALTER TABLE items DROP COLUMN stock;\n")},
+	"0003_create_orders.up.sql":    {Data: []byte("CREATE TABLE orders (id INTEGER PRIMARY KEY);\n")},
+	"0003_create_orders.down.sql":  {Data: []byte("DROP TABLE orders;\n")},
+	"README.md":                    {Data: []byte("no es una migración")},
+	"subdir/0004_ignored.up.sql":   {Data: []byte("CREATE TABLE ignored (id INTEGER);")},
+	"subdir/0004_ignored.down.sql": {Data: []byte("DROP TABLE ignored;")},
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	migrator, err := newMigrator(db, testMigrations)
+	assert.NoError(t, err)
+	return migrator, db
+}
+
+func versions(migrations []Migration) []uint {
+	result := make([]uint, len(migrations))
+	for i, migration := range migrations {
+		result[i] = migration.Version
+	}
+	return result
+}
+
+func TestMigrator_UpAppliesPendingInOrder(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 3}, versions(pending))
+
+	done, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 3}, versions(done))
+	assert.True(t, db.Migrator().HasColumn("items", "stock"))
+	assert.True(t, db.Migrator().HasIndex("items", "idx_items_name"))
+	assert.True(t, db.Migrator().HasTable("orders"))
+
+	// Una segunda ejecución no tiene nada pendiente
+	done, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+	pending, err = migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestMigrator_DownRevertsLatest(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+	_, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+
+	done, err := migrator.Down(ctx, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3, 2}, versions(done))
+	assert.False(t, db.Migrator().HasTable("orders"))
+	assert.False(t, db.Migrator().HasColumn("items", "stock"))
+	assert.True(t, db.Migrator().HasTable("items"))
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 3) {
+		assert.NotNil(t, statuses[0].AppliedAt)
+		assert.Nil(t, statuses[1].AppliedAt)
+		assert.Nil(t, statuses[2].AppliedAt)
+	}
+
+	// Revertir más pasos que migraciones aplicadas revierte solo las que hay
+	done, err = migrator.Down(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1}, versions(done))
+	assert.False(t, db.Migrator().HasTable("items"))
+}
+
+func TestMigrator_UpStopsAtFailedMigration(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	migrator, err := newMigrator(db, fstest.MapFS{
+		"0001_create_items.up.sql":   {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY);")},
+		"0001_create_items.down.sql": {Data: []byte("DROP TABLE items;")},
+		"0002_broken.up.sql":         {Data: []byte("ALTER TABLE missing ADD COLUMN stock INTEGER;")},
+		"0002_broken.down.sql":       {Data: []byte("SELECT 1;")},
+	})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	done, err := migrator.Up(ctx)
+
+	assert.ErrorContains(t, err, "0002_broken")
+	assert.Equal(t, []uint{1}, versions(done))
+
+	// La migración que falló queda marcada y bloquea las siguientes hasta corregirla a mano
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 2) {
+		assert.False(t, statuses[0].Dirty)
+		assert.True(t, statuses[1].Dirty)
+	}
+	_, err = migrator.Pending(ctx)
+	assert.ErrorContains(t, err, "0002_broken")
+	_, err = migrator.Up(ctx)
+	assert.ErrorContains(t, err, "0002_broken")
+	_, err = migrator.Down(ctx, 1)
+	assert.ErrorContains(t, err, "0002_broken")
+
+	// Una vez corregida, se elimina su fila y la migración vuelve a estar pendiente
+	assert.NoError(t, db.Exec("DELETE FROM schema_migrations WHERE version = 2").Error)
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{2}, versions(pending))
+}
+
+// TestMigrator_ReadsDoNotCreateTable verifica que Status y Pending no modifiquen el esquema
+func TestMigrator_ReadsDoNotCreateTable(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 3)
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 3}, versions(pending))
+
+	assert.False(t, db.Migrator().HasTable("schema_migrations"))
+}
+
+func TestLoadMigrations_RejectsInvalidFiles(t *testing.T) {
+	for name, files := range map[string]fstest.MapFS{
+		"sin down": {"0001_items.up.sql": {Data: []byte("CREATE TABLE items (id INTEGER);")}},
+		"nombre":   {"items.up.sql": {Data: []byte("CREATE TABLE items (id INTEGER);")}},
+		"versión":  {"0000_items.up.sql": {Data: []byte("x")}, "0000_items.down.sql": {Data: []byte("x")}},
+		"dos nombres": {
+			"0001_items.up.sql":      {Data: []byte("CREATE TABLE items (id INTEGER);")},
+			"0001_products.down.sql": {Data: []byte("DROP TABLE items;")},
+		},
+	} {
+		_, err := loadMigrations(files)
+		assert.Error(t, err, name)
+	}
+}
+
+// TestEmbeddedMigrations verifica que las migraciones del binario sean válidas y consecutivas
+func TestEmbeddedMigrations(t *testing.T) {
+	migrator, err := NewMigrator(nil)
+
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, migrator.migrations) {
+		for i, migration := range migrator.migrations {
+			assert.Equal(t, uint(i+1), migration.Version, migration.Name)
+			assert.NotEmpty(t, splitStatements(migration.Up), migration.Name)
+			assert.NotEmpty(t, splitStatements(migration.Down), migration.Name)
+		}
+	}
+}
+
+// TestMigrator_UpRejectsOutdatedExistingTables verifica que una tabla creada con un esquema anterior no
+// se dé por adoptada: la migración no se aplica ni se registra
+func TestMigrator_UpRejectsOutdatedExistingTables(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	migrator.existingTables = map[uint][]tableSchema{
+		1: {{table: "items", columns: []string{"id", "name"}, indexes: []string{"idx_items_name"}}},
+	}
+	ctx := context.Background()
+	assert.NoError(t, db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY)").Error)
+
+	done, err := migrator.Up(ctx)
+
+	assert.Empty(t, done)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "columna items.name")
+		assert.Contains(t, err.Error(), "índice items.idx_items_name")
+	}
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 3}, versions(pending))
+}
+
+// TestInitialSchema_MatchesMigration verifica que initialSchema liste las columnas e índices que crea
+// 0001_initial_schema
+func TestInitialSchema_MatchesMigration(t *testing.T) {
+	migrator, err := NewMigrator(nil)
+	assert.NoError(t, err)
+	initial := migrator.migrations[0].Up
+
+	for _, table := range initialSchema {
+		assert.Contains(t, initial, "CREATE TABLE IF NOT EXISTS "+table.table+" (", table.table)
+		for _, column := range table.columns {
+			assert.Regexp(t, `\n    `+column+` `, initial, table.table+"."+column)
+		}
+		for _, index := range table.indexes {
+			assert.Contains(t, initial, " "+index+" (", table.table+"."+index)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements(`
+-- Comentario
+CREATE TABLE items (
+    id INT,
+    name VARCHAR(255) -- el nombre
+);
+
+DROP TABLE orders;
+SELECT 1`)
+
+	assert.Equal(t, []string{
+		"CREATE TABLE items (\n    id INT,\n    name VARCHAR(255) -- el nombre\n)",
+		"DROP TABLE orders",
+		"SELECT 1",
+	}, statements)
+}
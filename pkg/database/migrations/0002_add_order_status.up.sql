@@ -0,0 +1,2 @@
+-- Estado de las órdenes; las órdenes existentes quedan pendientes
+ALTER TABLE orders ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending';
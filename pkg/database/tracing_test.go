@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"gorm.io/gorm"
+)
+
+type tracedItem struct {
+	ID   uint
+	Name string
+}
+
+func TestTracingPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(TracingPlugin{}))
+
+	// Las consultas fuera de una traza no crean spans
+	assert.NoError(t, db.AutoMigrate(&tracedItem{}))
+	assert.NoError(t, db.Create(&tracedItem{Name: "fuera"}).Error)
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "solicitud")
+	assert.NoError(t, db.WithContext(ctx).Create(&tracedItem{Name: "secreto"}).Error)
+	var item tracedItem
+	assert.ErrorIs(t, db.WithContext(ctx).First(&item, 99).Error, gorm.ErrRecordNotFound)
+	assert.Error(t, db.WithContext(ctx).Exec("SELECT * FROM no_existe").Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 4) {
+		create := spans[0]
+		assert.Equal(t, "gorm.create", create.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), create.Parent().SpanID())
+		assert.Contains(t, create.Attributes(), semconv.DBCollectionName("traced_items"))
+		assert.Contains(t, create.Attributes(), semconv.DBSystemSqlite)
+		// El SQL no incluye los valores de los parámetros
+		for _, attr := range create.Attributes() {
+			assert.NotContains(t, attr.Value.Emit(), "secreto")
+		}
+
+		// Un registro inexistente no es un error
+		assert.Equal(t, "gorm.query", spans[1].Name())
+		assert.Equal(t, codes.Unset, spans[1].Status().Code)
+
+		assert.Equal(t, "gorm.raw", spans[2].Name())
+		assert.Equal(t, codes.Error, spans[2].Status().Code)
+	}
+}
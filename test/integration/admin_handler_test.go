@@ -0,0 +1,91 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"order_management/internal/dtos"
+	"order_management/internal/handlers"
+	"order_management/internal/idempotency"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/internal/repositories"
+	"order_management/internal/services"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-resty/resty/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const testAdminToken = "admin-secret"
+
+// setupIdempotentProductRoutes registra los productos y los endpoints de administración sobre el mismo store
+func setupIdempotentProductRoutes(e *echo.Echo, db *gorm.DB, redisClient *redis.Client) {
+	productRepo := repositories.NewProductRepository(db)
+	productService := services.NewProductService(productRepo, db, nil)
+	store := idempotency.NewRedisStore(redisClient, idempotency.DefaultTTL)
+
+	handlers.NewProductHandler(e.Group("/api"), productService, store)
+	handlers.NewAdminHandler(e, store, testAdminToken)
+}
+
+// TestUpdateStockIdempotentAndPurgeKey: un ajuste de stock reintentado con la misma Idempotency-Key se
+// aplica una sola vez y la clave puede inspeccionarse y eliminarse desde la administración
+func TestUpdateStockIdempotentAndPurgeKey(t *testing.T) {
+	SetupTestServer(t, setupIdempotentProductRoutes)
+	defer TearDown()
+
+	product := models.Product{Name: "Producto de prueba", Price: 100.0, Stock: 10}
+	assert.NoError(t, db.Create(&product).Error)
+
+	client := resty.New()
+	updateStock := func() *resty.Response {
+		resp, err := client.R().
+			SetHeader("Content-Type", "application/json").
+			SetHeader("Idempotency-Key", "stock-1").
+			SetBody(dtos.UpdateStocRequestkDTO{Stock: 5}).
+			Put(fmt.Sprintf("%s/api/products/%d/stock", server.URL, product.ID))
+		assert.NoError(t, err)
+		return resp
+	}
+
+	first := updateStock()
+	retry := updateStock()
+	assert.Equal(t, http.StatusOK, first.StatusCode())
+	assert.Equal(t, http.StatusOK, retry.StatusCode())
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+
+	keyURL := server.URL + handlers.AdminPrefix + "/idempotency-keys/anonymous/stock-1"
+
+	// Sin el token de administración no se puede consultar la clave
+	resp, err := client.R().SetHeader("Authorization", "Bearer otro-token").Get(keyURL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+	resp, err = client.R().SetAuthToken(testAdminToken).Get(keyURL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+
+	var keyResponse dtos.IdempotencyKeyResponseDTO
+	assert.NoError(t, json.Unmarshal(resp.Body(), &keyResponse))
+	assert.Equal(t, "anonymous", keyResponse.Scope)
+	assert.Equal(t, "stock-1", keyResponse.Key)
+	assert.Equal(t, ports.IdempotencyCompleted, keyResponse.Status)
+	assert.Equal(t, http.StatusOK, keyResponse.StatusCode)
+	assert.Equal(t, string(first.Body()), keyResponse.Response)
+	assert.NotNil(t, keyResponse.ExpiresAt)
+
+	resp, err = client.R().SetAuthToken(testAdminToken).Delete(keyURL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	resp, err = client.R().SetAuthToken(testAdminToken).Get(keyURL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	// Con la clave eliminada el reintento vuelve a ejecutar la operación
+	assert.Empty(t, updateStock().Header().Get("Idempotent-Replayed"))
+}
@@ -0,0 +1,82 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"order_management/internal/dtos"
+	"order_management/internal/handlers"
+	"order_management/internal/ports"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-resty/resty/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const testHealthTimeout = 500 * time.Millisecond
+
+var healthHandler *handlers.HealthHandler
+
+// setupHealthRoutes verifica MySQL y Redis como lo hace cmd/main.go
+func setupHealthRoutes(e *echo.Echo, db *gorm.DB, redisClient *redis.Client) {
+	sqlDB, _ := db.DB()
+	healthHandler = handlers.NewHealthHandler(e, map[string]ports.HealthChecker{
+		"mysql": ports.HealthCheckFunc(sqlDB.PingContext),
+		"redis": ports.HealthCheckFunc(func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}),
+	}, testHealthTimeout)
+}
+
+func getHealth(t *testing.T, path string) (int, dtos.HealthResponseDTO) {
+	resp, err := resty.New().R().Get(server.URL + path)
+	assert.NoError(t, err)
+
+	var health dtos.HealthResponseDTO
+	assert.NoError(t, json.Unmarshal(resp.Body(), &health))
+	return resp.StatusCode(), health
+}
+
+// TestHealthAndReadiness: con MySQL y Redis disponibles ambos endpoints responden 200, y durante el
+// apagado /readyz responde 503 mientras /healthz sigue respondiendo
+func TestHealthAndReadiness(t *testing.T) {
+	SetupTestServer(t, setupHealthRoutes)
+	defer TearDown()
+
+	for _, path := range []string{handlers.HealthPath, handlers.ReadyPath} {
+		status, health := getHealth(t, path)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, dtos.HealthStatusOK, health.Status)
+		assert.Equal(t, map[string]string{"mysql": "ok", "redis": "ok"}, health.Checks)
+	}
+
+	healthHandler.Drain()
+
+	status, health := getHealth(t, handlers.ReadyPath)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, dtos.HealthStatusDraining, health.Status)
+
+	status, _ = getHealth(t, handlers.HealthPath)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+// TestHealthDependencyDown: si Redis no responde, los endpoints responden 503 dentro del timeout
+func TestHealthDependencyDown(t *testing.T) {
+	SetupTestServer(t, setupHealthRoutes)
+	defer TearDown()
+
+	redisClient.Close()
+
+	start := time.Now()
+	status, health := getHealth(t, handlers.ReadyPath)
+
+	assert.Less(t, time.Since(start), 2*testHealthTimeout)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, dtos.HealthStatusUnavailable, health.Status)
+	assert.Equal(t, "ok", health.Checks["mysql"])
+	assert.Equal(t, dtos.HealthStatusUnavailable, health.Checks["redis"])
+}
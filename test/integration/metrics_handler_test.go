@@ -0,0 +1,95 @@
+package integration_test
+
+import (
+	"net/http"
+	"order_management/internal/dtos"
+	"order_management/internal/handlers"
+	"order_management/internal/idempotency"
+	"order_management/internal/metrics"
+	"order_management/internal/middlewares"
+	"order_management/internal/models"
+	"order_management/internal/repositories"
+	"order_management/internal/services"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-resty/resty/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupMetricsRoutes instrumenta las órdenes, la idempotencia y los pools como lo hace cmd/main.go
+func setupMetricsRoutes(e *echo.Echo, db *gorm.DB, redisClient *redis.Client) {
+	appMetrics := metrics.New()
+	sqlDB, _ := db.DB()
+	appMetrics.RegisterDB(sqlDB)
+	appMetrics.RegisterRedis(redisClient)
+
+	e.Use(middlewares.MetricsMiddleware(appMetrics))
+
+	orderRepo := repositories.NewOrderRepository(db)
+	productRepo := repositories.NewProductRepository(db)
+	orderService := services.NewOrderService(orderRepo, productRepo, db, nil, appMetrics)
+	store := idempotency.NewInstrumentedStore(idempotency.NewRedisStore(redisClient, idempotency.DefaultTTL), appMetrics)
+
+	handlers.NewOrderHandler(e.Group("/api"), orderService, store)
+	handlers.NewMetricsHandler(e, appMetrics.Handler())
+}
+
+// TestMetrics: /metrics publica la duración de las solicitudes, las órdenes creadas y fallidas, el
+// uso de las claves de idempotencia y los pools de conexiones
+func TestMetrics(t *testing.T) {
+	SetupTestServer(t, setupMetricsRoutes)
+	defer TearDown()
+
+	product := models.Product{Name: "Producto de prueba", Price: 100.0, Stock: 3}
+	assert.NoError(t, db.Create(&product).Error)
+
+	client := resty.New()
+	postOrder := func(quantity int) *resty.Response {
+		resp, err := client.R().
+			SetHeader("Content-Type", "application/json").
+			SetHeader("Idempotency-Key", "metrics-key").
+			SetBody(dtos.OrderRequestDTO{
+				CustomerName: "Customer 1",
+				Items:        []dtos.OrderItemRequestDTO{{ProductID: product.ID, Quantity: quantity}},
+			}).
+			Post(server.URL + "/api/orders")
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Orden creada, respuesta repetida, clave reutilizada con otro contenido y orden sin stock
+	assert.Equal(t, http.StatusCreated, postOrder(2).StatusCode())
+	assert.Equal(t, http.StatusCreated, postOrder(2).StatusCode())
+	assert.Equal(t, http.StatusUnprocessableEntity, postOrder(5).StatusCode())
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(dtos.OrderRequestDTO{
+			CustomerName: "Customer 1",
+			Items:        []dtos.OrderItemRequestDTO{{ProductID: product.ID, Quantity: 5}},
+		}).
+		Post(server.URL + "/api/orders")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode())
+
+	resp, err = client.R().Get(server.URL + handlers.MetricsPath)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+
+	body := resp.String()
+	assert.Contains(t, body, `order_management_http_request_duration_seconds_count{method="POST",route="/api/orders",status="201"} 2`)
+	assert.Contains(t, body, `order_management_http_request_duration_seconds_count{method="POST",route="/api/orders",status="422"} 1`)
+	assert.Contains(t, body, `order_management_http_request_duration_seconds_count{method="POST",route="/api/orders",status="409"} 1`)
+	assert.Contains(t, body, "order_management_orders_created_total 1")
+	assert.Contains(t, body, `order_management_order_failures_total{reason="stock"} 1`)
+	assert.Contains(t, body, "order_management_order_value_sum 200")
+	assert.Contains(t, body, "order_management_idempotency_misses_total 1")
+	assert.Contains(t, body, "order_management_idempotency_hits_total 2")
+	assert.Contains(t, body, "order_management_idempotency_replays_total 1")
+	assert.Contains(t, body, `order_management_idempotency_conflicts_total{reason="fingerprint_mismatch"} 1`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="mysql"}`)
+	assert.Contains(t, body, "order_management_redis_pool_connections")
+}
@@ -0,0 +1,100 @@
+package integration_test
+
+import (
+	"context"
+	"order_management/internal/models"
+	"order_management/pkg/database"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// schemaModels son los modelos que se guardan en MySQL
+var schemaModels = []interface{}{
+	&models.Category{}, &models.Product{}, &models.ProductVariant{}, &models.Order{}, &models.OrderItem{}, &models.IdempotencyKey{},
+}
+
+// TestMigrations_MatchModels verifica que las migraciones creen todas las tablas y columnas de los
+// modelos, para que el esquema de la base y el que espera GORM no se separen
+func TestMigrations_MatchModels(t *testing.T) {
+	db := SetupTestDatabase(t)
+	defer TearDown()
+
+	for _, model := range schemaModels {
+		stmt := &gorm.Statement{DB: db}
+		assert.NoError(t, stmt.Parse(model))
+
+		assert.True(t, db.Migrator().HasTable(model), stmt.Schema.Table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(model, field.DBName), "%s.%s", stmt.Schema.Table, field.DBName)
+		}
+	}
+}
+
+// TestMigrations_DownAndUp verifica que todas las migraciones puedan revertirse y volver a aplicarse
+func TestMigrations_DownAndUp(t *testing.T) {
+	db := SetupTestDatabase(t)
+	defer TearDown()
+	ctx := context.Background()
+
+	migrator, err := database.NewMigrator(db)
+	assert.NoError(t, err)
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, status.Name)
+	}
+
+	reverted, err := migrator.Down(ctx, len(statuses))
+	assert.NoError(t, err)
+	assert.Len(t, reverted, len(statuses))
+	for _, model := range schemaModels {
+		assert.False(t, db.Migrator().HasTable(model))
+	}
+
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(statuses))
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+// TestMigrations_ConcurrentUp verifica que dos procesos que migran a la vez no apliquen dos veces la
+// misma migración: el bloqueo hace que el segundo espere y no encuentre nada pendiente
+func TestMigrations_ConcurrentUp(t *testing.T) {
+	db := SetupTestDatabase(t)
+	defer TearDown()
+	ctx := context.Background()
+
+	migrator, err := database.NewMigrator(db)
+	assert.NoError(t, err)
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	_, err = migrator.Down(ctx, len(statuses))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	applied := make([][]database.Migration, 2)
+	errs := make([]error, 2)
+	for i := range applied {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			applied[i], errs[i] = migrator.Up(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Equal(t, len(statuses), len(applied[0])+len(applied[1]))
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
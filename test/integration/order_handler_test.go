@@ -2,9 +2,11 @@ package integration_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"order_management/internal/dtos"
 	"order_management/internal/handlers"
+	"order_management/internal/idempotency"
 	"order_management/internal/models"
 	"order_management/internal/repositories"
 	"order_management/internal/services"
@@ -21,10 +23,10 @@ import (
 func setupOrderRoutes(e *echo.Echo, db *gorm.DB, redisClient *redis.Client) {
 	orderRepo := repositories.NewOrderRepository(db)
 	productRepo := repositories.NewProductRepository(db)
-	orderService := services.NewOrderService(orderRepo, productRepo, db)
+	orderService := services.NewOrderService(orderRepo, productRepo, db, nil, nil)
 
 	apiGroup := e.Group("/api")
-	handlers.NewOrderHandler(apiGroup, orderService, redisClient)
+	handlers.NewOrderHandler(apiGroup, orderService, idempotency.NewRedisStore(redisClient, idempotency.DefaultTTL))
 }
 
 // TestCreateOrderSuccess: Creación exitosa de una orden
@@ -57,13 +59,20 @@ func TestCreateOrderSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusCreated, resp.StatusCode())
 
-	var responseData map[string]string
-	err = json.Unmarshal(resp.Body(), &responseData)
+	// La respuesta contiene la orden creada y la URL para consultarla
+	var orderResponse dtos.OrderResponseDTO
+	err = json.Unmarshal(resp.Body(), &orderResponse)
 	assert.NoError(t, err)
-	assert.Equal(t, "Orden creada correctamente", responseData["message"])
+	assert.NotZero(t, orderResponse.ID)
+	assert.Equal(t, fmt.Sprintf("/api/orders/%d", orderResponse.ID), resp.Header().Get("Location"))
+	assert.Equal(t, "Customer 1", orderResponse.CustomerName)
+	assert.Equal(t, 200.0, orderResponse.TotalAmount)
+	assert.Len(t, orderResponse.Items, 1)
+	assert.Equal(t, "Producto de prueba", orderResponse.Items[0].ProductName)
+	assert.Equal(t, 200.0, orderResponse.Items[0].Subtotal)
 }
 
-// TestCreateOrderInvalidPayload: Creación de orden fallida por payload inválido
+// TestCreateOrderInvalidPayload: Creación de orden fallida por payload inválido, con un error traducido por campo
 func TestCreateOrderInvalidPayload(t *testing.T) {
 	SetupTestServer(t, setupOrderRoutes)
 	defer TearDown()
@@ -71,7 +80,7 @@ func TestCreateOrderInvalidPayload(t *testing.T) {
 	client := resty.New()
 	invalidOrderRequest := map[string]interface{}{
 		"items": []map[string]interface{}{
-			{"product_id": 1, "quantity": 2}, // Aquí falta "customer_name"
+			{"product_id": 1, "quantity": 0}, // Aquí falta "customer_name" y la cantidad no es válida
 		},
 	}
 
@@ -82,11 +91,96 @@ func TestCreateOrderInvalidPayload(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	assert.Equal(t, "es", resp.Header().Get("Content-Language"))
 
-	var responseData map[string]string
+	var responseData dtos.ErrorResponseDTO
+	err = json.Unmarshal(resp.Body(), &responseData)
+	assert.NoError(t, err)
+	assert.Equal(t, "VALIDATION_FAILED", responseData.Code)
+	assert.Equal(t, []dtos.FieldErrorDTO{
+		{Field: "customer_name", Rule: "required", Message: "customer_name es un campo requerido"},
+		{Field: "items[0].quantity", Rule: "required", Message: "quantity es un campo requerido"},
+	}, responseData.Fields)
+
+	// Los mismos errores en inglés
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept-Language", "en-US,en;q=0.9").
+		SetBody(invalidOrderRequest).
+		Post(server.URL + "/api/orders")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "en", resp.Header().Get("Content-Language"))
+
+	responseData = dtos.ErrorResponseDTO{}
+	err = json.Unmarshal(resp.Body(), &responseData)
+	assert.NoError(t, err)
+	assert.Equal(t, "the request contains invalid fields", responseData.Error)
+	assert.Equal(t, "customer_name is a required field", responseData.Fields[0].Message)
+}
+
+// TestCreateOrderInsufficientStock: Creación de orden rechazada por falta de stock
+func TestCreateOrderInsufficientStock(t *testing.T) {
+	SetupTestServer(t, setupOrderRoutes)
+	defer TearDown()
+
+	// Insertar un producto con poco stock
+	product := models.Product{
+		Name:  "Producto de prueba",
+		Price: 100.0,
+		Stock: 1,
+	}
+	err := db.Create(&product).Error
+	assert.NoError(t, err)
+
+	client := resty.New()
+	orderRequest := dtos.OrderRequestDTO{
+		CustomerName: "Customer 1",
+		Items: []dtos.OrderItemRequestDTO{
+			{ProductID: product.ID, Quantity: 3},
+		},
+	}
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(orderRequest).
+		Post(server.URL + "/api/orders")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode())
+
+	var responseData dtos.ErrorResponseDTO
+	err = json.Unmarshal(resp.Body(), &responseData)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSUFFICIENT_STOCK", responseData.Code)
+	assert.Equal(t, float64(1), responseData.Details["available"])
+}
+
+// TestCreateOrderProductNotFound: Creación de orden con un producto inexistente
+func TestCreateOrderProductNotFound(t *testing.T) {
+	SetupTestServer(t, setupOrderRoutes)
+	defer TearDown()
+
+	client := resty.New()
+	orderRequest := dtos.OrderRequestDTO{
+		CustomerName: "Customer 1",
+		Items: []dtos.OrderItemRequestDTO{
+			{ProductID: 999, Quantity: 1},
+		},
+	}
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(orderRequest).
+		Post(server.URL + "/api/orders")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	var responseData dtos.ErrorResponseDTO
 	err = json.Unmarshal(resp.Body(), &responseData)
 	assert.NoError(t, err)
-	assert.Equal(t, "Key: 'OrderRequestDTO.CustomerName' Error:Field validation for 'CustomerName' failed on the 'required' tag", responseData["error"])
+	assert.Equal(t, "PRODUCT_NOT_FOUND", responseData.Code)
 }
 
 // TestGetOrderByIdSuccess: Obtener una orden por ID
@@ -134,6 +228,50 @@ func TestGetOrderByIdSuccess(t *testing.T) {
 	assert.Equal(t, 200.0, orderResponse.TotalAmount)
 }
 
+// TestUpdateOrderStatus: La orden se crea pendiente y su estado avanza de a uno
+func TestUpdateOrderStatus(t *testing.T) {
+	SetupTestServer(t, setupOrderRoutes)
+	defer TearDown()
+
+	product := models.Product{Name: "Producto de prueba", Price: 100.0, Stock: 10}
+	assert.NoError(t, db.Create(&product).Error)
+
+	client := resty.New()
+	createResp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(dtos.OrderRequestDTO{CustomerName: "Customer 1", Items: []dtos.OrderItemRequestDTO{{ProductID: product.ID, Quantity: 1}}}).
+		Post(server.URL + "/api/orders")
+	assert.NoError(t, err)
+
+	var created dtos.OrderResponseDTO
+	assert.NoError(t, json.Unmarshal(createResp.Body(), &created))
+	assert.Equal(t, models.OrderStatusPending, created.Status)
+
+	statusURL := fmt.Sprintf("%s/api/orders/%d/status", server.URL, created.ID)
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(dtos.UpdateOrderStatusRequestDTO{Status: models.OrderStatusConfirmed}).
+		Put(statusURL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+
+	var updated dtos.OrderResponseDTO
+	assert.NoError(t, json.Unmarshal(resp.Body(), &updated))
+	assert.Equal(t, models.OrderStatusConfirmed, updated.Status)
+
+	// Una orden confirmada no puede entregarse sin haberse enviado
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(dtos.UpdateOrderStatusRequestDTO{Status: models.OrderStatusDelivered}).
+		Put(statusURL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode())
+
+	var order models.Order
+	assert.NoError(t, db.First(&order, created.ID).Error)
+	assert.Equal(t, models.OrderStatusConfirmed, order.Status)
+}
+
 // TestGetOrderByIdInvalidID: Obtener una orden con ID inválido
 func TestGetOrderByIdInvalidID(t *testing.T) {
 	SetupTestServer(t, setupOrderRoutes)
@@ -166,8 +304,259 @@ func TestGetOrderByIdNotFound(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
 
-	var responseData map[string]string
+	var responseData dtos.ErrorResponseDTO
 	err = json.Unmarshal(resp.Body(), &responseData)
 	assert.NoError(t, err)
-	assert.Equal(t, "Order not found", responseData["error"])
+	assert.Equal(t, "orden no encontrada", responseData.Error)
+	assert.Equal(t, "ORDER_NOT_FOUND", responseData.Code)
+
+	// El mensaje se traduce según Accept-Language; el código no cambia
+	resp, err = client.R().
+		SetHeader("Accept-Language", "en").
+		Get(server.URL + "/api/orders/999")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	responseData = dtos.ErrorResponseDTO{}
+	err = json.Unmarshal(resp.Body(), &responseData)
+	assert.NoError(t, err)
+	assert.Equal(t, "order not found", responseData.Error)
+	assert.Equal(t, "ORDER_NOT_FOUND", responseData.Code)
+}
+
+// setupVersionedOrderRoutes registra las órdenes en todas las versiones de la API
+func setupVersionedOrderRoutes(e *echo.Echo, db *gorm.DB, redisClient *redis.Client) {
+	orderRepo := repositories.NewOrderRepository(db)
+	productRepo := repositories.NewProductRepository(db)
+	orderService := services.NewOrderService(orderRepo, productRepo, db, nil, nil)
+
+	handlers.RegisterAPIRoutes(e, handlers.Services{Order: orderService}, idempotency.NewRedisStore(redisClient, idempotency.DefaultTTL))
+}
+
+// TestGetOrderByIdV2: La versión 2 devuelve el nuevo formato y la versión 1 se marca obsoleta
+func TestGetOrderByIdV2(t *testing.T) {
+	SetupTestServer(t, setupVersionedOrderRoutes)
+	defer TearDown()
+
+	product := models.Product{
+		Name:  "Producto de prueba",
+		Price: 100.0,
+		Stock: 10,
+	}
+	err := db.Create(&product).Error
+	assert.NoError(t, err)
+
+	client := resty.New()
+	orderRequest := dtos.OrderRequestDTO{
+		CustomerName: "Customer 1",
+		Items: []dtos.OrderItemRequestDTO{
+			{ProductID: product.ID, Quantity: 2},
+		},
+	}
+
+	createResp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(orderRequest).
+		Post(server.URL + "/api/v2/orders")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, createResp.StatusCode())
+	assert.Empty(t, createResp.Header().Get("Deprecation"))
+
+	var created dtos.OrderV2ResponseDTO
+	err = json.Unmarshal(createResp.Body(), &created)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("/api/v2/orders/%d", created.ID), createResp.Header().Get("Location"))
+
+	getResp, err := client.R().
+		Get(server.URL + createResp.Header().Get("Location"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, getResp.StatusCode())
+
+	var orderResponse dtos.OrderV2ResponseDTO
+	err = json.Unmarshal(getResp.Body(), &orderResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, "Customer 1", orderResponse.Customer.Name)
+	assert.Equal(t, 200.0, orderResponse.Total)
+	assert.Len(t, orderResponse.Items, 1)
+	assert.Equal(t, product.ID, orderResponse.Items[0].Product.ID)
+	assert.Equal(t, 100.0, orderResponse.Items[0].UnitPrice)
+
+	// La misma orden sigue disponible en la versión 1 con cabeceras de obsolescencia
+	v1Resp, err := client.R().
+		Get(fmt.Sprintf("%s/api/v1/orders/%d", server.URL, created.ID))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, v1Resp.StatusCode())
+	assert.Equal(t, "true", v1Resp.Header().Get("Deprecation"))
+	assert.Equal(t, fmt.Sprintf(`</api/v2/orders/%d>; rel="successor-version"`, created.ID), v1Resp.Header().Get("Link"))
+}
+
+// TestCreateOrdersBatch: Un lote todo o nada no crea ninguna orden si una falla; uno independiente crea las válidas
+func TestCreateOrdersBatch(t *testing.T) {
+	SetupTestServer(t, setupOrderRoutes)
+	defer TearDown()
+
+	product := models.Product{
+		Name:  "Producto de prueba",
+		Price: 100.0,
+		Stock: 5,
+	}
+	err := db.Create(&product).Error
+	assert.NoError(t, err)
+
+	client := resty.New()
+	batchRequest := dtos.BatchOrderRequestDTO{
+		Mode: dtos.BatchModeAllOrNothing,
+		Orders: []dtos.BatchOrderEntryDTO{
+			{OrderRequestDTO: dtos.OrderRequestDTO{CustomerName: "Customer 1", Items: []dtos.OrderItemRequestDTO{{ProductID: product.ID, Quantity: 3}}}},
+			{OrderRequestDTO: dtos.OrderRequestDTO{CustomerName: "Customer 2", Items: []dtos.OrderItemRequestDTO{{ProductID: product.ID, Quantity: 3}}}},
+		},
+	}
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(batchRequest).
+		Post(server.URL + "/api/orders/batch")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode())
+
+	var batchResponse dtos.BatchOrderResponseDTO
+	err = json.Unmarshal(resp.Body(), &batchResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, batchResponse.Succeeded)
+	assert.Equal(t, "BATCH_ABORTED", batchResponse.Results[0].Error.Code)
+	assert.Equal(t, "INSUFFICIENT_STOCK", batchResponse.Results[1].Error.Code)
+
+	// El stock no cambia porque no se creó ninguna orden
+	var stored models.Product
+	assert.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 5, stored.Stock)
+
+	// El mismo lote en modo independiente crea la primera orden y rechaza la segunda
+	batchRequest.Mode = dtos.BatchModeIndependent
+	batchRequest.Orders[0].IdempotencyKey = "batch-order-1"
+
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(batchRequest).
+		Post(server.URL + "/api/orders/batch")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode())
+
+	batchResponse = dtos.BatchOrderResponseDTO{}
+	err = json.Unmarshal(resp.Body(), &batchResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, batchResponse.Succeeded)
+	assert.Equal(t, dtos.BatchStatusCreated, batchResponse.Results[0].Status)
+	assert.NotZero(t, batchResponse.Results[0].OrderID)
+	assert.Equal(t, dtos.BatchStatusFailed, batchResponse.Results[1].Status)
+
+	// La clave de idempotencia de la primera orden evita crearla de nuevo
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(dtos.BatchOrderRequestDTO{Mode: dtos.BatchModeIndependent, Orders: batchRequest.Orders[:1]}).
+		Post(server.URL + "/api/orders/batch")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+
+	var replayResponse dtos.BatchOrderResponseDTO
+	err = json.Unmarshal(resp.Body(), &replayResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, dtos.BatchStatusReplayed, replayResponse.Results[0].Status)
+	assert.Equal(t, batchResponse.Results[0].OrderID, replayResponse.Results[0].OrderID)
+}
+
+// setupSQLIdempotencyOrderRoutes registra las órdenes con las claves de idempotencia guardadas en MySQL
+func setupSQLIdempotencyOrderRoutes(e *echo.Echo, db *gorm.DB, redisClient *redis.Client) {
+	orderRepo := repositories.NewOrderRepository(db)
+	productRepo := repositories.NewProductRepository(db)
+	orderService := services.NewOrderService(orderRepo, productRepo, db, nil, nil)
+
+	apiGroup := e.Group("/api")
+	handlers.NewOrderHandler(apiGroup, orderService, idempotency.NewSQLStore(db, idempotency.DefaultTTL))
+}
+
+// TestCreateOrderIdempotentSQLStore: Con el store SQL la clave se asocia con la orden y un reintento
+// repite la respuesta sin crear otra orden, aunque Redis no se use
+func TestCreateOrderIdempotentSQLStore(t *testing.T) {
+	SetupTestServer(t, setupSQLIdempotencyOrderRoutes)
+	defer TearDown()
+
+	product := models.Product{Name: "Producto de prueba", Price: 100.0, Stock: 10}
+	assert.NoError(t, db.Create(&product).Error)
+
+	orderRequest := dtos.OrderRequestDTO{
+		CustomerName: "Customer 1",
+		Items:        []dtos.OrderItemRequestDTO{{ProductID: product.ID, Quantity: 2}},
+	}
+
+	client := resty.New()
+	var responses []*resty.Response
+	for i := 0; i < 2; i++ {
+		resp, err := client.R().
+			SetHeader("Content-Type", "application/json").
+			SetHeader("Idempotency-Key", "sql-order-1").
+			SetBody(orderRequest).
+			Post(server.URL + "/api/orders")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode())
+		responses = append(responses, resp)
+	}
+
+	assert.Equal(t, string(responses[0].Body()), string(responses[1].Body()))
+	assert.Equal(t, "true", responses[1].Header().Get("Idempotent-Replayed"))
+
+	var orderResponse dtos.OrderResponseDTO
+	assert.NoError(t, json.Unmarshal(responses[0].Body(), &orderResponse))
+
+	var key models.IdempotencyKey
+	assert.NoError(t, db.First(&key, "idempotency_key LIKE ?", "%:sql-order-1").Error)
+	assert.Equal(t, orderResponse.ID, *key.OrderID)
+
+	var orders int64
+	db.Model(&models.Order{}).Count(&orders)
+	assert.Equal(t, int64(1), orders)
+}
+
+// TestCreateOrdersBatchIdempotentSQLStore: Con el store SQL cada clave del lote se asocia con su orden
+// en la transacción que la crea, en ambos modos
+func TestCreateOrdersBatchIdempotentSQLStore(t *testing.T) {
+	SetupTestServer(t, setupSQLIdempotencyOrderRoutes)
+	defer TearDown()
+
+	product := models.Product{Name: "Producto de prueba", Price: 100.0, Stock: 10}
+	assert.NoError(t, db.Create(&product).Error)
+
+	client := resty.New()
+	for _, mode := range []string{dtos.BatchModeIndependent, dtos.BatchModeAllOrNothing} {
+		idempotencyKey := "sql-batch-" + mode
+		resp, err := client.R().
+			SetHeader("Content-Type", "application/json").
+			SetBody(dtos.BatchOrderRequestDTO{
+				Mode: mode,
+				Orders: []dtos.BatchOrderEntryDTO{{
+					IdempotencyKey:  idempotencyKey,
+					OrderRequestDTO: dtos.OrderRequestDTO{CustomerName: "Customer 1", Items: []dtos.OrderItemRequestDTO{{ProductID: product.ID, Quantity: 1}}},
+				}},
+			}).
+			Post(server.URL + "/api/orders/batch")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode(), mode)
+
+		var batchResponse dtos.BatchOrderResponseDTO
+		assert.NoError(t, json.Unmarshal(resp.Body(), &batchResponse))
+
+		var key models.IdempotencyKey
+		assert.NoError(t, db.First(&key, "idempotency_key LIKE ?", "%:"+idempotencyKey).Error)
+		if assert.NotNil(t, key.OrderID, mode) {
+			assert.Equal(t, batchResponse.Results[0].OrderID, *key.OrderID, mode)
+		}
+	}
 }
@@ -0,0 +1,166 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"order_management/internal/dtos"
+	"order_management/internal/handlers"
+	"order_management/internal/idempotency"
+	"order_management/internal/models"
+	"order_management/internal/ports"
+	"order_management/internal/repositories"
+	"order_management/internal/services"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-resty/resty/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupProductRoutes configura las rutas de productos
+func setupProductRoutes(e *echo.Echo, db *gorm.DB, redisClient *redis.Client) {
+	productRepo := repositories.NewProductRepository(db)
+	productService := services.NewProductService(productRepo, db, nil)
+
+	handlers.NewProductHandler(e.Group("/api"), productService, idempotency.NewRedisStore(redisClient, idempotency.DefaultTTL))
+}
+
+func importProducts(t *testing.T, csv string) (int, dtos.ProductImportReportDTO) {
+	resp, err := resty.New().R().
+		SetHeader("Content-Type", "text/csv").
+		SetBody(csv).
+		Post(server.URL + "/api/products/import")
+	assert.NoError(t, err)
+
+	var report dtos.ProductImportReportDTO
+	assert.NoError(t, json.Unmarshal(resp.Body(), &report))
+	return resp.StatusCode(), report
+}
+
+func exportProducts(t *testing.T) string {
+	resp, err := resty.New().R().Get(server.URL + "/api/products/export")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Contains(t, resp.Header().Get("Content-Type"), "text/csv")
+	return resp.String()
+}
+
+// TestImportExportRoundTrip: el CSV exportado se vuelve a importar sin cambios, de modo que sirve para
+// editar el catálogo en una planilla y aplicarlo de nuevo
+func TestImportExportRoundTrip(t *testing.T) {
+	SetupTestServer(t, setupProductRoutes)
+	defer TearDown()
+
+	category := models.Category{Name: "Ropa"}
+	assert.NoError(t, db.Create(&category).Error)
+	product := models.Product{Name: "Camisa", Price: 20, Stock: 5}
+	assert.NoError(t, db.Create(&product).Error)
+
+	// Crear un producto nuevo y agregar variantes al existente
+	productID := strconv.FormatUint(uint64(product.ID), 10)
+	status, report := importProducts(t, "product_id,name,price,stock,category_id,sku,attributes\n"+
+		",\"Pantalón, largo\",35.5,8,"+strconv.FormatUint(uint64(category.ID), 10)+",,\n"+
+		productID+",,22,3,,CAM-M,talla=M;color=azul\n"+
+		productID+",,,2,,CAM-L,talla=L\n")
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, report.Applied)
+	assert.Equal(t, 3, report.Created)
+	assert.Zero(t, report.Failed)
+
+	exported := exportProducts(t)
+
+	// Reimportar lo exportado actualiza cada fila sin crear ni cambiar nada
+	status, report = importProducts(t, exported)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, report.Applied)
+	assert.Zero(t, report.Created)
+	assert.Equal(t, 4, report.Updated)
+	assert.Zero(t, report.Failed)
+	assert.Equal(t, exported, exportProducts(t))
+
+	var imported models.Product
+	assert.NoError(t, db.Preload("Variants").Where("name = ?", "Pantalón, largo").First(&imported).Error)
+	assert.Equal(t, 35.5, imported.Price)
+	assert.Equal(t, 8, imported.Stock)
+	assert.Equal(t, category.ID, *imported.CategoryID)
+
+	var variants []models.ProductVariant
+	assert.NoError(t, db.Where("product_id = ?", product.ID).Order("sku").Find(&variants).Error)
+	if assert.Len(t, variants, 2) {
+		assert.Equal(t, "CAM-L", variants[0].SKU)
+		assert.Nil(t, variants[0].Price)
+		assert.Equal(t, map[string]string{"talla": "L"}, variants[0].Attributes)
+		assert.Equal(t, "CAM-M", variants[1].SKU)
+		assert.Equal(t, 22.0, *variants[1].Price)
+		assert.Equal(t, 3, variants[1].Stock)
+	}
+}
+
+// TestImportRejectsInvalidRows: una fila inválida impide aplicar todo el archivo
+func TestImportRejectsInvalidRows(t *testing.T) {
+	SetupTestServer(t, setupProductRoutes)
+	defer TearDown()
+
+	status, report := importProducts(t, "name,price,stock\nMouse,10,5\nTeclado,diez,5\n")
+
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.False(t, report.Applied)
+	assert.Equal(t, 1, report.Failed)
+	var count int64
+	assert.NoError(t, db.Model(&models.Product{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// TestProductSearch_UsesIndexes verifica con EXPLAIN que cada rama de la búsqueda por nombre y SKU use
+// su índice, idx_products_name o idx_product_variants_sku, en lugar de recorrer la tabla
+func TestProductSearch_UsesIndexes(t *testing.T) {
+	db := SetupTestDatabase(t)
+	defer TearDown()
+
+	for i := 0; i < 20; i++ {
+		product := models.Product{Name: "Producto " + strconv.Itoa(i), Price: 10, Stock: 1}
+		assert.NoError(t, db.Create(&product).Error)
+		assert.NoError(t, db.Create(&models.ProductVariant{ProductID: product.ID, SKU: "SKU-" + strconv.Itoa(i)}).Error)
+	}
+
+	// Capturar la consulta de la página que ejecuta el repositorio
+	var queries []string
+	assert.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture_sql", func(tx *gorm.DB) {
+		queries = append(queries, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}))
+	_, total, err := repositories.NewProductRepository(db).Search(context.Background(), ports.ProductFilter{Query: "ducto 1", Page: 1, PageSize: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), total)
+	assert.NoError(t, db.Callback().Query().Remove("test:capture_sql"))
+
+	var pageQuery string
+	for _, query := range queries {
+		if strings.Contains(query, "search_matches") && !strings.Contains(query, "count(") {
+			pageQuery = query
+		}
+	}
+	if !assert.NotEmpty(t, pageQuery) {
+		return
+	}
+
+	var plan []struct {
+		Type string
+		Key  *string
+	}
+	assert.NoError(t, db.Raw("EXPLAIN "+pageQuery).Scan(&plan).Error)
+
+	keys := make(map[string]string)
+	for _, row := range plan {
+		if row.Key != nil {
+			keys[*row.Key] = row.Type
+		}
+	}
+	// El nombre se busca por dentro recorriendo solo el índice, que ya contiene el ID, y el SKU por rango
+	assert.Equal(t, "index", keys["idx_products_name"])
+	assert.Equal(t, "range", keys["idx_product_variants_sku"])
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http/httptest"
-	"order_management/internal/models"
+	"order_management/internal/handlers"
+	"order_management/internal/middlewares"
 	"order_management/internal/validators"
+	"order_management/pkg/database"
 	"testing"
 	"time"
 
@@ -67,9 +69,12 @@ func SetupTestDatabase(t *testing.T) *gorm.DB {
 		t.Fatalf("Error conectando a MySQL: %v", err)
 	}
 
-	// Migrar modelos
-	err = db.AutoMigrate(&models.Product{}, &models.Order{}, &models.OrderItem{})
+	// Crear el esquema con las mismas migraciones que producción
+	migrator, err := database.NewMigrator(db)
 	if err != nil {
+		t.Fatalf("Error cargando las migraciones: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
 		t.Fatalf("Error ejecutando migraciones: %v", err)
 	}
 
@@ -96,6 +101,10 @@ func SetupTestServer(t *testing.T, registerRoutes func(e *echo.Echo, db *gorm.DB
 	// Configurar el validador
 	e.Validator = validators.NewValidator()
 
+	// Configurar el manejador central de errores y la negociación del idioma
+	e.HTTPErrorHandler = handlers.HTTPErrorHandler
+	e.Use(middlewares.LanguageMiddleware())
+
 	registerRoutes(e, db, redisClient)
 
 	// Iniciar servidor de pruebas
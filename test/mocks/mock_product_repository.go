@@ -5,7 +5,9 @@
 package mocks
 
 import (
+	context "context"
 	models "order_management/internal/models"
+	ports "order_management/internal/ports"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,60 +37,162 @@ func (m *MockProductRepository) EXPECT() *MockProductRepositoryMockRecorder {
 	return m.recorder
 }
 
-// GetAll mocks base method.
-func (m *MockProductRepository) GetAll() ([]models.Product, error) {
+// FindByIDs mocks base method.
+func (m *MockProductRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll")
+	ret := m.ctrl.Call(m, "FindByIDs", ctx, ids)
 	ret0, _ := ret[0].([]models.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAll indicates an expected call of GetAll.
-func (mr *MockProductRepositoryMockRecorder) GetAll() *gomock.Call {
+// FindByIDs indicates an expected call of FindByIDs.
+func (mr *MockProductRepositoryMockRecorder) FindByIDs(ctx, ids interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProductRepository)(nil).GetAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockProductRepository)(nil).FindByIDs), ctx, ids)
+}
+
+// FindInBatches mocks base method.
+func (m *MockProductRepository) FindInBatches(ctx context.Context, batchSize int, fn func([]models.Product) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInBatches", ctx, batchSize, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FindInBatches indicates an expected call of FindInBatches.
+func (mr *MockProductRepositoryMockRecorder) FindInBatches(ctx, batchSize, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInBatches", reflect.TypeOf((*MockProductRepository)(nil).FindInBatches), ctx, batchSize, fn)
 }
 
 // GetByID mocks base method.
-func (m *MockProductRepository) GetByID(id uint, tx *gorm.DB) (*models.Product, error) {
+func (m *MockProductRepository) GetByID(ctx context.Context, id uint, tx *gorm.DB) (*models.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", id, tx)
+	ret := m.ctrl.Call(m, "GetByID", ctx, id, tx)
 	ret0, _ := ret[0].(*models.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockProductRepositoryMockRecorder) GetByID(id, tx interface{}) *gomock.Call {
+func (mr *MockProductRepositoryMockRecorder) GetByID(ctx, id, tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductRepository)(nil).GetByID), ctx, id, tx)
+}
+
+// GetVariantByID mocks base method.
+func (m *MockProductRepository) GetVariantByID(ctx context.Context, id uint, tx *gorm.DB) (*models.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariantByID", ctx, id, tx)
+	ret0, _ := ret[0].(*models.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariantByID indicates an expected call of GetVariantByID.
+func (mr *MockProductRepositoryMockRecorder) GetVariantByID(ctx, id, tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariantByID", reflect.TypeOf((*MockProductRepository)(nil).GetVariantByID), ctx, id, tx)
+}
+
+// GetVariantBySKU mocks base method.
+func (m *MockProductRepository) GetVariantBySKU(ctx context.Context, sku string, tx *gorm.DB) (*models.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariantBySKU", ctx, sku, tx)
+	ret0, _ := ret[0].(*models.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariantBySKU indicates an expected call of GetVariantBySKU.
+func (mr *MockProductRepositoryMockRecorder) GetVariantBySKU(ctx, sku, tx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductRepository)(nil).GetByID), id, tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariantBySKU", reflect.TypeOf((*MockProductRepository)(nil).GetVariantBySKU), ctx, sku, tx)
+}
+
+// Save mocks base method.
+func (m *MockProductRepository) Save(ctx context.Context, product *models.Product, tx *gorm.DB) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, product, tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockProductRepositoryMockRecorder) Save(ctx, product, tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockProductRepository)(nil).Save), ctx, product, tx)
+}
+
+// SaveVariant mocks base method.
+func (m *MockProductRepository) SaveVariant(ctx context.Context, variant *models.ProductVariant, tx *gorm.DB) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVariant", ctx, variant, tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVariant indicates an expected call of SaveVariant.
+func (mr *MockProductRepositoryMockRecorder) SaveVariant(ctx, variant, tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVariant", reflect.TypeOf((*MockProductRepository)(nil).SaveVariant), ctx, variant, tx)
+}
+
+// Search mocks base method.
+func (m *MockProductRepository) Search(ctx context.Context, filter ports.ProductFilter) ([]models.Product, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, filter)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockProductRepositoryMockRecorder) Search(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockProductRepository)(nil).Search), ctx, filter)
 }
 
 // Update mocks base method.
-func (m *MockProductRepository) Update(product *models.Product) error {
+func (m *MockProductRepository) Update(ctx context.Context, product *models.Product) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", product)
+	ret := m.ctrl.Call(m, "Update", ctx, product)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockProductRepositoryMockRecorder) Update(product interface{}) *gomock.Call {
+func (mr *MockProductRepositoryMockRecorder) Update(ctx, product interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProductRepository)(nil).Update), product)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProductRepository)(nil).Update), ctx, product)
 }
 
 // UpdateStock mocks base method.
-func (m *MockProductRepository) UpdateStock(id uint, newStock int, tx *gorm.DB) error {
+func (m *MockProductRepository) UpdateStock(ctx context.Context, id uint, newStock int, tx *gorm.DB) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStock", id, newStock, tx)
+	ret := m.ctrl.Call(m, "UpdateStock", ctx, id, newStock, tx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateStock indicates an expected call of UpdateStock.
-func (mr *MockProductRepositoryMockRecorder) UpdateStock(id, newStock, tx interface{}) *gomock.Call {
+func (mr *MockProductRepositoryMockRecorder) UpdateStock(ctx, id, newStock, tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStock", reflect.TypeOf((*MockProductRepository)(nil).UpdateStock), ctx, id, newStock, tx)
+}
+
+// UpdateVariantStock mocks base method.
+func (m *MockProductRepository) UpdateVariantStock(ctx context.Context, id uint, newStock int, tx *gorm.DB) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariantStock", ctx, id, newStock, tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVariantStock indicates an expected call of UpdateVariantStock.
+func (mr *MockProductRepositoryMockRecorder) UpdateVariantStock(ctx, id, newStock, tx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStock", reflect.TypeOf((*MockProductRepository)(nil).UpdateStock), id, newStock, tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariantStock", reflect.TypeOf((*MockProductRepository)(nil).UpdateVariantStock), ctx, id, newStock, tx)
 }